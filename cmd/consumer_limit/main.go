@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"xyz-transaction-service/common/authorization"
+	"xyz-transaction-service/common/config"
+	gormConn "xyz-transaction-service/common/gorm"
+	commonJwt "xyz-transaction-service/common/jwt"
+	"xyz-transaction-service/common/migration"
+	"xyz-transaction-service/common/tracing"
+	"xyz-transaction-service/pb"
+	"xyz-transaction-service/server"
+
+	consumerLimitModule "xyz-transaction-service/modules/consumer_limit"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// main runs ConsumerLimitService on its own, for local development and
+// integration tests of a transaction service pointed at it through
+// CLIENT_URL_CONSUMER.
+func main() {
+	cfg, cerr := config.NewConfig(".env")
+	checkError(cerr)
+
+	splash(cfg)
+
+	shutdownTracing, terr := tracing.NewTracerProvider(context.Background(), cfg.ServiceName, cfg.Tracing)
+	checkError(terr)
+
+	db, gerr := gormConn.NewGormDB(*cfg)
+	checkError(gerr)
+	checkError(gormConn.InstrumentGormDB(db, gormConn.DatabaseName(*cfg)))
+
+	checkError(migration.Prepare(context.Background(), db, cfg.Database.Driver, cfg.Migration))
+
+	jwtManager := commonJwt.NewJWT(cfg.JWT.JwtSecretKey, cfg.JWT.TokenDuration)
+
+	policy, perr := authorization.LoadPolicy(cfg.Auth.PolicyFile)
+	checkError(perr)
+
+	var serverOptions []grpc.ServerOption
+	if cfg.TLS.CertFile != "" {
+		certs, err := server.NewCertReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		checkError(err)
+		tlsConfig, err := server.NewServerTLSConfig(certs, cfg.TLS.ClientCAFile)
+		checkError(err)
+
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	grpcServer := server.NewGrpcServer(cfg.Port.GRPC, jwtManager, policy, serverOptions...)
+	grpcServer.DrainDelay = cfg.GRPC.DrainDelay
+	if cfg.GRPC.Reflection {
+		grpcServer.EnableReflection()
+		policy.AddAnonymous(server.ReflectionMethods...)
+	}
+
+	consumerLimitModule.InitGrpc(grpcServer, *cfg, db)
+	grpcServer.Health.AddCheck(cfg.Database.Driver, func(ctx context.Context) error {
+		return gormConn.Ping(ctx, db)
+	})
+	go grpcServer.Health.Run(context.Background(), cfg.Health.CheckInterval, cfg.Health.CheckTimeout)
+
+	// the policy may only name methods that are actually served; the policy
+	// of the transaction service is shared, so its methods are tolerated
+	policy.AddOptionalServices(pb.TransactionService_ServiceDesc.ServiceName)
+	checkError(policy.Validate(grpcServer.Server.GetServiceInfo()))
+
+	var metricsServer *server.Metrics
+	if cfg.Port.Metrics != "" {
+		metricsServer = server.NewMetrics(cfg.Port.Metrics)
+		checkError(metricsServer.Run())
+	}
+
+	_ = grpcServer.Run()
+	_ = grpcServer.AwaitTermination()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(ctx); err != nil {
+			log.Println("ERROR: [main] Error while shutdown metrics server:", err)
+		}
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		log.Println("ERROR: [main] Error while flush traces:", err)
+	}
+}
+
+func checkError(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+func splash(cfg *config.Config) {
+	colorReset := "\033[0m"
+	colorCyan := "\033[36m"
+
+	fmt.Println(colorCyan, fmt.Sprintf(`-> GRPC %s consumer limit server started on port :%s`, cfg.ServiceName, cfg.Port.GRPC))
+	fmt.Println(colorReset, "")
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"xyz-transaction-service/common/config"
+	gormConn "xyz-transaction-service/common/gorm"
+	"xyz-transaction-service/common/migration"
+	"xyz-transaction-service/migrations"
+)
+
+const usage = `usage: migrate <command> [flags]
+
+commands:
+  up [-to version]        apply the pending migrations, up to version when given
+  down [-steps n]         roll back the latest n applied migrations, 1 by default
+  status                  list every migration and whether it is applied
+  baseline -version v     mark migrations up to v as applied without running them,
+                          for a database whose schema was created by hand
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.NewConfig(".env")
+	checkError(err)
+
+	db, err := gormConn.NewGormDB(*cfg)
+	checkError(err)
+
+	migrationFiles, err := migrations.For(cfg.Database.Driver)
+	checkError(err)
+	migrationList, err := migration.Load(migrationFiles)
+	checkError(err)
+
+	migrator := migration.NewMigrator(db, migrationList)
+	ctx := context.Background()
+
+	command, args := os.Args[1], os.Args[2:]
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	switch command {
+	case "up":
+		to := flags.Uint64("to", 0, "last version to apply, 0 for all")
+		checkError(flags.Parse(args))
+
+		applied, err := migrator.Up(ctx, *to)
+		report("applied", applied)
+		checkError(err)
+	case "down":
+		steps := flags.Int("steps", 1, "number of migrations to roll back")
+		checkError(flags.Parse(args))
+
+		rolledBack, err := migrator.Down(ctx, *steps)
+		report("rolled back", rolledBack)
+		checkError(err)
+	case "status":
+		checkError(flags.Parse(args))
+
+		statuses, err := migrator.Status(ctx)
+		checkError(err)
+		printStatus(statuses)
+	case "baseline":
+		version := flags.Uint64("version", 0, "last version already present in the schema")
+		checkError(flags.Parse(args))
+		if *version == 0 {
+			fmt.Fprintln(os.Stderr, "baseline requires -version")
+			os.Exit(2)
+		}
+
+		baselined, err := migrator.Baseline(ctx, *version)
+		report("baselined", baselined)
+		checkError(err)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func report(action string, done []*migration.Migration) {
+	if len(done) == 0 {
+		fmt.Printf("nothing %s\n", action)
+		return
+	}
+	for _, m := range done {
+		fmt.Printf("%s %04d_%s\n", action, m.Version, m.Name)
+	}
+}
+
+func printStatus(statuses []*migration.Status) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		state := "pending"
+		switch {
+		case s.Missing:
+			state = "applied, missing from this build"
+		case s.Modified:
+			state = "applied, modified since"
+		case s.Baseline:
+			state = "baseline"
+		case s.Applied:
+			state = "applied"
+		}
+
+		appliedAt := ""
+		if s.Applied {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+	}
+	_ = w.Flush()
+}
+
+func checkError(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR: [migrate]", err)
+		os.Exit(1)
+	}
+}
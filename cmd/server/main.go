@@ -1,16 +1,24 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"time"
+	"xyz-transaction-service/common/authorization"
 	"xyz-transaction-service/common/config"
 	gormConn "xyz-transaction-service/common/gorm"
 	commonJwt "xyz-transaction-service/common/jwt"
-	"xyz-transaction-service/common/mysql"
+	"xyz-transaction-service/common/migration"
+	"xyz-transaction-service/common/tracing"
+	"xyz-transaction-service/pb"
 	"xyz-transaction-service/server"
 
+	consumerLimitModule "xyz-transaction-service/modules/consumer_limit"
 	transactionModule "xyz-transaction-service/modules/transaction"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"gorm.io/gorm"
 )
 
@@ -20,21 +28,81 @@ func main() {
 
 	splash(cfg)
 
-	dsn, derr := mysql.NewPool(&cfg.MySQL)
-	checkError(derr)
+	shutdownTracing, terr := tracing.NewTracerProvider(context.Background(), cfg.ServiceName, cfg.Tracing)
+	checkError(terr)
 
-	db, gerr := gormConn.NewMySQLGormDB(dsn)
+	db, gerr := gormConn.NewGormDB(*cfg)
 	checkError(gerr)
+	checkError(gormConn.InstrumentGormDB(db, gormConn.DatabaseName(*cfg)))
+
+	checkError(migration.Prepare(context.Background(), db, cfg.Database.Driver, cfg.Migration))
 
 	jwtManager := commonJwt.NewJWT(cfg.JWT.JwtSecretKey, cfg.JWT.TokenDuration)
 
-	grpcServer := server.NewGrpcServer(cfg.Port.GRPC, jwtManager)
-	grpcConn := server.InitGRPCConn(fmt.Sprintf("127.0.0.1:%v", cfg.Port.GRPC), false, "")
+	policy, perr := authorization.LoadPolicy(cfg.Auth.PolicyFile)
+	checkError(perr)
+
+	var serverOptions []grpc.ServerOption
+	var loopbackOptions []server.DialOption
+	if cfg.TLS.CertFile != "" {
+		certs, err := server.NewCertReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		checkError(err)
+		tlsConfig, err := server.NewServerTLSConfig(certs, cfg.TLS.ClientCAFile)
+		checkError(err)
 
-	registerGrpcHandlers(grpcServer.Server, *cfg, db, grpcConn)
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		loopbackOptions = append(loopbackOptions, server.WithLoopbackTLS(certs))
+	}
+
+	grpcServer := server.NewGrpcServer(cfg.Port.GRPC, jwtManager, policy, serverOptions...)
+	grpcServer.DrainDelay = cfg.GRPC.DrainDelay
+	if cfg.GRPC.Reflection {
+		grpcServer.EnableReflection()
+		policy.AddAnonymous(server.ReflectionMethods...)
+	}
+	grpcConn := server.InitGRPCConn(fmt.Sprintf("127.0.0.1:%v", cfg.Port.GRPC), false, "", loopbackOptions...)
+
+	registerGrpcHandlers(grpcServer, *cfg, db, policy)
+	grpcServer.Health.AddCheck(cfg.Database.Driver, func(ctx context.Context) error {
+		return gormConn.Ping(ctx, db)
+	})
+	go grpcServer.Health.Run(context.Background(), cfg.Health.CheckInterval, cfg.Health.CheckTimeout)
+
+	// the policy may only name methods that are actually served
+	checkError(policy.Validate(grpcServer.Server.GetServiceInfo()))
+
+	var metricsServer *server.Metrics
+	if cfg.Port.Metrics != "" {
+		metricsServer = server.NewMetrics(cfg.Port.Metrics)
+		checkError(metricsServer.Run())
+	}
 
 	_ = grpcServer.Run()
+
+	var gateway *server.Gateway
+	if cfg.Port.HTTP != "" {
+		gateway = server.NewGateway(cfg.Port.HTTP, grpcConn, pb.TransactionOpenAPI)
+		checkError(transactionModule.InitGateway(context.Background(), gateway))
+		checkError(gateway.Run())
+	}
+
 	_ = grpcServer.AwaitTermination()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if gateway != nil {
+		if err := gateway.Shutdown(ctx); err != nil {
+			log.Println("ERROR: [main] Error while shutdown http gateway:", err)
+		}
+	}
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(ctx); err != nil {
+			log.Println("ERROR: [main] Error while shutdown metrics server:", err)
+		}
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		log.Println("ERROR: [main] Error while flush traces:", err)
+	}
 }
 
 func checkError(err error) {
@@ -43,8 +111,12 @@ func checkError(err error) {
 	}
 }
 
-func registerGrpcHandlers(server *grpc.Server, cfg config.Config, db *gorm.DB, grpcConn *grpc.ClientConn) {
-	transactionModule.InitGrpc(server, cfg, db, grpcConn)
+func registerGrpcHandlers(grpcServer *server.Grpc, cfg config.Config, db *gorm.DB, policy *authorization.Policy) {
+	var consumerLimitClient pb.ConsumerLimitServiceClient
+	if cfg.ConsumerLimit.Embedded {
+		consumerLimitClient = consumerLimitModule.InitGrpc(grpcServer, cfg, db)
+	}
+	transactionModule.InitGrpc(grpcServer, cfg, db, consumerLimitClient, policy)
 }
 
 func splash(cfg *config.Config) {
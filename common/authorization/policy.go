@@ -0,0 +1,215 @@
+package authorization
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+	"gopkg.in/yaml.v3"
+)
+
+// PermissionAnyConsumer lets a role read and create contracts of every
+// consumer instead of only the consumer bound to its token.
+const PermissionAnyConsumer = "transactions:any_consumer"
+
+// Role is a JWT role id together with the permissions it grants.
+type Role struct {
+	Id          uint32   `json:"id" yaml:"id"`
+	Permissions []string `json:"permissions" yaml:"permissions"`
+}
+
+// MethodRule lets a caller in when its role is listed or when its role grants
+// one of the listed permissions.
+type MethodRule struct {
+	Roles       []string `json:"roles" yaml:"roles"`
+	Permissions []string `json:"permissions" yaml:"permissions"`
+}
+
+// Policy maps full gRPC method names, e.g. /xyz_grpc.TransactionService/CreateTransaction,
+// to the callers allowed to invoke them. Methods that are neither listed nor
+// anonymous are denied. Methods of OptionalServices, e.g. xyz_grpc.ConsumerLimitService,
+// may be listed whether or not the service is registered.
+type Policy struct {
+	Roles            map[string]Role       `json:"roles" yaml:"roles"`
+	AllowAnonymous   []string              `json:"allow_anonymous" yaml:"allow_anonymous"`
+	Methods          map[string]MethodRule `json:"methods" yaml:"methods"`
+	OptionalServices []string              `json:"optional_services" yaml:"optional_services"`
+
+	anonymous   map[string]bool
+	optional    map[string]bool
+	roleIds     map[string]uint32
+	permissions map[uint32]map[string]bool
+}
+
+// LoadPolicy reads a policy from a .json, .yaml or .yml file.
+func LoadPolicy(path string) (*Policy, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read authorization policy: %w", err)
+	}
+
+	return ParsePolicy(b, filepath.Ext(path))
+}
+
+// ParsePolicy decodes a policy document, ext selecting JSON or YAML.
+func ParsePolicy(b []byte, ext string) (*Policy, error) {
+	var policy Policy
+	switch strings.ToLower(ext) {
+	case ".json":
+		if err := json.Unmarshal(b, &policy); err != nil {
+			return nil, fmt.Errorf("parse authorization policy: %w", err)
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(b, &policy); err != nil {
+			return nil, fmt.Errorf("parse authorization policy: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported authorization policy format %q", ext)
+	}
+
+	if err := policy.compile(); err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+func (p *Policy) compile() error {
+	p.anonymous = make(map[string]bool)
+	for _, method := range p.AllowAnonymous {
+		p.anonymous[method] = true
+	}
+
+	p.optional = make(map[string]bool)
+	for _, service := range p.OptionalServices {
+		p.optional[service] = true
+	}
+
+	p.roleIds = make(map[string]uint32)
+	p.permissions = make(map[uint32]map[string]bool)
+	for name, role := range p.Roles {
+		if _, ok := p.permissions[role.Id]; ok {
+			return fmt.Errorf("authorization policy: role id %d is used more than once", role.Id)
+		}
+		p.roleIds[name] = role.Id
+		p.permissions[role.Id] = make(map[string]bool)
+		for _, permission := range role.Permissions {
+			p.permissions[role.Id][permission] = true
+		}
+	}
+
+	for method, rule := range p.Methods {
+		for _, name := range rule.Roles {
+			if _, ok := p.roleIds[name]; !ok {
+				return fmt.Errorf("authorization policy: method %s references unknown role %q", method, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Validate checks every method named by the policy against the services
+// registered on the server, so a typo fails the startup instead of silently
+// leaving a rule unused.
+func (p *Policy) Validate(services map[string]grpc.ServiceInfo) error {
+	registered := make(map[string]bool)
+	for service, info := range services {
+		for _, method := range info.Methods {
+			registered["/"+service+"/"+method.Name] = true
+		}
+	}
+
+	var unknown []string
+	for method := range p.Methods {
+		if !registered[method] && !p.optional[serviceOf(method)] {
+			unknown = append(unknown, method)
+		}
+	}
+	for method := range p.anonymous {
+		if !registered[method] && !p.optional[serviceOf(method)] {
+			unknown = append(unknown, method)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("authorization policy references unregistered methods: %s", strings.Join(unknown, ", "))
+	}
+
+	for method := range registered {
+		if _, ok := p.Methods[method]; !ok && !p.anonymous[method] {
+			log.Println("WARNING: [Authorization - Validate] No policy for method, every call will be denied:", method)
+		}
+	}
+
+	return nil
+}
+
+// serviceOf returns the service of a full method name, e.g.
+// xyz_grpc.TransactionService for /xyz_grpc.TransactionService/CreateTransaction.
+func serviceOf(method string) string {
+	method = strings.TrimPrefix(method, "/")
+	if i := strings.LastIndex(method, "/"); i >= 0 {
+		return method[:i]
+	}
+	return method
+}
+
+// AddAnonymous lets the methods be called without a token, for services
+// registered only under some configurations, such as server reflection.
+func (p *Policy) AddAnonymous(methods ...string) {
+	for _, method := range methods {
+		if !p.anonymous[method] {
+			p.AllowAnonymous = append(p.AllowAnonymous, method)
+			p.anonymous[method] = true
+		}
+	}
+}
+
+// AddOptionalServices lets the policy name methods of services a server does
+// not register, such as xyz_grpc.TransactionService on the standalone consumer
+// limit server.
+func (p *Policy) AddOptionalServices(services ...string) {
+	for _, service := range services {
+		if !p.optional[service] {
+			p.OptionalServices = append(p.OptionalServices, service)
+			p.optional[service] = true
+		}
+	}
+}
+
+// IsAnonymous reports whether the method can be called without a token.
+func (p *Policy) IsAnonymous(method string) bool {
+	return p.anonymous[method]
+}
+
+// Allows reports whether a caller with the role may invoke the method.
+func (p *Policy) Allows(method string, role uint32) bool {
+	rule, ok := p.Methods[method]
+	if !ok {
+		return false
+	}
+
+	for _, name := range rule.Roles {
+		if p.roleIds[name] == role {
+			return true
+		}
+	}
+	for _, permission := range rule.Permissions {
+		if p.HasPermission(role, permission) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasPermission reports whether the role grants the permission.
+func (p *Policy) HasPermission(role uint32, permission string) bool {
+	return p.permissions[role][permission]
+}
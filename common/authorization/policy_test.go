@@ -0,0 +1,152 @@
+package authorization_test
+
+import (
+	"testing"
+	"xyz-transaction-service/common/authorization"
+	"xyz-transaction-service/pb"
+	"xyz-transaction-service/server"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+const testPolicy = `
+roles:
+  admin:
+    id: 1
+    permissions: [transactions:read, transactions:write]
+  consumer:
+    id: 3
+allow_anonymous:
+  - /xyz_grpc.TransactionService/GetInstallmentSchedule
+methods:
+  /xyz_grpc.TransactionService/GetAllTransactions:
+    permissions: [transactions:read]
+  /xyz_grpc.TransactionService/CreateTransaction:
+    roles: [consumer]
+`
+
+func newTestServer() *grpc.Server {
+	server := grpc.NewServer()
+	pb.RegisterTransactionServiceServer(server, &pb.UnimplementedTransactionServiceServer{})
+	return server
+}
+
+func TestPolicyAllows(t *testing.T) {
+	policy, err := authorization.ParsePolicy([]byte(testPolicy), ".yaml")
+	assert.NoError(t, err)
+
+	assert.True(t, policy.Allows("/xyz_grpc.TransactionService/GetAllTransactions", 1))
+	assert.False(t, policy.Allows("/xyz_grpc.TransactionService/GetAllTransactions", 3))
+	assert.True(t, policy.Allows("/xyz_grpc.TransactionService/CreateTransaction", 3))
+	assert.False(t, policy.Allows("/xyz_grpc.TransactionService/CreateTransaction", 1))
+
+	// methods missing from the policy are denied to everyone
+	assert.False(t, policy.Allows("/xyz_grpc.TransactionService/UpdateTransactionStatus", 1))
+	assert.False(t, policy.IsAnonymous("/xyz_grpc.TransactionService/UpdateTransactionStatus"))
+	assert.True(t, policy.IsAnonymous("/xyz_grpc.TransactionService/GetInstallmentSchedule"))
+
+	assert.True(t, policy.HasPermission(1, "transactions:write"))
+	assert.False(t, policy.HasPermission(3, "transactions:write"))
+}
+
+func TestParsePolicyJSON(t *testing.T) {
+	policy, err := authorization.ParsePolicy([]byte(`{
+		"roles": {"staff": {"id": 2, "permissions": ["transactions:read"]}},
+		"methods": {"/xyz_grpc.TransactionService/GetAllTransactions": {"roles": ["staff"]}}
+	}`), ".json")
+	assert.NoError(t, err)
+	assert.True(t, policy.Allows("/xyz_grpc.TransactionService/GetAllTransactions", 2))
+}
+
+func TestParsePolicyRejectsUnknownRole(t *testing.T) {
+	_, err := authorization.ParsePolicy([]byte(`
+methods:
+  /xyz_grpc.TransactionService/GetAllTransactions:
+    roles: [auditor]
+`), ".yml")
+	assert.Error(t, err)
+}
+
+func TestPolicyValidate(t *testing.T) {
+	policy, err := authorization.ParsePolicy([]byte(testPolicy), ".yaml")
+	assert.NoError(t, err)
+	assert.NoError(t, policy.Validate(newTestServer().GetServiceInfo()))
+
+	// the proto package is xyz_grpc, not the module name
+	typo, err := authorization.ParsePolicy([]byte(`
+methods:
+  /xyz-transaction-service.TransactionService/GetAllTransactions:
+    permissions: [transactions:read]
+`), ".yaml")
+	assert.NoError(t, err)
+	assert.ErrorContains(t, typo.Validate(newTestServer().GetServiceInfo()), "/xyz-transaction-service.TransactionService/GetAllTransactions")
+}
+
+func TestPolicyValidateOptionalServices(t *testing.T) {
+	policy, err := authorization.ParsePolicy([]byte(testPolicy+`
+  /xyz_grpc.ConsumerLimitService/UpdateAvailableLimit:
+    permissions: [transactions:write]
+optional_services:
+  - xyz_grpc.ConsumerLimitService
+`), ".yaml")
+	assert.NoError(t, err)
+	assert.NoError(t, policy.Validate(newTestServer().GetServiceInfo()))
+
+	withConsumerLimit := newTestServer()
+	pb.RegisterConsumerLimitServiceServer(withConsumerLimit, &pb.UnimplementedConsumerLimitServiceServer{})
+	assert.NoError(t, policy.Validate(withConsumerLimit.GetServiceInfo()))
+
+	// only the listed services are optional
+	typo, err := authorization.ParsePolicy([]byte(`
+methods:
+  /xyz_grpc.ConsumerLimitServices/UpdateAvailableLimit:
+    permissions: [transactions:write]
+optional_services:
+  - xyz_grpc.ConsumerLimitService
+`), ".yaml")
+	assert.NoError(t, err)
+	assert.ErrorContains(t, typo.Validate(newTestServer().GetServiceInfo()), "/xyz_grpc.ConsumerLimitServices/UpdateAvailableLimit")
+}
+
+func TestExamplePolicyMatchesRegisteredServices(t *testing.T) {
+	policy, err := authorization.LoadPolicy("../../policy.example.yaml")
+	assert.NoError(t, err)
+
+	// the application server also serves grpc.health.v1 and, when enabled, reflection
+	grpcServer := server.NewGrpc("0")
+	pb.RegisterTransactionServiceServer(grpcServer.Server, &pb.UnimplementedTransactionServiceServer{})
+	assert.NoError(t, policy.Validate(grpcServer.Server.GetServiceInfo()))
+	assert.True(t, policy.IsAnonymous("/grpc.health.v1.Health/Check"))
+
+	grpcServer.EnableReflection()
+	policy.AddAnonymous(server.ReflectionMethods...)
+	assert.NoError(t, policy.Validate(grpcServer.Server.GetServiceInfo()))
+	assert.True(t, policy.IsAnonymous(server.ReflectionMethods[0]))
+
+	// with CONSUMER_LIMIT_EMBEDDED the same policy covers the consumer limit service
+	pb.RegisterConsumerLimitServiceServer(grpcServer.Server, &pb.UnimplementedConsumerLimitServiceServer{})
+	assert.NoError(t, policy.Validate(grpcServer.Server.GetServiceInfo()))
+	for _, method := range []string{
+		pb.ConsumerLimitService_GetConsumerLimitsByConsumerId_FullMethodName,
+		pb.ConsumerLimitService_GetConsumerLimitByConsumerIdAndTenor_FullMethodName,
+		pb.ConsumerLimitService_CreateConsumerLimit_FullMethodName,
+		pb.ConsumerLimitService_UpdateAvailableLimit_FullMethodName,
+		pb.ConsumerLimitService_RestoreAvailableLimit_FullMethodName,
+	} {
+		assert.True(t, policy.Allows(method, 1), method)
+	}
+}
+
+func TestExamplePolicyServesStandaloneConsumerLimit(t *testing.T) {
+	policy, err := authorization.LoadPolicy("../../policy.example.yaml")
+	assert.NoError(t, err)
+
+	// cmd/consumer_limit registers only the consumer limit service
+	grpcServer := server.NewGrpc("0")
+	pb.RegisterConsumerLimitServiceServer(grpcServer.Server, &pb.UnimplementedConsumerLimitServiceServer{})
+	assert.Error(t, policy.Validate(grpcServer.Server.GetServiceInfo()))
+
+	policy.AddOptionalServices(pb.TransactionService_ServiceDesc.ServiceName)
+	assert.NoError(t, policy.Validate(grpcServer.Server.GetServiceInfo()))
+}
@@ -9,15 +9,54 @@ import (
 )
 
 type Config struct {
-	ServiceName       string `env:"SERVICE_NAME,default=xyz-grpc"`
-	Port              Port
-	MySQL             MySQL
-	JWT               JWTConfig
-	ClientURL         ClientURL
+	ServiceName      string `env:"SERVICE_NAME,default=xyz-grpc"`
+	Port             Port
+	GRPC             GRPC
+	Health           Health
+	Database         Database
+	MySQL            MySQL
+	Postgres         Postgres
+	SQLite           SQLite
+	Migration        Migration
+	JWT              JWTConfig
+	Auth             Auth
+	TLS              TLS
+	ClientURL        ClientURL
+	ClientTLS        ClientTLS
+	ClientResilience ClientResilience
+	ConsumerLimit    ConsumerLimit
+	Pricing          Pricing
+	ContractNumber   ContractNumber
+	Payment          Payment
+	Saga             Saga
+	Idempotency      Idempotency
+	Pagination       Pagination
+	Tracing          Tracing
 }
 
 type Port struct {
 	GRPC string `env:"PORT_GRPC,default=8081"`
+	// REST/JSON gateway listener, left empty to disable it
+	HTTP string `env:"PORT_HTTP,default=8080"`
+	// Prometheus /metrics listener, left empty to disable it
+	Metrics string `env:"PORT_METRICS,default=9090"`
+}
+
+type GRPC struct {
+	// serves grpc.reflection for tools such as grpcurl, keep it off in production
+	Reflection bool `env:"GRPC_REFLECTION,default=false"`
+	// time reported as NOT_SERVING before the graceful stop on shutdown
+	DrainDelay time.Duration `env:"GRPC_DRAIN_DELAY,default=5s"`
+}
+
+type Health struct {
+	CheckInterval time.Duration `env:"HEALTH_CHECK_INTERVAL,default=10s"`
+	CheckTimeout  time.Duration `env:"HEALTH_CHECK_TIMEOUT,default=2s"`
+}
+
+type Database struct {
+	// mysql, postgres or sqlite
+	Driver string `env:"DATABASE_DRIVER,default=mysql"`
 }
 
 type MySQL struct {
@@ -28,15 +67,136 @@ type MySQL struct {
 	Name     string `env:"MYSQL_NAME"`
 }
 
+type Postgres struct {
+	Host     string `env:"POSTGRES_HOST,default=localhost"`
+	Port     string `env:"POSTGRES_PORT,default=5432"`
+	User     string `env:"POSTGRES_USER"`
+	Password string `env:"POSTGRES_PASSWORD"`
+	Name     string `env:"POSTGRES_NAME"`
+	SSLMode  string `env:"POSTGRES_SSL_MODE,default=disable"`
+}
+
+type SQLite struct {
+	// database file, or :memory: for a database living as long as the process
+	Path string `env:"SQLITE_PATH,default=xyz_transaction.db"`
+}
+
+type Migration struct {
+	// refuses to start while cmd/migrate has pending or modified migrations
+	RequireCurrent bool `env:"MIGRATION_REQUIRE_CURRENT,default=false"`
+	// applies the pending migrations on start, e.g. for an in-memory SQLite database
+	AutoApply bool `env:"MIGRATION_AUTO_APPLY,default=false"`
+}
+
 type JWTConfig struct {
 	JwtSecretKey  string        `env:"JWT_SECRET_KEY"`
 	TokenDuration time.Duration `env:"JWT_DURATION,default=30m"`
 }
 
+type Auth struct {
+	// json, yaml or yml authorization policy, see policy.example.yaml
+	PolicyFile string `env:"AUTH_POLICY_FILE,default=policy.yaml"`
+}
+
+type TLS struct {
+	// server certificate and key, reloaded on change, TLS is off when empty
+	CertFile string `env:"TLS_CERT_FILE"`
+	KeyFile  string `env:"TLS_KEY_FILE"`
+	// CA bundle of accepted client certificates, requires mTLS when set
+	ClientCAFile string `env:"TLS_CLIENT_CA_FILE"`
+}
+
 type ClientURL struct {
 	Consumer string `env:"CLIENT_URL_CONSUMER"`
 }
 
+type ClientTLS struct {
+	ConsumerSSL bool `env:"CLIENT_CONSUMER_SSL,default=false"`
+	// CA bundle trusted for the consumer limit service, the system pool when empty
+	ConsumerCAFile string `env:"CLIENT_CONSUMER_CA_FILE"`
+	// client certificate and key presented for mTLS, reloaded on change
+	ConsumerCertFile string `env:"CLIENT_CONSUMER_CERT_FILE"`
+	ConsumerKeyFile  string `env:"CLIENT_CONSUMER_KEY_FILE"`
+}
+
+type ClientResilience struct {
+	// deadline of every attempt, on top of the deadline of the caller
+	ConsumerTimeout time.Duration `env:"CLIENT_CONSUMER_TIMEOUT,default=2s"`
+	// retries of the limit lookup, limit updates are never retried here
+	ConsumerMaxRetries      int           `env:"CLIENT_CONSUMER_MAX_RETRIES,default=2"`
+	ConsumerRetryBackoff    time.Duration `env:"CLIENT_CONSUMER_RETRY_BACKOFF,default=100ms"`
+	ConsumerRetryMaxBackoff time.Duration `env:"CLIENT_CONSUMER_RETRY_MAX_BACKOFF,default=1s"`
+	// consecutive failures opening the circuit breaker, 0 disables it
+	ConsumerBreakerFailures    uint32        `env:"CLIENT_CONSUMER_BREAKER_FAILURES,default=5"`
+	ConsumerBreakerOpenTimeout time.Duration `env:"CLIENT_CONSUMER_BREAKER_OPEN_TIMEOUT,default=30s"`
+}
+
+type ConsumerLimit struct {
+	// serves ConsumerLimitService from this process instead of calling CLIENT_URL_CONSUMER
+	Embedded bool `env:"CONSUMER_LIMIT_EMBEDDED,default=false"`
+}
+
+type Pricing struct {
+	Method         string `env:"PRICING_METHOD,default=flat"`
+	AnnualRateBps  uint32 `env:"PRICING_ANNUAL_RATE_BPS,default=0"`
+	AdminFeeBps    uint32 `env:"PRICING_ADMIN_FEE_BPS,default=0"`
+	RoundingUnit   uint64 `env:"PRICING_ROUNDING_UNIT,default=1"`
+	RejectMismatch bool   `env:"PRICING_REJECT_MISMATCH,default=false"`
+	// rejects contracts without a catalog product instead of pricing them
+	// with the settings above
+	RequireProduct bool `env:"PRICING_REQUIRE_PRODUCT,default=false"`
+}
+
+type ContractNumber struct {
+	// legacy, sequence or opaque
+	Strategy string `env:"CONTRACT_NUMBER_STRATEGY,default=legacy"`
+	// layout built from {date}, {consumer}, {unique} and {check}, the
+	// default layout of the strategy when empty
+	Template string `env:"CONTRACT_NUMBER_TEMPLATE"`
+	// numbers generated for one contract before a collision is given up on
+	MaxAttempts int `env:"CONTRACT_NUMBER_MAX_ATTEMPTS,default=5"`
+}
+
+type Payment struct {
+	// comma separated component order, defaults to penalty,fee,interest,principal
+	Waterfall string `env:"PAYMENT_WATERFALL"`
+	// one-off penalty in basis points of the scheduled amount, charged on an
+	// installment still owed after its due date; 0 disables late penalties
+	LatePenaltyBps uint32 `env:"PAYMENT_LATE_PENALTY_BPS,default=0"`
+}
+
+type Saga struct {
+	RecoveryInterval    time.Duration `env:"SAGA_RECOVERY_INTERVAL,default=30s"`
+	RecoveryGracePeriod time.Duration `env:"SAGA_RECOVERY_GRACE_PERIOD,default=5m"`
+	RecoveryBatchSize   int           `env:"SAGA_RECOVERY_BATCH_SIZE,default=50"`
+	MaxAttempts         uint32        `env:"SAGA_MAX_ATTEMPTS,default=10"`
+}
+
+type Idempotency struct {
+	KeyTTL time.Duration `env:"IDEMPOTENCY_KEY_TTL,default=24h"`
+	// how long a request holds its key before a retry may take it over
+	LockTTL time.Duration `env:"IDEMPOTENCY_LOCK_TTL,default=1m"`
+	// how often expired keys are removed, and how many per round
+	SweepInterval  time.Duration `env:"IDEMPOTENCY_SWEEP_INTERVAL,default=10m"`
+	SweepBatchSize int           `env:"IDEMPOTENCY_SWEEP_BATCH_SIZE,default=100"`
+}
+
+type Pagination struct {
+	DefaultPageSize uint32 `env:"PAGINATION_DEFAULT_PAGE_SIZE,default=20"`
+	MaxPageSize     uint32 `env:"PAGINATION_MAX_PAGE_SIZE,default=100"`
+	StreamBatchSize uint32 `env:"PAGINATION_STREAM_BATCH_SIZE,default=500"`
+}
+
+type Tracing struct {
+	// none, otlp or stdout
+	Exporter     string `env:"TRACING_EXPORTER,default=none"`
+	OTLPEndpoint string `env:"TRACING_OTLP_ENDPOINT,default=localhost:4317"`
+	OTLPInsecure bool   `env:"TRACING_OTLP_INSECURE,default=true"`
+	// stdout exporter target file, spans go to stdout when empty
+	File        string  `env:"TRACING_FILE"`
+	SampleRatio float64 `env:"TRACING_SAMPLE_RATIO,default=1"`
+}
+
 func NewConfig(env string) (*Config, error) {
 	_ = godotenv.Load(env)
 
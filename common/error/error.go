@@ -2,6 +2,7 @@ package error
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 
 	"google.golang.org/grpc/codes"
@@ -74,3 +75,33 @@ func ParseError(err error) *Error {
 
 	return NewError(strToCode[int(statusCode)], outputmsg)
 }
+
+// HttpStatusFromCode maps a gRPC code to the HTTP status used in the Code field of responses.
+func HttpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -1,15 +1,114 @@
 package gorm
 
 import (
-	"gorm.io/driver/mysql"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"xyz-transaction-service/common/config"
+	"xyz-transaction-service/common/mysql"
+	"xyz-transaction-service/common/postgres"
+
+	gormMySQL "gorm.io/driver/mysql"
+	gormPostgres "gorm.io/driver/postgres"
+	gormSQLite "gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// NewGormDB connects to the database selected by DATABASE_DRIVER.
+func NewGormDB(cfg config.Config) (*gorm.DB, error) {
+	switch cfg.Database.Driver {
+	case DriverMySQL:
+		dsn, err := mysql.NewPool(&cfg.MySQL)
+		if err != nil {
+			return nil, err
+		}
+		return NewMySQLGormDB(dsn)
+	case DriverPostgres:
+		dsn, err := postgres.NewPool(&cfg.Postgres)
+		if err != nil {
+			return nil, err
+		}
+		return NewPostgresGormDB(dsn)
+	case DriverSQLite:
+		return NewSQLiteGormDB(cfg.SQLite.Path)
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q, expected mysql, postgres or sqlite", cfg.Database.Driver)
+	}
+}
+
+// DatabaseName names the database selected by DATABASE_DRIVER, e.g. in metrics.
+func DatabaseName(cfg config.Config) string {
+	switch cfg.Database.Driver {
+	case DriverPostgres:
+		return cfg.Postgres.Name
+	case DriverSQLite:
+		return strings.TrimSuffix(filepath.Base(cfg.SQLite.Path), filepath.Ext(cfg.SQLite.Path))
+	default:
+		return cfg.MySQL.Name
+	}
+}
+
 // NewMySQLGormDB builds a connection of gorm to MySQL.
 func NewMySQLGormDB(dsn string) (*gorm.DB, error) {
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
-	})
-	return db, err
+	return gorm.Open(gormMySQL.Open(dsn), newGormConfig())
+}
+
+// NewPostgresGormDB builds a connection of gorm to PostgreSQL.
+func NewPostgresGormDB(dsn string) (*gorm.DB, error) {
+	return gorm.Open(gormPostgres.Open(dsn), newGormConfig())
+}
+
+// NewSQLiteGormDB builds a connection of gorm to a SQLite file, or to a
+// database held in memory when path is :memory:. An in-memory database lives
+// in a single connection, which the pool is therefore limited to. Inside
+// Transactor.WithinTransaction every query must then use the context of the
+// callback, a query on any other context waits for the held connection forever.
+func NewSQLiteGormDB(path string) (*gorm.DB, error) {
+	memory := path == ":memory:"
+	dsn := "file:" + path + "?_foreign_keys=on&_busy_timeout=5000"
+	if !memory {
+		dsn += "&_journal_mode=WAL"
+	}
+
+	db, err := gorm.Open(gormSQLite.Open(dsn), newGormConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	if memory {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		sqlDB.SetMaxOpenConns(1)
+	}
+
+	return db, nil
+}
+
+// newGormConfig translates the errors of every driver to the gorm ones, so
+// repositories detect e.g. a duplicate key with gorm.ErrDuplicatedKey.
+func newGormConfig() *gorm.Config {
+	return &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.Info),
+		TranslateError: true,
+	}
+}
+
+// Ping checks that the database behind db answers.
+func Ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.PingContext(ctx)
 }
@@ -0,0 +1,44 @@
+package gorm
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type GormTransactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(db *gorm.DB) *GormTransactor {
+	return &GormTransactor{
+		db: db,
+	}
+}
+
+// WithinTransaction runs fn inside a database transaction carried by ctx.
+// Repositories pick it up through Conn, and nested calls join the outer one.
+func (t *GormTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// Conn returns the transaction bound to ctx, or db when there is none.
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx
+	}
+
+	return db
+}
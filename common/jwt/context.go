@@ -0,0 +1,16 @@
+package jwt
+
+import "context"
+
+type claimsContextKey struct{}
+
+// NewContext returns a copy of ctx carrying the verified claims of the caller.
+func NewContext(ctx context.Context, claims *CustomClaims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// FromContext returns the claims stored by the auth interceptor, if any.
+func FromContext(ctx context.Context) (*CustomClaims, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(*CustomClaims)
+	return claims, ok && claims != nil
+}
@@ -17,6 +17,8 @@ type CustomClaims struct {
 	StandardClaims jwt.StandardClaims
 	Cred           string `json:"cred"`
 	Role           uint32 `json:"role"`
+	// consumer the token belongs to, zero for staff tokens
+	ConsumerId uint64 `json:"consumer_id,omitempty"`
 }
 
 func NewJWT(secretKey string, tokenDuration time.Duration) *JWT {
@@ -26,13 +28,14 @@ func NewJWT(secretKey string, tokenDuration time.Duration) *JWT {
 	}
 }
 
-func (j *JWT) GenerateToken(cred string, role uint32) (string, error) {
+func (j *JWT) GenerateToken(cred string, role uint32, consumerId uint64) (string, error) {
 	claims := &CustomClaims{
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: time.Now().Local().Add(j.tokenDuration).Unix(),
 		},
-		Cred: cred,
-		Role: role,
+		Cred:       cred,
+		Role:       role,
+		ConsumerId: consumerId,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc/status"
+)
+
+const namespace = "xyz_transaction"
+
+// Registry holds every collector of the service. A dedicated registry keeps
+// tests free of collectors registered by imported libraries.
+var Registry = prometheus.NewRegistry()
+
+var (
+	rpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "requests_total",
+		Help:      "gRPC calls handled, by method and status code.",
+	}, []string{"type", "method", "code"})
+
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "request_duration_seconds",
+		Help:      "gRPC call latency, by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"type", "method"})
+
+	queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Database query latency, by table and operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"table", "operation"})
+
+	queryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_errors_total",
+		Help:      "Database queries failed, by table and operation.",
+	}, []string{"table", "operation"})
+
+	consumerLimitCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "consumer_limit_client",
+		Name:      "calls_total",
+		Help:      "Calls to the consumer limit service, by method and status code.",
+	}, []string{"method", "code"})
+
+	consumerLimitDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "consumer_limit_client",
+		Name:      "call_duration_seconds",
+		Help:      "Latency of calls to the consumer limit service, by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	contractsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "contracts_created_total",
+		Help:      "Contracts booked and activated.",
+	})
+
+	otrVolume = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "otr_volume_total",
+		Help:      "Sum of the OTR of the contracts booked and activated.",
+	})
+
+	limitRejections = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "limit_rejections_total",
+		Help:      "Contracts rejected because the available limit is not enough.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		rpcRequests,
+		rpcDuration,
+		queryDuration,
+		queryErrors,
+		consumerLimitCalls,
+		consumerLimitDuration,
+		contractsCreated,
+		otrVolume,
+		limitRejections,
+	)
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveRPC records a served gRPC call; kind is unary or stream.
+func ObserveRPC(kind, method string, start time.Time, err error) {
+	rpcRequests.WithLabelValues(kind, method, status.Code(err).String()).Inc()
+	rpcDuration.WithLabelValues(kind, method).Observe(time.Since(start).Seconds())
+}
+
+// ObserveQuery records a database statement.
+func ObserveQuery(table, operation string, duration time.Duration, err error) {
+	queryDuration.WithLabelValues(table, operation).Observe(duration.Seconds())
+	if err != nil {
+		queryErrors.WithLabelValues(table, operation).Inc()
+	}
+}
+
+// ObserveConsumerLimitCall records a call to the consumer limit service.
+func ObserveConsumerLimitCall(method string, start time.Time, err error) {
+	consumerLimitCalls.WithLabelValues(method, status.Code(err).String()).Inc()
+	consumerLimitDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// ContractCreated counts a contract booked and activated with its OTR.
+func ContractCreated(otr uint64) {
+	contractsCreated.Inc()
+	otrVolume.Add(float64(otr))
+}
+
+// LimitRejected counts a contract rejected for not enough available limit.
+func LimitRejected() {
+	limitRejections.Inc()
+}
@@ -0,0 +1,30 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"xyz-transaction-service/common/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHandlerExposesObservations(t *testing.T) {
+	metrics.ObserveRPC("unary", "/xyz_grpc.TransactionService/CreateTransaction", time.Now(), status.Error(codes.InvalidArgument, "Limit available not enough"))
+	metrics.ObserveConsumerLimitCall("UpdateAvailableLimit", time.Now(), nil)
+	metrics.ContractCreated(1500000)
+	metrics.LimitRejected()
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+
+	assert.Contains(t, string(body), `xyz_transaction_grpc_requests_total{code="InvalidArgument",method="/xyz_grpc.TransactionService/CreateTransaction",type="unary"} 1`)
+	assert.Contains(t, string(body), `xyz_transaction_consumer_limit_client_calls_total{code="OK",method="UpdateAvailableLimit"} 1`)
+	assert.Contains(t, string(body), "xyz_transaction_contracts_created_total 1")
+	assert.Contains(t, string(body), "xyz_transaction_otr_volume_total 1.5e+06")
+	assert.Contains(t, string(body), "xyz_transaction_limit_rejections_total 1")
+}
@@ -0,0 +1,131 @@
+package migration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const SchemaMigrationTableName = "schema_migrations"
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is one version of the schema, read from a pair of
+// <version>_<name>.up.sql and <version>_<name>.down.sql files.
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+	// sha256 of the up script, compared with the one recorded when applied
+	Checksum string
+}
+
+// SchemaMigration records an applied migration. Baselined migrations were
+// marked as applied without running, on a schema created by hand.
+type SchemaMigration struct {
+	Version   uint64    `json:"version" gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `json:"name" gorm:"size:255;not null"`
+	Checksum  string    `json:"checksum" gorm:"size:64;not null"`
+	Baseline  bool      `json:"baseline" gorm:"not null"`
+	AppliedAt time.Time `json:"applied_at" gorm:"not null"`
+}
+
+func (s *SchemaMigration) TableName() string {
+	return SchemaMigrationTableName
+}
+
+// Load reads the migrations of fsys in version order. Every version needs an
+// up script; a missing down script makes it irreversible.
+func Load(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+
+		match := fileNamePattern.FindStringSubmatch(e.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration %s: name must be <version>_<name>.up.sql or <version>_<name>.down.sql", e.Name())
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("migration %s: version must be a positive number", e.Name())
+		}
+
+		b, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", e.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %s: version %d is already used by %s", e.Name(), version, m.Name)
+		}
+
+		if match[3] == "up" {
+			m.Up = string(b)
+			m.Checksum = Checksum(m.Up)
+		} else {
+			m.Down = string(b)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Checksum == "" {
+			return nil, fmt.Errorf("migration %04d_%s: missing up script", m.Version, m.Name)
+		}
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Checksum returns the hex sha256 of a script.
+func Checksum(script string) string {
+	sum := sha256.Sum256([]byte(script))
+	return hex.EncodeToString(sum[:])
+}
+
+// Statements splits a script on the semicolons ending a line, dropping
+// comment-only lines, since the driver runs one statement per call.
+func Statements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+
+	return statements
+}
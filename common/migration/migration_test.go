@@ -0,0 +1,89 @@
+package migration_test
+
+import (
+	"testing"
+	"testing/fstest"
+	"xyz-transaction-service/common/migration"
+	"xyz-transaction-service/migrations"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_add_index.up.sql":       {Data: []byte("CREATE INDEX idx ON t (a);")},
+		"0001_create_table.up.sql":    {Data: []byte("CREATE TABLE t (a INT);")},
+		"0001_create_table.down.sql":  {Data: []byte("DROP TABLE t;")},
+		"README.md":                   {Data: []byte("ignored")},
+		"0003_irreversible.up.sql":    {Data: []byte("UPDATE t SET a = 1;")},
+		"0003_irreversible.notes.txt": {Data: []byte("ignored")},
+	}
+
+	list, err := migration.Load(fsys)
+	assert.NoError(t, err)
+	assert.Len(t, list, 3)
+	assert.Equal(t, uint64(1), list[0].Version)
+	assert.Equal(t, "create_table", list[0].Name)
+	assert.Equal(t, "DROP TABLE t;", list[0].Down)
+	assert.Equal(t, migration.Checksum("CREATE TABLE t (a INT);"), list[0].Checksum)
+	assert.Equal(t, uint64(2), list[1].Version)
+	assert.Empty(t, list[2].Down)
+}
+
+func TestLoadRejectsInvalidFiles(t *testing.T) {
+	for name, fsys := range map[string]fstest.MapFS{
+		"bad name":          {"create_table.up.sql": {Data: []byte("x;")}},
+		"zero version":      {"0_create_table.up.sql": {Data: []byte("x;")}},
+		"missing up":        {"0001_create_table.down.sql": {Data: []byte("x;")}},
+		"duplicate version": {"0001_a.up.sql": {Data: []byte("x;")}, "0001_b.up.sql": {Data: []byte("y;")}},
+	} {
+		_, err := migration.Load(fsys)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestStatements(t *testing.T) {
+	statements := migration.Statements(`
+-- the table
+CREATE TABLE t (
+    a INT, -- inline comments stay
+    b VARCHAR(8) DEFAULT ';'
+);
+
+CREATE INDEX idx_t_a ON t (a);
+`)
+
+	assert.Equal(t, []string{
+		"CREATE TABLE t (\n    a INT, -- inline comments stay\n    b VARCHAR(8) DEFAULT ';'\n);",
+		"CREATE INDEX idx_t_a ON t (a);",
+	}, statements)
+}
+
+func TestDriverMigrations(t *testing.T) {
+	var names []string
+	for _, driver := range []string{"mysql", "postgres", "sqlite"} {
+		files, err := migrations.For(driver)
+		assert.NoError(t, err)
+		list, err := migration.Load(files)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, list)
+
+		var driverNames []string
+		for i, m := range list {
+			// versions are contiguous, so a gap means a file was lost
+			assert.Equal(t, uint64(i+1), m.Version, driver)
+			assert.NotEmpty(t, m.Down, driver+" "+m.Name)
+			assert.NotEmpty(t, migration.Statements(m.Up), driver+" "+m.Name)
+			driverNames = append(driverNames, m.Name)
+		}
+
+		// every driver ships the same versions
+		if names == nil {
+			names = driverNames
+		}
+		assert.Equal(t, names, driverNames, driver)
+	}
+
+	_, err := migrations.For("oracle")
+	assert.Error(t, err)
+}
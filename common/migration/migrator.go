@@ -0,0 +1,306 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+	"xyz-transaction-service/common/config"
+	"xyz-transaction-service/migrations"
+
+	"gorm.io/gorm"
+)
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []*Migration
+}
+
+func NewMigrator(db *gorm.DB, migrations []*Migration) *Migrator {
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}
+}
+
+// Status is a known migration, or an applied one missing from the files,
+// together with what the schema table records for it.
+type Status struct {
+	Version   uint64
+	Name      string
+	Applied   bool
+	Baseline  bool
+	AppliedAt time.Time
+	// the up script changed after being applied
+	Modified bool
+	// applied but unknown to this build, e.g. by a newer release
+	Missing bool
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	if err := m.db.WithContext(ctx).AutoMigrate(&SchemaMigration{}); err != nil {
+		return fmt.Errorf("create %s: %w", SchemaMigrationTableName, err)
+	}
+	return nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[uint64]*SchemaMigration, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+
+	var rows []*SchemaMigration
+	if err := m.db.WithContext(ctx).Order("version asc").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("read %s: %w", SchemaMigrationTableName, err)
+	}
+
+	applied := make(map[uint64]*SchemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// Status lists every migration in version order.
+func (m *Migrator) Status(ctx context.Context) ([]*Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []*Status
+	known := make(map[uint64]bool, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = true
+		s := &Status{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			s.Applied = true
+			s.Baseline = row.Baseline
+			s.AppliedAt = row.AppliedAt
+			s.Modified = row.Checksum != migration.Checksum
+		}
+		statuses = append(statuses, s)
+	}
+	for version, row := range applied {
+		if !known[version] {
+			statuses = append(statuses, &Status{Version: version, Name: row.Name, Applied: true, Baseline: row.Baseline, AppliedAt: row.AppliedAt, Missing: true})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// pending returns the migrations left to apply. It fails when an applied
+// script was modified, or when a migration older than the latest applied one
+// is missing, since applying it late would run it against a schema it was
+// not written for.
+func (m *Migrator) pending(ctx context.Context) ([]*Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest uint64
+	for _, s := range statuses {
+		if s.Modified {
+			return nil, fmt.Errorf("migration %04d_%s was modified after being applied", s.Version, s.Name)
+		}
+		if s.Applied && s.Version > latest {
+			latest = s.Version
+		}
+	}
+
+	var pending []*Migration
+	for i, s := range statuses {
+		if s.Applied {
+			continue
+		}
+		if s.Version < latest {
+			return nil, fmt.Errorf("migration %04d_%s is older than the applied version %d", s.Version, s.Name, latest)
+		}
+		pending = append(pending, m.find(statuses[i].Version))
+	}
+
+	return pending, nil
+}
+
+func (m *Migrator) find(version uint64) *Migration {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration
+		}
+	}
+	return nil
+}
+
+// Up applies the pending migrations up to and including target, or all of
+// them when target is 0, and returns the ones applied. Every migration runs
+// in its own transaction, but MySQL commits DDL statements implicitly, so
+// there a migration failing halfway has to be repaired by hand before running
+// Up again.
+func (m *Migrator) Up(ctx context.Context, target uint64) ([]*Migration, error) {
+	pending, err := m.pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []*Migration
+	for _, migration := range pending {
+		if target != 0 && migration.Version > target {
+			break
+		}
+
+		log.Printf("INFO: [Migrator - Up] Applying migration %04d_%s\n", migration.Version, migration.Name)
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := exec(tx, migration.Up); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				Checksum:  migration.Checksum,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("apply migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Down rolls back the latest steps applied migrations and returns the ones
+// rolled back.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]*Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []*Migration
+	for i := len(statuses) - 1; i >= 0 && len(done) < steps; i-- {
+		s := statuses[i]
+		if !s.Applied {
+			continue
+		}
+		if s.Missing {
+			return done, fmt.Errorf("migration %04d_%s is unknown to this build and cannot be rolled back", s.Version, s.Name)
+		}
+		migration := m.find(s.Version)
+		if migration.Down == "" {
+			return done, fmt.Errorf("migration %04d_%s has no down script", migration.Version, migration.Name)
+		}
+
+		log.Printf("INFO: [Migrator - Down] Rolling back migration %04d_%s\n", migration.Version, migration.Name)
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := exec(tx, migration.Down); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{Version: migration.Version}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("roll back migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Baseline marks every migration up to and including version as applied
+// without running it, for a database whose schema was created by hand. It
+// only runs against an empty schema table.
+func (m *Migrator) Baseline(ctx context.Context, version uint64) ([]*Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(applied) > 0 {
+		return nil, fmt.Errorf("cannot baseline, %d migrations are already recorded", len(applied))
+	}
+	if m.find(version) == nil {
+		return nil, fmt.Errorf("unknown migration version %d", version)
+	}
+
+	var done []*Migration
+	err = m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		for _, migration := range m.migrations {
+			if migration.Version > version {
+				break
+			}
+			if err := tx.Create(&SchemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				Checksum:  migration.Checksum,
+				Baseline:  true,
+				AppliedAt: now,
+			}).Error; err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("baseline version %d: %w", version, err)
+	}
+
+	return done, nil
+}
+
+// Check fails when migrations are pending or an applied one was modified, so
+// a server can refuse to start on a schema it does not expect.
+func (m *Migrator) Check(ctx context.Context) error {
+	pending, err := m.pending(ctx)
+	if err != nil {
+		return fmt.Errorf("database schema: %w", err)
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("database schema is behind, %d migrations pending starting with %04d_%s, run cmd/migrate up", len(pending), pending[0].Version, pending[0].Name)
+	}
+
+	return nil
+}
+
+func exec(tx *gorm.DB, script string) error {
+	for _, statement := range Statements(script) {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Prepare readies the schema of db for a server starting on it: the pending
+// migrations of driver are applied with MIGRATION_AUTO_APPLY, or only checked
+// with MIGRATION_REQUIRE_CURRENT.
+func Prepare(ctx context.Context, db *gorm.DB, driver string, cfg config.Migration) error {
+	if !cfg.AutoApply && !cfg.RequireCurrent {
+		return nil
+	}
+
+	files, err := migrations.For(driver)
+	if err != nil {
+		return err
+	}
+	list, err := Load(files)
+	if err != nil {
+		return err
+	}
+
+	migrator := NewMigrator(db, list)
+	if cfg.AutoApply {
+		if _, err := migrator.Up(ctx, 0); err != nil {
+			return err
+		}
+	}
+
+	return migrator.Check(ctx)
+}
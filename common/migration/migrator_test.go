@@ -0,0 +1,171 @@
+package migration_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+	"xyz-transaction-service/common/config"
+	gormConn "xyz-transaction-service/common/gorm"
+	"xyz-transaction-service/common/migration"
+	"xyz-transaction-service/migrations"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupSQLite(t *testing.T) *gorm.DB {
+	db, err := gormConn.NewSQLiteGormDB(":memory:")
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		_ = sqlDB.Close()
+	})
+	return db
+}
+
+func testMigrations(t *testing.T) []*migration.Migration {
+	list, err := migration.Load(fstest.MapFS{
+		"0001_create_a.up.sql":   {Data: []byte("CREATE TABLE a (id INTEGER);")},
+		"0001_create_a.down.sql": {Data: []byte("DROP TABLE a;")},
+		"0002_create_b.up.sql":   {Data: []byte("CREATE TABLE b (id INTEGER);\nCREATE INDEX idx_b ON b (id);")},
+		"0002_create_b.down.sql": {Data: []byte("DROP TABLE b;")},
+		"0003_create_c.up.sql":   {Data: []byte("CREATE TABLE c (id INTEGER);")},
+	})
+	assert.NoError(t, err)
+	return list
+}
+
+func TestMigratorUpDown(t *testing.T) {
+	db := setupSQLite(t)
+	ctx := context.Background()
+	migrator := migration.NewMigrator(db, testMigrations(t))
+
+	assert.ErrorContains(t, migrator.Check(ctx), "3 migrations pending")
+
+	applied, err := migrator.Up(ctx, 2)
+	assert.NoError(t, err)
+	assert.Len(t, applied, 2)
+	assert.True(t, db.Migrator().HasTable("b"))
+	assert.False(t, db.Migrator().HasTable("c"))
+
+	applied, err = migrator.Up(ctx, 0)
+	assert.NoError(t, err)
+	assert.Len(t, applied, 1)
+	assert.NoError(t, migrator.Check(ctx))
+
+	// 0003 has no down script
+	rolledBack, err := migrator.Down(ctx, 1)
+	assert.Error(t, err)
+	assert.Empty(t, rolledBack)
+
+	statuses, err := migrator.Status(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 3)
+	for _, s := range statuses {
+		assert.True(t, s.Applied)
+		assert.False(t, s.Modified)
+	}
+
+	// a build without 0003 sees it as applied but missing
+	older := migration.NewMigrator(db, testMigrations(t)[:2])
+	statuses, err = older.Status(ctx)
+	assert.NoError(t, err)
+	assert.True(t, statuses[2].Missing)
+	assert.NoError(t, older.Check(ctx))
+}
+
+func TestMigratorRollsBackInReverseOrder(t *testing.T) {
+	db := setupSQLite(t)
+	ctx := context.Background()
+	migrator := migration.NewMigrator(db, testMigrations(t)[:2])
+
+	_, err := migrator.Up(ctx, 0)
+	assert.NoError(t, err)
+
+	rolledBack, err := migrator.Down(ctx, 5)
+	assert.NoError(t, err)
+	assert.Len(t, rolledBack, 2)
+	assert.Equal(t, uint64(2), rolledBack[0].Version)
+	assert.False(t, db.Migrator().HasTable("a"))
+	assert.ErrorContains(t, migrator.Check(ctx), "2 migrations pending")
+}
+
+func TestMigratorDetectsModifiedMigration(t *testing.T) {
+	db := setupSQLite(t)
+	ctx := context.Background()
+
+	_, err := migration.NewMigrator(db, testMigrations(t)).Up(ctx, 1)
+	assert.NoError(t, err)
+
+	modified := testMigrations(t)
+	modified[0].Up = "CREATE TABLE a (id INTEGER, name TEXT);"
+	modified[0].Checksum = migration.Checksum(modified[0].Up)
+
+	migrator := migration.NewMigrator(db, modified)
+	assert.ErrorContains(t, migrator.Check(ctx), "0001_create_a was modified")
+	_, err = migrator.Up(ctx, 0)
+	assert.Error(t, err)
+	assert.False(t, db.Migrator().HasTable("b"))
+}
+
+func TestMigratorBaseline(t *testing.T) {
+	db := setupSQLite(t)
+	ctx := context.Background()
+	migrator := migration.NewMigrator(db, testMigrations(t))
+
+	// the schema of 0001 and 0002 was created by hand
+	assert.NoError(t, db.Exec("CREATE TABLE a (id INTEGER)").Error)
+	assert.NoError(t, db.Exec("CREATE TABLE b (id INTEGER)").Error)
+
+	_, err := migrator.Baseline(ctx, 9)
+	assert.Error(t, err)
+
+	baselined, err := migrator.Baseline(ctx, 2)
+	assert.NoError(t, err)
+	assert.Len(t, baselined, 2)
+
+	_, err = migrator.Baseline(ctx, 2)
+	assert.Error(t, err)
+
+	applied, err := migrator.Up(ctx, 0)
+	assert.NoError(t, err)
+	assert.Len(t, applied, 1)
+	assert.Equal(t, uint64(3), applied[0].Version)
+
+	statuses, err := migrator.Status(ctx)
+	assert.NoError(t, err)
+	assert.True(t, statuses[0].Baseline)
+	assert.False(t, statuses[2].Baseline)
+}
+
+func TestSQLiteSchemaMigrations(t *testing.T) {
+	db := setupSQLite(t)
+	ctx := context.Background()
+
+	files, err := migrations.For("sqlite")
+	assert.NoError(t, err)
+	list, err := migration.Load(files)
+	assert.NoError(t, err)
+	migrator := migration.NewMigrator(db, list)
+
+	_, err = migrator.Up(ctx, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, migrator.Check(ctx))
+	assert.True(t, db.Migrator().HasTable("transactions"))
+
+	rolledBack, err := migrator.Down(ctx, len(list))
+	assert.NoError(t, err)
+	assert.Len(t, rolledBack, len(list))
+	assert.False(t, db.Migrator().HasTable("transactions"))
+}
+
+func TestPrepare(t *testing.T) {
+	ctx := context.Background()
+
+	assert.NoError(t, migration.Prepare(ctx, setupSQLite(t), gormConn.DriverSQLite, config.Migration{}))
+	assert.ErrorContains(t, migration.Prepare(ctx, setupSQLite(t), gormConn.DriverSQLite, config.Migration{RequireCurrent: true}), "database schema is behind")
+
+	db := setupSQLite(t)
+	assert.NoError(t, migration.Prepare(ctx, db, gormConn.DriverSQLite, config.Migration{AutoApply: true}))
+	assert.True(t, db.Migrator().HasTable("consumer_limits"))
+}
@@ -0,0 +1,12 @@
+package postgres
+
+import (
+	"fmt"
+	"xyz-transaction-service/common/config"
+)
+
+func NewPool(cfg *config.Postgres) (string, error) {
+	connCfg := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s TimeZone=UTC", cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode)
+
+	return connCfg, nil
+}
@@ -0,0 +1,46 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"xyz-transaction-service/common/config"
+	"xyz-transaction-service/common/tracing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStartSpanJoinsParentTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	assert.Empty(t, tracing.TraceId(context.Background()))
+
+	ctx, parent := tracing.StartSpan(context.Background(), "TransactionHandler - createTransaction")
+	childCtx, child := tracing.StartSpan(ctx, "TransactionRepository - Create")
+	tracing.RecordError(child, errors.New("duplicate contract number"))
+	child.End()
+	parent.End()
+
+	assert.NotEmpty(t, tracing.TraceId(ctx))
+	assert.Equal(t, tracing.TraceId(ctx), tracing.TraceId(childCtx))
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "TransactionRepository - Create", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+}
+
+func TestNewTracerProviderRejectsUnknownExporter(t *testing.T) {
+	_, err := tracing.NewTracerProvider(context.Background(), "xyz-grpc", config.Tracing{Exporter: "zipkin"})
+	assert.Error(t, err)
+
+	shutdown, err := tracing.NewTracerProvider(context.Background(), "xyz-grpc", config.Tracing{Exporter: tracing.ExporterNone})
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
@@ -9,6 +9,9 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+// RequestIdHeader carries the id correlating logs and audit records of one request.
+const RequestIdHeader = "x-request-id"
+
 func GetMetadataAuthorization(ctx context.Context) (string, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
@@ -26,3 +29,18 @@ func GetMetadataAuthorization(ctx context.Context) (string, error) {
 
 	return authHeader, nil
 }
+
+// GetMetadataRequestId returns the x-request-id sent by the caller, or an
+// empty string when none was provided.
+func GetMetadataRequestId(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	if values := md.Get(RequestIdHeader); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,107 @@
+// Package validation collects the field violations of a request and reports
+// them as one InvalidArgument status carrying a google.rpc.BadRequest detail,
+// so clients can map every problem back to the field that caused it.
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Violations gathers the violations of one request. Fields are named by
+// their proto path, such as filter.created_from.
+type Violations struct {
+	prefix     string
+	violations *[]*errdetails.BadRequest_FieldViolation
+}
+
+func NewViolations() *Violations {
+	return &Violations{violations: &[]*errdetails.BadRequest_FieldViolation{}}
+}
+
+// Nested returns Violations that record into v with field names under prefix.
+func (v *Violations) Nested(prefix string) *Violations {
+	return &Violations{prefix: v.field(prefix), violations: v.violations}
+}
+
+func (v *Violations) field(name string) string {
+	if v.prefix == "" {
+		return name
+	}
+	return v.prefix + "." + name
+}
+
+// Add records that field is invalid.
+func (v *Violations) Add(field, format string, args ...interface{}) {
+	*v.violations = append(*v.violations, &errdetails.BadRequest_FieldViolation{
+		Field:       v.field(field),
+		Description: fmt.Sprintf(format, args...),
+	})
+}
+
+// Check records description against field unless ok holds.
+func (v *Violations) Check(ok bool, field, description string) {
+	if !ok {
+		v.Add(field, "%s", description)
+	}
+}
+
+// Required records a violation for a blank value.
+func (v *Violations) Required(value, field string) {
+	v.Check(strings.TrimSpace(value) != "", field, "is required")
+}
+
+// MaxLength records a violation for a value longer than max bytes.
+func (v *Violations) MaxLength(value string, max int, field string) {
+	v.Check(len(value) <= max, field, fmt.Sprintf("must be at most %d characters", max))
+}
+
+// Timestamp parses an optional RFC3339 value, recording a violation and
+// returning the zero time when it is malformed.
+func (v *Violations) Timestamp(value, field string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		v.Add(field, "must be an RFC3339 timestamp")
+		return time.Time{}
+	}
+	return parsed
+}
+
+// Err returns nil without violations, otherwise an InvalidArgument status
+// whose message lists them and whose details hold a BadRequest.
+func (v *Violations) Err() error {
+	if len(*v.violations) == 0 {
+		return nil
+	}
+
+	var messages []string
+	for _, violation := range *v.violations {
+		messages = append(messages, violation.Field+" "+violation.Description)
+	}
+
+	st := status.New(codes.InvalidArgument, "invalid request: "+strings.Join(messages, "; "))
+	detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: *v.violations})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// FieldViolations returns the BadRequest field violations carried by err.
+func FieldViolations(err error) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			violations = append(violations, badRequest.GetFieldViolations()...)
+		}
+	}
+	return violations
+}
@@ -0,0 +1,31 @@
+package validation_test
+
+import (
+	"testing"
+	"xyz-transaction-service/common/validation"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestViolationsErr(t *testing.T) {
+	v := validation.NewViolations()
+	assert.NoError(t, v.Err())
+
+	v.Check(false, "otr", "must be greater than zero")
+	v.Required(" ", "asset_name")
+	filter := v.Nested("filter")
+	filter.Timestamp("yesterday", "created_from")
+	filter.MaxLength("abcd", 3, "asset_name")
+
+	err := v.Err()
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "invalid request: otr must be greater than zero; asset_name is required; filter.created_from must be an RFC3339 timestamp; filter.asset_name must be at most 3 characters", status.Convert(err).Message())
+
+	violations := validation.FieldViolations(err)
+	assert.Len(t, violations, 4)
+	assert.Equal(t, "filter.created_from", violations[2].Field)
+	assert.Equal(t, "must be an RFC3339 timestamp", violations[2].Description)
+}
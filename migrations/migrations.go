@@ -0,0 +1,26 @@
+// Package migrations embeds the versioned SQL schema of the service, applied
+// with cmd/migrate. Every driver has its own directory holding the same
+// versions. Files are named <version>_<name>.up.sql and
+// <version>_<name>.down.sql, statements end with a semicolon at the end of a
+// line, and an applied file must never be edited: add a new version instead.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+)
+
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// For returns the migrations of the schema for driver, mysql, postgres or
+// sqlite.
+func For(driver string) (fs.FS, error) {
+	switch driver {
+	case "mysql", "postgres", "sqlite":
+		return fs.Sub(files, driver)
+	default:
+		return nil, fmt.Errorf("no migrations for database driver %q", driver)
+	}
+}
@@ -0,0 +1,22 @@
+CREATE TABLE transactions (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    contract_number VARCHAR(64) NOT NULL,
+    consumer_id BIGINT UNSIGNED NOT NULL,
+    tenor INT UNSIGNED NOT NULL,
+    otr BIGINT UNSIGNED NOT NULL,
+    admin_fee BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    installment BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    interest BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    asset_name VARCHAR(255) NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    created_at DATETIME(3) NOT NULL,
+    updated_at DATETIME(3) NOT NULL,
+    deleted_at DATETIME(3) NULL,
+    deleted_reason VARCHAR(255) NOT NULL DEFAULT '',
+    PRIMARY KEY (id),
+    -- TransactionRepository.Create reports a duplicate as AlreadyExists
+    UNIQUE KEY uk_transactions_contract_number (contract_number),
+    KEY idx_transactions_consumer_id (consumer_id, created_at),
+    KEY idx_transactions_created_at (created_at, id),
+    KEY idx_transactions_deleted_at (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,54 @@
+CREATE TABLE installments (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    transaction_id BIGINT UNSIGNED NOT NULL,
+    contract_number VARCHAR(64) NOT NULL,
+    installment_number INT UNSIGNED NOT NULL,
+    due_date DATE NOT NULL,
+    principal BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    interest BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    fee BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    amount BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    outstanding_balance BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    penalty BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    paid_principal BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    paid_interest BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    paid_fee BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    paid_penalty BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    status VARCHAR(32) NOT NULL,
+    paid_at DATETIME(3) NULL,
+    created_at DATETIME(3) NOT NULL,
+    updated_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uk_installments_transaction_number (transaction_id, installment_number),
+    CONSTRAINT fk_installments_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE payments (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    transaction_id BIGINT UNSIGNED NOT NULL,
+    contract_number VARCHAR(64) NOT NULL,
+    payment_reference VARCHAR(128) NOT NULL,
+    amount BIGINT UNSIGNED NOT NULL,
+    paid_at DATETIME(3) NOT NULL,
+    created_at DATETIME(3) NOT NULL,
+    updated_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (id),
+    -- PaymentRepository.Create reports a duplicate as AlreadyExists
+    UNIQUE KEY uk_payments_payment_reference (payment_reference),
+    KEY idx_payments_transaction_id (transaction_id),
+    CONSTRAINT fk_payments_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE payment_allocations (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    payment_id BIGINT UNSIGNED NOT NULL,
+    installment_id BIGINT UNSIGNED NOT NULL,
+    installment_number INT UNSIGNED NOT NULL,
+    component VARCHAR(32) NOT NULL,
+    amount BIGINT UNSIGNED NOT NULL,
+    created_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_payment_allocations_payment_id (payment_id),
+    CONSTRAINT fk_payment_allocations_payment FOREIGN KEY (payment_id) REFERENCES payments (id),
+    CONSTRAINT fk_payment_allocations_installment FOREIGN KEY (installment_id) REFERENCES installments (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
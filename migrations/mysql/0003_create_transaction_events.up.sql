@@ -0,0 +1,15 @@
+CREATE TABLE transaction_events (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    transaction_id BIGINT UNSIGNED NOT NULL,
+    contract_number VARCHAR(64) NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    actor VARCHAR(128) NOT NULL DEFAULT '',
+    actor_role INT UNSIGNED NOT NULL DEFAULT 0,
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    `before` TEXT NULL,
+    `after` TEXT NULL,
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_transaction_events_contract_number (contract_number, id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
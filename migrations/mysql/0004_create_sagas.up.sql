@@ -0,0 +1,37 @@
+CREATE TABLE sagas (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    saga_type VARCHAR(64) NOT NULL,
+    reference VARCHAR(128) NOT NULL,
+    contract_number VARCHAR(64) NOT NULL DEFAULT '',
+    transaction_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    consumer_id BIGINT UNSIGNED NOT NULL,
+    tenor INT UNSIGNED NOT NULL,
+    otr BIGINT UNSIGNED NOT NULL,
+    admin_fee BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    installment BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    interest BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    asset_name VARCHAR(255) NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    last_error TEXT NULL,
+    attempts INT UNSIGNED NOT NULL DEFAULT 0,
+    created_at DATETIME(3) NOT NULL,
+    updated_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_sagas_reference (reference),
+    -- SagaRecoveryWorker polls for stuck sagas
+    KEY idx_sagas_status_updated_at (status, updated_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE saga_steps (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    saga_id BIGINT UNSIGNED NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    sequence INT UNSIGNED NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    error TEXT NULL,
+    created_at DATETIME(3) NOT NULL,
+    updated_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_saga_steps_saga_id (saga_id, sequence),
+    CONSTRAINT fk_saga_steps_saga FOREIGN KEY (saga_id) REFERENCES sagas (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,15 @@
+CREATE TABLE idempotency_keys (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `key` VARCHAR(255) NOT NULL,
+    method VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    response MEDIUMBLOB NULL,
+    expires_at DATETIME(3) NOT NULL,
+    created_at DATETIME(3) NOT NULL,
+    updated_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (id),
+    -- IdempotencyKeyRepository.Create reports a duplicate as AlreadyExists
+    UNIQUE KEY uk_idempotency_keys_method_key (method, `key`),
+    KEY idx_idempotency_keys_expires_at (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,24 @@
+CREATE TABLE consumer_limits (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    consumer_id BIGINT UNSIGNED NOT NULL,
+    tenor INT UNSIGNED NOT NULL,
+    limit_amount BIGINT UNSIGNED NOT NULL,
+    limit_available BIGINT UNSIGNED NOT NULL,
+    created_at DATETIME(3) NOT NULL,
+    updated_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uk_consumer_limits_consumer_tenor (consumer_id, tenor)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE consumer_limit_movements (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    consumer_limit_id BIGINT UNSIGNED NOT NULL,
+    reference VARCHAR(128) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    amount BIGINT UNSIGNED NOT NULL,
+    created_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (id),
+    -- a debit or restore is applied at most once per reference
+    UNIQUE KEY uk_consumer_limit_movements_reference_type (reference, type),
+    CONSTRAINT fk_consumer_limit_movements_limit FOREIGN KEY (consumer_limit_id) REFERENCES consumer_limits (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
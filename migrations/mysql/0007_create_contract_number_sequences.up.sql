@@ -0,0 +1,7 @@
+-- one row per day, incremented by the sequence contract number strategy
+CREATE TABLE contract_number_sequences (
+    day CHAR(8) NOT NULL,
+    counter BIGINT UNSIGNED NOT NULL,
+    updated_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (day)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
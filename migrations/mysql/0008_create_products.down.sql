@@ -0,0 +1,10 @@
+ALTER TABLE sagas
+    DROP COLUMN product_version,
+    DROP COLUMN product_code;
+
+ALTER TABLE transactions
+    DROP COLUMN product_version,
+    DROP COLUMN product_code;
+
+DROP TABLE IF EXISTS product_tenors;
+DROP TABLE IF EXISTS products;
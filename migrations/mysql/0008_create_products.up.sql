@@ -0,0 +1,40 @@
+-- a row per version of a product; ProductRepository.Create reports a taken
+-- version as AlreadyExists
+CREATE TABLE products (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    code VARCHAR(64) NOT NULL,
+    version INT UNSIGNED NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    pricing_method VARCHAR(16) NOT NULL,
+    admin_fee_type VARCHAR(16) NOT NULL,
+    admin_fee_amount BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    admin_fee_bps INT UNSIGNED NOT NULL DEFAULT 0,
+    admin_fee_min BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    admin_fee_max BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    effective_from DATETIME(3) NOT NULL,
+    effective_to DATETIME(3) NULL,
+    created_at DATETIME(3) NOT NULL,
+    updated_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uk_products_code_version (code, version),
+    KEY idx_products_effective_from (effective_from)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE product_tenors (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    product_id BIGINT UNSIGNED NOT NULL,
+    tenor INT UNSIGNED NOT NULL,
+    annual_rate_bps INT UNSIGNED NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uk_product_tenors_product_tenor (product_id, tenor),
+    CONSTRAINT fk_product_tenors_product FOREIGN KEY (product_id) REFERENCES products (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- contracts and their sagas remember the product version they were priced with
+ALTER TABLE transactions
+    ADD COLUMN product_code VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN product_version INT UNSIGNED NOT NULL DEFAULT 0;
+
+ALTER TABLE sagas
+    ADD COLUMN product_code VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN product_version INT UNSIGNED NOT NULL DEFAULT 0;
@@ -0,0 +1,21 @@
+CREATE TABLE transactions (
+    id BIGSERIAL PRIMARY KEY,
+    contract_number VARCHAR(64) NOT NULL,
+    consumer_id BIGINT NOT NULL,
+    tenor BIGINT NOT NULL,
+    otr BIGINT NOT NULL,
+    admin_fee BIGINT NOT NULL DEFAULT 0,
+    installment BIGINT NOT NULL DEFAULT 0,
+    interest BIGINT NOT NULL DEFAULT 0,
+    asset_name VARCHAR(255) NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    deleted_at TIMESTAMPTZ NULL,
+    deleted_reason VARCHAR(255) NOT NULL DEFAULT ''
+);
+-- TransactionRepository.Create reports a duplicate as AlreadyExists
+CREATE UNIQUE INDEX uk_transactions_contract_number ON transactions (contract_number);
+CREATE INDEX idx_transactions_consumer_id ON transactions (consumer_id, created_at);
+CREATE INDEX idx_transactions_created_at ON transactions (created_at, id);
+CREATE INDEX idx_transactions_deleted_at ON transactions (deleted_at);
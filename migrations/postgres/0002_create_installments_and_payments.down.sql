@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS payment_allocations;
+DROP TABLE IF EXISTS payments;
+DROP TABLE IF EXISTS installments;
@@ -0,0 +1,47 @@
+CREATE TABLE installments (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id BIGINT NOT NULL REFERENCES transactions (id),
+    contract_number VARCHAR(64) NOT NULL,
+    installment_number BIGINT NOT NULL,
+    due_date DATE NOT NULL,
+    principal BIGINT NOT NULL DEFAULT 0,
+    interest BIGINT NOT NULL DEFAULT 0,
+    fee BIGINT NOT NULL DEFAULT 0,
+    amount BIGINT NOT NULL DEFAULT 0,
+    outstanding_balance BIGINT NOT NULL DEFAULT 0,
+    penalty BIGINT NOT NULL DEFAULT 0,
+    paid_principal BIGINT NOT NULL DEFAULT 0,
+    paid_interest BIGINT NOT NULL DEFAULT 0,
+    paid_fee BIGINT NOT NULL DEFAULT 0,
+    paid_penalty BIGINT NOT NULL DEFAULT 0,
+    status VARCHAR(32) NOT NULL,
+    paid_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+CREATE UNIQUE INDEX uk_installments_transaction_number ON installments (transaction_id, installment_number);
+
+CREATE TABLE payments (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id BIGINT NOT NULL REFERENCES transactions (id),
+    contract_number VARCHAR(64) NOT NULL,
+    payment_reference VARCHAR(128) NOT NULL,
+    amount BIGINT NOT NULL,
+    paid_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+-- PaymentRepository.Create reports a duplicate as AlreadyExists
+CREATE UNIQUE INDEX uk_payments_payment_reference ON payments (payment_reference);
+CREATE INDEX idx_payments_transaction_id ON payments (transaction_id);
+
+CREATE TABLE payment_allocations (
+    id BIGSERIAL PRIMARY KEY,
+    payment_id BIGINT NOT NULL REFERENCES payments (id),
+    installment_id BIGINT NOT NULL REFERENCES installments (id),
+    installment_number BIGINT NOT NULL,
+    component VARCHAR(32) NOT NULL,
+    amount BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX idx_payment_allocations_payment_id ON payment_allocations (payment_id);
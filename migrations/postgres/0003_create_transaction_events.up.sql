@@ -0,0 +1,14 @@
+CREATE TABLE transaction_events (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id BIGINT NOT NULL,
+    contract_number VARCHAR(64) NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    actor VARCHAR(128) NOT NULL DEFAULT '',
+    actor_role BIGINT NOT NULL DEFAULT 0,
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    "before" TEXT NULL,
+    "after" TEXT NULL,
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX idx_transaction_events_contract_number ON transaction_events (contract_number, id);
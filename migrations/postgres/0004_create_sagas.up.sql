@@ -0,0 +1,34 @@
+CREATE TABLE sagas (
+    id BIGSERIAL PRIMARY KEY,
+    saga_type VARCHAR(64) NOT NULL,
+    reference VARCHAR(128) NOT NULL,
+    contract_number VARCHAR(64) NOT NULL DEFAULT '',
+    transaction_id BIGINT NOT NULL DEFAULT 0,
+    consumer_id BIGINT NOT NULL,
+    tenor BIGINT NOT NULL,
+    otr BIGINT NOT NULL,
+    admin_fee BIGINT NOT NULL DEFAULT 0,
+    installment BIGINT NOT NULL DEFAULT 0,
+    interest BIGINT NOT NULL DEFAULT 0,
+    asset_name VARCHAR(255) NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    last_error TEXT NULL,
+    attempts BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX idx_sagas_reference ON sagas (reference);
+-- SagaRecoveryWorker polls for stuck sagas
+CREATE INDEX idx_sagas_status_updated_at ON sagas (status, updated_at);
+
+CREATE TABLE saga_steps (
+    id BIGSERIAL PRIMARY KEY,
+    saga_id BIGINT NOT NULL REFERENCES sagas (id),
+    name VARCHAR(64) NOT NULL,
+    sequence BIGINT NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    error TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX idx_saga_steps_saga_id ON saga_steps (saga_id, sequence);
@@ -0,0 +1,14 @@
+CREATE TABLE idempotency_keys (
+    id BIGSERIAL PRIMARY KEY,
+    "key" VARCHAR(255) NOT NULL,
+    method VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    response BYTEA NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+-- IdempotencyKeyRepository.Create reports a duplicate as AlreadyExists
+CREATE UNIQUE INDEX uk_idempotency_keys_method_key ON idempotency_keys (method, "key");
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,21 @@
+CREATE TABLE consumer_limits (
+    id BIGSERIAL PRIMARY KEY,
+    consumer_id BIGINT NOT NULL,
+    tenor BIGINT NOT NULL,
+    limit_amount BIGINT NOT NULL,
+    limit_available BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+CREATE UNIQUE INDEX uk_consumer_limits_consumer_tenor ON consumer_limits (consumer_id, tenor);
+
+CREATE TABLE consumer_limit_movements (
+    id BIGSERIAL PRIMARY KEY,
+    consumer_limit_id BIGINT NOT NULL REFERENCES consumer_limits (id),
+    reference VARCHAR(128) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    amount BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+-- a debit or restore is applied at most once per reference
+CREATE UNIQUE INDEX uk_consumer_limit_movements_reference_type ON consumer_limit_movements (reference, type);
@@ -0,0 +1,34 @@
+-- a row per version of a product; ProductRepository.Create reports a taken
+-- version as AlreadyExists
+CREATE TABLE products (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(64) NOT NULL,
+    version BIGINT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    pricing_method VARCHAR(16) NOT NULL,
+    admin_fee_type VARCHAR(16) NOT NULL,
+    admin_fee_amount BIGINT NOT NULL DEFAULT 0,
+    admin_fee_bps BIGINT NOT NULL DEFAULT 0,
+    admin_fee_min BIGINT NOT NULL DEFAULT 0,
+    admin_fee_max BIGINT NOT NULL DEFAULT 0,
+    effective_from TIMESTAMPTZ NOT NULL,
+    effective_to TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+CREATE UNIQUE INDEX uk_products_code_version ON products (code, version);
+CREATE INDEX idx_products_effective_from ON products (effective_from);
+
+CREATE TABLE product_tenors (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES products (id),
+    tenor BIGINT NOT NULL,
+    annual_rate_bps BIGINT NOT NULL
+);
+CREATE UNIQUE INDEX uk_product_tenors_product_tenor ON product_tenors (product_id, tenor);
+
+-- contracts and their sagas remember the product version they were priced with
+ALTER TABLE transactions ADD COLUMN product_code VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN product_version BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE sagas ADD COLUMN product_code VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE sagas ADD COLUMN product_version BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- an IN_PROGRESS key is only held until locked_until, a key left behind by a
+-- crashed request is then taken over by the next retry
+ALTER TABLE idempotency_keys ADD COLUMN locked_until TIMESTAMPTZ NULL;
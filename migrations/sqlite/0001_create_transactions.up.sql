@@ -0,0 +1,21 @@
+CREATE TABLE transactions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    contract_number VARCHAR(64) NOT NULL,
+    consumer_id INTEGER NOT NULL,
+    tenor INTEGER NOT NULL,
+    otr INTEGER NOT NULL,
+    admin_fee INTEGER NOT NULL DEFAULT 0,
+    installment INTEGER NOT NULL DEFAULT 0,
+    interest INTEGER NOT NULL DEFAULT 0,
+    asset_name VARCHAR(255) NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    deleted_at DATETIME NULL,
+    deleted_reason VARCHAR(255) NOT NULL DEFAULT ''
+);
+-- TransactionRepository.Create reports a duplicate as AlreadyExists
+CREATE UNIQUE INDEX uk_transactions_contract_number ON transactions (contract_number);
+CREATE INDEX idx_transactions_consumer_id ON transactions (consumer_id, created_at);
+CREATE INDEX idx_transactions_created_at ON transactions (created_at, id);
+CREATE INDEX idx_transactions_deleted_at ON transactions (deleted_at);
@@ -0,0 +1,47 @@
+CREATE TABLE installments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    transaction_id INTEGER NOT NULL REFERENCES transactions (id),
+    contract_number VARCHAR(64) NOT NULL,
+    installment_number INTEGER NOT NULL,
+    due_date DATE NOT NULL,
+    principal INTEGER NOT NULL DEFAULT 0,
+    interest INTEGER NOT NULL DEFAULT 0,
+    fee INTEGER NOT NULL DEFAULT 0,
+    amount INTEGER NOT NULL DEFAULT 0,
+    outstanding_balance INTEGER NOT NULL DEFAULT 0,
+    penalty INTEGER NOT NULL DEFAULT 0,
+    paid_principal INTEGER NOT NULL DEFAULT 0,
+    paid_interest INTEGER NOT NULL DEFAULT 0,
+    paid_fee INTEGER NOT NULL DEFAULT 0,
+    paid_penalty INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(32) NOT NULL,
+    paid_at DATETIME NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+CREATE UNIQUE INDEX uk_installments_transaction_number ON installments (transaction_id, installment_number);
+
+CREATE TABLE payments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    transaction_id INTEGER NOT NULL REFERENCES transactions (id),
+    contract_number VARCHAR(64) NOT NULL,
+    payment_reference VARCHAR(128) NOT NULL,
+    amount INTEGER NOT NULL,
+    paid_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+-- PaymentRepository.Create reports a duplicate as AlreadyExists
+CREATE UNIQUE INDEX uk_payments_payment_reference ON payments (payment_reference);
+CREATE INDEX idx_payments_transaction_id ON payments (transaction_id);
+
+CREATE TABLE payment_allocations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    payment_id INTEGER NOT NULL REFERENCES payments (id),
+    installment_id INTEGER NOT NULL REFERENCES installments (id),
+    installment_number INTEGER NOT NULL,
+    component VARCHAR(32) NOT NULL,
+    amount INTEGER NOT NULL,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX idx_payment_allocations_payment_id ON payment_allocations (payment_id);
@@ -0,0 +1,14 @@
+CREATE TABLE transaction_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    transaction_id INTEGER NOT NULL,
+    contract_number VARCHAR(64) NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    actor VARCHAR(128) NOT NULL DEFAULT '',
+    actor_role INTEGER NOT NULL DEFAULT 0,
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    "before" TEXT NULL,
+    "after" TEXT NULL,
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX idx_transaction_events_contract_number ON transaction_events (contract_number, id);
@@ -0,0 +1,34 @@
+CREATE TABLE sagas (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    saga_type VARCHAR(64) NOT NULL,
+    reference VARCHAR(128) NOT NULL,
+    contract_number VARCHAR(64) NOT NULL DEFAULT '',
+    transaction_id INTEGER NOT NULL DEFAULT 0,
+    consumer_id INTEGER NOT NULL,
+    tenor INTEGER NOT NULL,
+    otr INTEGER NOT NULL,
+    admin_fee INTEGER NOT NULL DEFAULT 0,
+    installment INTEGER NOT NULL DEFAULT 0,
+    interest INTEGER NOT NULL DEFAULT 0,
+    asset_name VARCHAR(255) NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    last_error TEXT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+CREATE INDEX idx_sagas_reference ON sagas (reference);
+-- SagaRecoveryWorker polls for stuck sagas
+CREATE INDEX idx_sagas_status_updated_at ON sagas (status, updated_at);
+
+CREATE TABLE saga_steps (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    saga_id INTEGER NOT NULL REFERENCES sagas (id),
+    name VARCHAR(64) NOT NULL,
+    sequence INTEGER NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    error TEXT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+CREATE INDEX idx_saga_steps_saga_id ON saga_steps (saga_id, sequence);
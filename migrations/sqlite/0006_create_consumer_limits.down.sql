@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS consumer_limit_movements;
+DROP TABLE IF EXISTS consumer_limits;
@@ -0,0 +1,21 @@
+CREATE TABLE consumer_limits (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    consumer_id INTEGER NOT NULL,
+    tenor INTEGER NOT NULL,
+    limit_amount INTEGER NOT NULL,
+    limit_available INTEGER NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+CREATE UNIQUE INDEX uk_consumer_limits_consumer_tenor ON consumer_limits (consumer_id, tenor);
+
+CREATE TABLE consumer_limit_movements (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    consumer_limit_id INTEGER NOT NULL REFERENCES consumer_limits (id),
+    reference VARCHAR(128) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    amount INTEGER NOT NULL,
+    created_at DATETIME NOT NULL
+);
+-- a debit or restore is applied at most once per reference
+CREATE UNIQUE INDEX uk_consumer_limit_movements_reference_type ON consumer_limit_movements (reference, type);
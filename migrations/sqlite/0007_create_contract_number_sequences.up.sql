@@ -0,0 +1,6 @@
+-- one row per day, incremented by the sequence contract number strategy
+CREATE TABLE contract_number_sequences (
+    day CHAR(8) PRIMARY KEY,
+    counter BIGINT NOT NULL,
+    updated_at DATETIME NOT NULL
+);
@@ -0,0 +1,34 @@
+-- a row per version of a product; ProductRepository.Create reports a taken
+-- version as AlreadyExists
+CREATE TABLE products (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    code VARCHAR(64) NOT NULL,
+    version INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    pricing_method VARCHAR(16) NOT NULL,
+    admin_fee_type VARCHAR(16) NOT NULL,
+    admin_fee_amount INTEGER NOT NULL DEFAULT 0,
+    admin_fee_bps INTEGER NOT NULL DEFAULT 0,
+    admin_fee_min INTEGER NOT NULL DEFAULT 0,
+    admin_fee_max INTEGER NOT NULL DEFAULT 0,
+    effective_from DATETIME NOT NULL,
+    effective_to DATETIME NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+CREATE UNIQUE INDEX uk_products_code_version ON products (code, version);
+CREATE INDEX idx_products_effective_from ON products (effective_from);
+
+CREATE TABLE product_tenors (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id INTEGER NOT NULL REFERENCES products (id),
+    tenor INTEGER NOT NULL,
+    annual_rate_bps INTEGER NOT NULL
+);
+CREATE UNIQUE INDEX uk_product_tenors_product_tenor ON product_tenors (product_id, tenor);
+
+-- contracts and their sagas remember the product version they were priced with
+ALTER TABLE transactions ADD COLUMN product_code VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN product_version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sagas ADD COLUMN product_code VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE sagas ADD COLUMN product_version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- the saga a CreateTransaction key started, a retry replays or waits for its
+-- outcome instead of starting another saga
+ALTER TABLE idempotency_keys ADD COLUMN saga_reference VARCHAR(128) NOT NULL DEFAULT '';
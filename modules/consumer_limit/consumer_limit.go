@@ -0,0 +1,56 @@
+package consumer_limit
+
+import (
+	"context"
+	"xyz-transaction-service/common/config"
+	"xyz-transaction-service/modules/consumer_limit/internal/builder"
+	"xyz-transaction-service/modules/consumer_limit/internal/handler"
+	"xyz-transaction-service/pb"
+	"xyz-transaction-service/server"
+
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+)
+
+// InitGrpc registers ConsumerLimitService on grpcServer and returns a client
+// calling the same handler in-process, for modules embedding the service.
+func InitGrpc(grpcServer *server.Grpc, cfg config.Config, db *gorm.DB) pb.ConsumerLimitServiceClient {
+	consumerLimit := builder.BuildConsumerLimitHandler(cfg, db)
+	pb.RegisterConsumerLimitServiceServer(grpcServer.Server, consumerLimit)
+
+	return &localClient{handler: consumerLimit}
+}
+
+// localClient skips the network and the interceptors of the server: callers
+// are other modules of this process, not end users holding a token. Like a
+// remote call, a failed call returns no response.
+type localClient struct {
+	handler *handler.ConsumerLimitHandler
+}
+
+func (c *localClient) GetConsumerLimitsByConsumerId(ctx context.Context, in *pb.ConsumerRequest, _ ...grpc.CallOption) (*pb.ConsumerLimitListResponse, error) {
+	return localResponse(c.handler.GetConsumerLimitsByConsumerId(ctx, in))
+}
+
+func (c *localClient) CreateConsumerLimit(ctx context.Context, in *pb.ConsumerLimit, _ ...grpc.CallOption) (*pb.ConsumerLimitResponse, error) {
+	return localResponse(c.handler.CreateConsumerLimit(ctx, in))
+}
+
+func (c *localClient) UpdateAvailableLimit(ctx context.Context, in *pb.UpdateAvailableLimitRequest, _ ...grpc.CallOption) (*pb.ConsumerLimitResponse, error) {
+	return localResponse(c.handler.UpdateAvailableLimit(ctx, in))
+}
+
+func (c *localClient) RestoreAvailableLimit(ctx context.Context, in *pb.UpdateAvailableLimitRequest, _ ...grpc.CallOption) (*pb.ConsumerLimitResponse, error) {
+	return localResponse(c.handler.RestoreAvailableLimit(ctx, in))
+}
+
+func (c *localClient) GetConsumerLimitByConsumerIdAndTenor(ctx context.Context, in *pb.ConsumerIdAndTenorRequest, _ ...grpc.CallOption) (*pb.ConsumerLimitResponse, error) {
+	return localResponse(c.handler.GetConsumerLimitByConsumerIdAndTenor(ctx, in))
+}
+
+func localResponse[T any](res *T, err error) (*T, error) {
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"time"
+	"xyz-transaction-service/pb"
+)
+
+const (
+	ConsumerLimitTableName         = "consumer_limits"
+	ConsumerLimitMovementTableName = "consumer_limit_movements"
+
+	MovementTypeDebit   = "DEBIT"
+	MovementTypeRestore = "RESTORE"
+)
+
+// ConsumerLimit is the credit line of a consumer for one tenor, unique on
+// (consumer_id, tenor).
+type ConsumerLimit struct {
+	Id             uint64    `json:"id"`
+	ConsumerId     uint64    `json:"consumer_id"`
+	Tenor          uint32    `json:"tenor"`
+	LimitAmount    uint64    `json:"limit_amount"`
+	LimitAvailable uint64    `json:"limit_available"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ConsumerLimitMovement records a debit or restore of a limit under the
+// reference of the caller, unique on (reference, type), which makes replays
+// of the same request a no-op.
+type ConsumerLimitMovement struct {
+	Id              uint64    `json:"id"`
+	ConsumerLimitId uint64    `json:"consumer_limit_id"`
+	Reference       string    `json:"reference"`
+	Type            string    `json:"type"`
+	Amount          uint64    `json:"amount"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func NewConsumerLimitEntity(consumerId uint64, tenor uint32, limitAmount uint64) *ConsumerLimit {
+	return &ConsumerLimit{
+		ConsumerId:     consumerId,
+		Tenor:          tenor,
+		LimitAmount:    limitAmount,
+		LimitAvailable: limitAmount,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+}
+
+func NewConsumerLimitMovementEntity(consumerLimitId uint64, reference string, movementType string, amount uint64) *ConsumerLimitMovement {
+	return &ConsumerLimitMovement{
+		ConsumerLimitId: consumerLimitId,
+		Reference:       reference,
+		Type:            movementType,
+		Amount:          amount,
+		CreatedAt:       time.Now(),
+	}
+}
+
+func (c *ConsumerLimit) TableName() string {
+	return ConsumerLimitTableName
+}
+
+func (m *ConsumerLimitMovement) TableName() string {
+	return ConsumerLimitMovementTableName
+}
+
+func ConvertEntityToProto(c *ConsumerLimit) *pb.ConsumerLimit {
+	return &pb.ConsumerLimit{
+		Id:             c.Id,
+		ConsumerId:     c.ConsumerId,
+		Tenor:          c.Tenor,
+		LimitAmount:    c.LimitAmount,
+		LimitAvailable: c.LimitAvailable,
+		CreatedAt:      c.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      c.UpdatedAt.Format(time.RFC3339),
+	}
+}
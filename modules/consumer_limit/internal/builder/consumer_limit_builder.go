@@ -0,0 +1,20 @@
+package builder
+
+import (
+	"xyz-transaction-service/common/config"
+	gormConn "xyz-transaction-service/common/gorm"
+	"xyz-transaction-service/modules/consumer_limit/internal/handler"
+	"xyz-transaction-service/modules/consumer_limit/internal/repository"
+	"xyz-transaction-service/modules/consumer_limit/service"
+
+	"gorm.io/gorm"
+)
+
+func BuildConsumerLimitHandler(cfg config.Config, db *gorm.DB) *handler.ConsumerLimitHandler {
+	consumerLimitRepository := repository.NewConsumerLimitRepository(db)
+	movementRepository := repository.NewConsumerLimitMovementRepository(db)
+	transactor := gormConn.NewTransactor(db)
+	consumerLimitSvc := service.NewConsumerLimitService(cfg, transactor, consumerLimitRepository, movementRepository)
+
+	return handler.NewConsumerLimitHandler(cfg, consumerLimitSvc)
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"xyz-transaction-service/common/config"
+	commonErr "xyz-transaction-service/common/error"
+	"xyz-transaction-service/modules/consumer_limit/entity"
+	"xyz-transaction-service/modules/consumer_limit/service"
+	"xyz-transaction-service/pb"
+
+	"google.golang.org/grpc/status"
+)
+
+type ConsumerLimitHandler struct {
+	pb.UnimplementedConsumerLimitServiceServer
+	config           config.Config
+	consumerLimitSvc service.ConsumerLimitServiceUseCase
+}
+
+func NewConsumerLimitHandler(config config.Config, consumerLimitSvc service.ConsumerLimitServiceUseCase) *ConsumerLimitHandler {
+	return &ConsumerLimitHandler{
+		config:           config,
+		consumerLimitSvc: consumerLimitSvc,
+	}
+}
+
+func (ch *ConsumerLimitHandler) GetConsumerLimitsByConsumerId(ctx context.Context, req *pb.ConsumerRequest) (*pb.ConsumerLimitListResponse, error) {
+	consumerLimitList, err := ch.consumerLimitSvc.FindByConsumerId(ctx, req.ConsumerId)
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [ConsumerLimitHandler - GetConsumerLimitsByConsumerId] Error while find consumer limits by consumer id:", parseError.Message)
+		return &pb.ConsumerLimitListResponse{
+			Code:    uint32(commonErr.HttpStatusFromCode(parseError.Code)),
+			Message: parseError.Message,
+		}, status.Errorf(parseError.Code, parseError.Message)
+	}
+
+	var consumerLimits []*pb.ConsumerLimit
+	for _, c := range consumerLimitList {
+		consumerLimits = append(consumerLimits, entity.ConvertEntityToProto(c))
+	}
+
+	return &pb.ConsumerLimitListResponse{
+		Code:    uint32(http.StatusOK),
+		Message: "Success get consumer limits by consumer id",
+		Data:    consumerLimits,
+	}, nil
+}
+
+func (ch *ConsumerLimitHandler) GetConsumerLimitByConsumerIdAndTenor(ctx context.Context, req *pb.ConsumerIdAndTenorRequest) (*pb.ConsumerLimitResponse, error) {
+	consumerLimit, err := ch.consumerLimitSvc.FindByConsumerIdAndTenor(ctx, req.ConsumerId, req.Tenor)
+	if err != nil {
+		return consumerLimitErrorResponse("GetConsumerLimitByConsumerIdAndTenor", "Error while find consumer limit by consumer id and tenor", err)
+	}
+
+	return &pb.ConsumerLimitResponse{
+		Code:    uint32(http.StatusOK),
+		Message: "Success get consumer limit by consumer id and tenor",
+		Data:    entity.ConvertEntityToProto(consumerLimit),
+	}, nil
+}
+
+func (ch *ConsumerLimitHandler) CreateConsumerLimit(ctx context.Context, req *pb.ConsumerLimit) (*pb.ConsumerLimitResponse, error) {
+	consumerLimit, err := ch.consumerLimitSvc.Create(ctx, req.ConsumerId, req.Tenor, req.LimitAmount)
+	if err != nil {
+		return consumerLimitErrorResponse("CreateConsumerLimit", "Error while create consumer limit", err)
+	}
+
+	return &pb.ConsumerLimitResponse{
+		Code:    uint32(http.StatusCreated),
+		Message: "Success create consumer limit",
+		Data:    entity.ConvertEntityToProto(consumerLimit),
+	}, nil
+}
+
+func (ch *ConsumerLimitHandler) UpdateAvailableLimit(ctx context.Context, req *pb.UpdateAvailableLimitRequest) (*pb.ConsumerLimitResponse, error) {
+	consumerLimit, err := ch.consumerLimitSvc.Debit(ctx, req.ConsumerId, req.Tenor, req.AmountTransaction, req.Reference)
+	if err != nil {
+		return consumerLimitErrorResponse("UpdateAvailableLimit", "Error while update available limit", err)
+	}
+
+	return &pb.ConsumerLimitResponse{
+		Code:    uint32(http.StatusOK),
+		Message: "Success update available limit",
+		Data:    entity.ConvertEntityToProto(consumerLimit),
+	}, nil
+}
+
+func (ch *ConsumerLimitHandler) RestoreAvailableLimit(ctx context.Context, req *pb.UpdateAvailableLimitRequest) (*pb.ConsumerLimitResponse, error) {
+	consumerLimit, err := ch.consumerLimitSvc.Restore(ctx, req.ConsumerId, req.Tenor, req.AmountTransaction, req.Reference)
+	if err != nil {
+		return consumerLimitErrorResponse("RestoreAvailableLimit", "Error while restore available limit", err)
+	}
+
+	return &pb.ConsumerLimitResponse{
+		Code:    uint32(http.StatusOK),
+		Message: "Success restore available limit",
+		Data:    entity.ConvertEntityToProto(consumerLimit),
+	}, nil
+}
+
+func consumerLimitErrorResponse(method string, message string, err error) (*pb.ConsumerLimitResponse, error) {
+	parseError := commonErr.ParseError(err)
+	log.Printf("ERROR: [ConsumerLimitHandler - %s] %s: %s\n", method, message, parseError.Message)
+	return &pb.ConsumerLimitResponse{
+		Code:    uint32(commonErr.HttpStatusFromCode(parseError.Code)),
+		Message: parseError.Message,
+	}, status.Errorf(parseError.Code, parseError.Message)
+}
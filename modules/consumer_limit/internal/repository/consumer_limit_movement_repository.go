@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	gormConn "xyz-transaction-service/common/gorm"
+	"xyz-transaction-service/common/tracing"
+	"xyz-transaction-service/modules/consumer_limit/entity"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+type ConsumerLimitMovementRepository struct {
+	db *gorm.DB
+}
+
+func NewConsumerLimitMovementRepository(db *gorm.DB) *ConsumerLimitMovementRepository {
+	return &ConsumerLimitMovementRepository{
+		db: db,
+	}
+}
+
+type ConsumerLimitMovementRepositoryUseCase interface {
+	FindByReference(ctx context.Context, reference string, movementType string) (*entity.ConsumerLimitMovement, error)
+	Create(ctx context.Context, req *entity.ConsumerLimitMovement) (*entity.ConsumerLimitMovement, error)
+}
+
+func (m *ConsumerLimitMovementRepository) FindByReference(ctx context.Context, reference string, movementType string) (*entity.ConsumerLimitMovement, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "ConsumerLimitMovementRepository - FindByReference")
+	defer span.End()
+
+	var movement entity.ConsumerLimitMovement
+	if err := gormConn.Conn(ctx, m.db).Debug().WithContext(ctxSpan).Where("reference = ? AND type = ?", reference, movementType).First(&movement).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "Consumer limit movement not found for reference: %v", reference)
+		}
+		log.Println("ERROR: [ConsumerLimitMovementRepository - FindByReference] Internal server error:", err)
+		return nil, err
+	}
+
+	return &movement, nil
+}
+
+func (m *ConsumerLimitMovementRepository) Create(ctx context.Context, req *entity.ConsumerLimitMovement) (*entity.ConsumerLimitMovement, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "ConsumerLimitMovementRepository - Create")
+	defer span.End()
+
+	if err := gormConn.Conn(ctx, m.db).Debug().WithContext(ctxSpan).Create(req).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			log.Printf("WARNING: [ConsumerLimitMovementRepository - Create] %s already recorded for reference: %v\n", req.Type, req.Reference)
+			return nil, status.Errorf(codes.AlreadyExists, "%s already recorded for reference: %v", req.Type, req.Reference)
+		}
+		log.Println("ERROR: [ConsumerLimitMovementRepository - Create] Internal server error:", err)
+		return nil, err
+	}
+
+	return req, nil
+}
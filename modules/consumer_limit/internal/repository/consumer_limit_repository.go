@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	gormConn "xyz-transaction-service/common/gorm"
+	"xyz-transaction-service/common/tracing"
+	"xyz-transaction-service/modules/consumer_limit/entity"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ConsumerLimitRepository struct {
+	db *gorm.DB
+}
+
+func NewConsumerLimitRepository(db *gorm.DB) *ConsumerLimitRepository {
+	return &ConsumerLimitRepository{
+		db: db,
+	}
+}
+
+type ConsumerLimitRepositoryUseCase interface {
+	FindByConsumerId(ctx context.Context, consumerId uint64) ([]*entity.ConsumerLimit, error)
+	FindByConsumerIdAndTenor(ctx context.Context, consumerId uint64, tenor uint32) (*entity.ConsumerLimit, error)
+	FindByConsumerIdAndTenorForUpdate(ctx context.Context, consumerId uint64, tenor uint32) (*entity.ConsumerLimit, error)
+	Create(ctx context.Context, req *entity.ConsumerLimit) (*entity.ConsumerLimit, error)
+	UpdateLimitAvailable(ctx context.Context, id uint64, limitAvailable uint64) error
+}
+
+func (c *ConsumerLimitRepository) FindByConsumerId(ctx context.Context, consumerId uint64) ([]*entity.ConsumerLimit, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "ConsumerLimitRepository - FindByConsumerId")
+	defer span.End()
+
+	var consumerLimits []*entity.ConsumerLimit
+	if err := gormConn.Conn(ctx, c.db).Debug().WithContext(ctxSpan).Where("consumer_id = ?", consumerId).Order("tenor asc").Find(&consumerLimits).Error; err != nil {
+		log.Println("ERROR: [ConsumerLimitRepository - FindByConsumerId] Internal server error:", err)
+		return nil, err
+	}
+
+	return consumerLimits, nil
+}
+
+func (c *ConsumerLimitRepository) FindByConsumerIdAndTenor(ctx context.Context, consumerId uint64, tenor uint32) (*entity.ConsumerLimit, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "ConsumerLimitRepository - FindByConsumerIdAndTenor")
+	defer span.End()
+
+	return c.findByConsumerIdAndTenor(gormConn.Conn(ctx, c.db).Debug().WithContext(ctxSpan), "FindByConsumerIdAndTenor", consumerId, tenor)
+}
+
+// FindByConsumerIdAndTenorForUpdate locks the limit row until the surrounding
+// transaction ends, so concurrent debits and restores are applied one after
+// another.
+func (c *ConsumerLimitRepository) FindByConsumerIdAndTenorForUpdate(ctx context.Context, consumerId uint64, tenor uint32) (*entity.ConsumerLimit, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "ConsumerLimitRepository - FindByConsumerIdAndTenorForUpdate")
+	defer span.End()
+
+	return c.findByConsumerIdAndTenor(gormConn.Conn(ctx, c.db).Debug().WithContext(ctxSpan).Clauses(clause.Locking{Strength: "UPDATE"}), "FindByConsumerIdAndTenorForUpdate", consumerId, tenor)
+}
+
+func (c *ConsumerLimitRepository) findByConsumerIdAndTenor(query *gorm.DB, method string, consumerId uint64, tenor uint32) (*entity.ConsumerLimit, error) {
+	var consumerLimit entity.ConsumerLimit
+	if err := query.Where("consumer_id = ? AND tenor = ?", consumerId, tenor).First(&consumerLimit).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("WARNING: [ConsumerLimitRepository - %s] Consumer limit not found for consumer id: %v, tenor: %v\n", method, consumerId, tenor)
+			return nil, status.Errorf(codes.NotFound, "Consumer limit not found for consumer id: %v, tenor: %v", consumerId, tenor)
+		}
+		log.Printf("ERROR: [ConsumerLimitRepository - %s] Internal server error: %v\n", method, err)
+		return nil, err
+	}
+
+	return &consumerLimit, nil
+}
+
+func (c *ConsumerLimitRepository) Create(ctx context.Context, req *entity.ConsumerLimit) (*entity.ConsumerLimit, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "ConsumerLimitRepository - Create")
+	defer span.End()
+
+	if err := gormConn.Conn(ctx, c.db).Debug().WithContext(ctxSpan).Create(req).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			log.Printf("WARNING: [ConsumerLimitRepository - Create] Consumer limit already exists for consumer id: %v, tenor: %v\n", req.ConsumerId, req.Tenor)
+			return nil, status.Errorf(codes.AlreadyExists, "Consumer limit already exists for consumer id: %v, tenor: %v", req.ConsumerId, req.Tenor)
+		}
+		log.Println("ERROR: [ConsumerLimitRepository - Create] Internal server error:", err)
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (c *ConsumerLimitRepository) UpdateLimitAvailable(ctx context.Context, id uint64, limitAvailable uint64) error {
+	ctxSpan, span := tracing.StartSpan(ctx, "ConsumerLimitRepository - UpdateLimitAvailable")
+	defer span.End()
+
+	if err := gormConn.Conn(ctx, c.db).Debug().WithContext(ctxSpan).Model(&entity.ConsumerLimit{}).Where("id = ?", id).
+		Updates(map[string]any{"limit_available": limitAvailable, "updated_at": time.Now()}).Error; err != nil {
+		log.Println("ERROR: [ConsumerLimitRepository - UpdateLimitAvailable] Internal server error:", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+	"xyz-transaction-service/common/config"
+	commonErr "xyz-transaction-service/common/error"
+	gormConn "xyz-transaction-service/common/gorm"
+	"xyz-transaction-service/common/tracing"
+	"xyz-transaction-service/modules/consumer_limit/entity"
+	"xyz-transaction-service/modules/consumer_limit/internal/repository"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type ConsumerLimitService struct {
+	cfg                     config.Config
+	transactor              gormConn.Transactor
+	consumerLimitRepository repository.ConsumerLimitRepositoryUseCase
+	movementRepository      repository.ConsumerLimitMovementRepositoryUseCase
+}
+
+func NewConsumerLimitService(cfg config.Config, transactor gormConn.Transactor, consumerLimitRepository repository.ConsumerLimitRepositoryUseCase, movementRepository repository.ConsumerLimitMovementRepositoryUseCase) *ConsumerLimitService {
+	return &ConsumerLimitService{
+		cfg:                     cfg,
+		transactor:              transactor,
+		consumerLimitRepository: consumerLimitRepository,
+		movementRepository:      movementRepository,
+	}
+}
+
+type ConsumerLimitServiceUseCase interface {
+	FindByConsumerId(ctx context.Context, consumerId uint64) ([]*entity.ConsumerLimit, error)
+	FindByConsumerIdAndTenor(ctx context.Context, consumerId uint64, tenor uint32) (*entity.ConsumerLimit, error)
+	Create(ctx context.Context, consumerId uint64, tenor uint32, limitAmount uint64) (*entity.ConsumerLimit, error)
+	Debit(ctx context.Context, consumerId uint64, tenor uint32, amount uint64, reference string) (*entity.ConsumerLimit, error)
+	Restore(ctx context.Context, consumerId uint64, tenor uint32, amount uint64, reference string) (*entity.ConsumerLimit, error)
+}
+
+func (svc *ConsumerLimitService) FindByConsumerId(ctx context.Context, consumerId uint64) ([]*entity.ConsumerLimit, error) {
+	ctx, span := tracing.StartSpan(ctx, "ConsumerLimitService - FindByConsumerId")
+	defer span.End()
+
+	consumerLimits, err := svc.consumerLimitRepository.FindByConsumerId(ctx, consumerId)
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [ConsumerLimitService - FindByConsumerId] Error while find consumer limits by consumer id:", parseError.Message)
+		return nil, err
+	}
+
+	return consumerLimits, nil
+}
+
+func (svc *ConsumerLimitService) FindByConsumerIdAndTenor(ctx context.Context, consumerId uint64, tenor uint32) (*entity.ConsumerLimit, error) {
+	ctx, span := tracing.StartSpan(ctx, "ConsumerLimitService - FindByConsumerIdAndTenor")
+	defer span.End()
+
+	consumerLimit, err := svc.consumerLimitRepository.FindByConsumerIdAndTenor(ctx, consumerId, tenor)
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [ConsumerLimitService - FindByConsumerIdAndTenor] Error while find consumer limit by consumer id and tenor:", parseError.Message)
+		return nil, err
+	}
+
+	return consumerLimit, nil
+}
+
+func (svc *ConsumerLimitService) Create(ctx context.Context, consumerId uint64, tenor uint32, limitAmount uint64) (*entity.ConsumerLimit, error) {
+	ctx, span := tracing.StartSpan(ctx, "ConsumerLimitService - Create")
+	defer span.End()
+
+	if consumerId == 0 || tenor == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "consumer id and tenor are required")
+	}
+	if limitAmount == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "limit amount must be greater than zero")
+	}
+
+	consumerLimit, err := svc.consumerLimitRepository.Create(ctx, entity.NewConsumerLimitEntity(consumerId, tenor, limitAmount))
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [ConsumerLimitService - Create] Error while create consumer limit:", parseError.Message)
+		return nil, err
+	}
+
+	return consumerLimit, nil
+}
+
+// Debit takes amount off the available limit once per reference. A replay of
+// an applied debit returns the limit unchanged, and a debit arriving after its
+// reference was restored is rejected, so a late retry cannot consume a limit
+// the caller has already given back.
+func (svc *ConsumerLimitService) Debit(ctx context.Context, consumerId uint64, tenor uint32, amount uint64, reference string) (*entity.ConsumerLimit, error) {
+	ctx, span := tracing.StartSpan(ctx, "ConsumerLimitService - Debit")
+	defer span.End()
+
+	if amount == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "amount must be greater than zero")
+	}
+	if reference == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "reference is required")
+	}
+
+	var consumerLimit *entity.ConsumerLimit
+	err := svc.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		consumerLimit, err = svc.consumerLimitRepository.FindByConsumerIdAndTenorForUpdate(ctx, consumerId, tenor)
+		if err != nil {
+			return err
+		}
+
+		debit, err := svc.findMovement(ctx, reference, entity.MovementTypeDebit)
+		if err != nil {
+			return err
+		}
+		if debit != nil {
+			if debit.ConsumerLimitId != consumerLimit.Id || debit.Amount != amount {
+				log.Println("WARNING: [ConsumerLimitService - Debit] Reference reused with a different payload:", reference)
+				return status.Errorf(codes.AlreadyExists, "reference %s is already used by another debit", reference)
+			}
+			log.Println("INFO: [ConsumerLimitService - Debit] Duplicate debit ignored for reference:", reference)
+			return nil
+		}
+
+		restore, err := svc.findMovement(ctx, reference, entity.MovementTypeRestore)
+		if err != nil {
+			return err
+		}
+		if restore != nil {
+			log.Println("WARNING: [ConsumerLimitService - Debit] Debit rejected for restored reference:", reference)
+			return status.Errorf(codes.FailedPrecondition, "reference %s was already restored", reference)
+		}
+
+		if consumerLimit.LimitAvailable < amount {
+			log.Printf("WARNING: [ConsumerLimitService - Debit] Insufficient limit for consumer id: %v, tenor: %v\n", consumerId, tenor)
+			return status.Errorf(codes.FailedPrecondition, "insufficient limit: available %d, requested %d", consumerLimit.LimitAvailable, amount)
+		}
+
+		return svc.applyMovement(ctx, consumerLimit, consumerLimit.LimitAvailable-amount, entity.NewConsumerLimitMovementEntity(consumerLimit.Id, reference, entity.MovementTypeDebit, amount))
+	})
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [ConsumerLimitService - Debit] Error while debit consumer limit:", parseError.Message)
+		return nil, err
+	}
+
+	return consumerLimit, nil
+}
+
+// Restore gives back the amount debited under reference, capped at the limit
+// amount. Restoring a reference twice, or one that was never debited, leaves
+// the limit unchanged; the latter is still recorded so that the debit is
+// refused if it arrives afterwards.
+func (svc *ConsumerLimitService) Restore(ctx context.Context, consumerId uint64, tenor uint32, amount uint64, reference string) (*entity.ConsumerLimit, error) {
+	ctx, span := tracing.StartSpan(ctx, "ConsumerLimitService - Restore")
+	defer span.End()
+
+	if reference == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "reference is required")
+	}
+
+	var consumerLimit *entity.ConsumerLimit
+	err := svc.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		consumerLimit, err = svc.consumerLimitRepository.FindByConsumerIdAndTenorForUpdate(ctx, consumerId, tenor)
+		if err != nil {
+			return err
+		}
+
+		restore, err := svc.findMovement(ctx, reference, entity.MovementTypeRestore)
+		if err != nil {
+			return err
+		}
+		if restore != nil {
+			log.Println("INFO: [ConsumerLimitService - Restore] Duplicate restore ignored for reference:", reference)
+			return nil
+		}
+
+		debit, err := svc.findMovement(ctx, reference, entity.MovementTypeDebit)
+		if err != nil {
+			return err
+		}
+		if debit == nil {
+			log.Println("INFO: [ConsumerLimitService - Restore] Nothing debited for reference:", reference)
+			_, err := svc.movementRepository.Create(ctx, entity.NewConsumerLimitMovementEntity(consumerLimit.Id, reference, entity.MovementTypeRestore, 0))
+			return err
+		}
+		if debit.ConsumerLimitId != consumerLimit.Id {
+			log.Println("WARNING: [ConsumerLimitService - Restore] Reference debited on another limit:", reference)
+			return status.Errorf(codes.FailedPrecondition, "reference %s was debited on another limit", reference)
+		}
+		if debit.Amount != amount {
+			log.Printf("WARNING: [ConsumerLimitService - Restore] Restoring the debited amount %d instead of %d for reference: %v\n", debit.Amount, amount, reference)
+		}
+
+		limitAvailable := consumerLimit.LimitAvailable + debit.Amount
+		if limitAvailable > consumerLimit.LimitAmount {
+			limitAvailable = consumerLimit.LimitAmount
+		}
+
+		return svc.applyMovement(ctx, consumerLimit, limitAvailable, entity.NewConsumerLimitMovementEntity(consumerLimit.Id, reference, entity.MovementTypeRestore, debit.Amount))
+	})
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [ConsumerLimitService - Restore] Error while restore consumer limit:", parseError.Message)
+		return nil, err
+	}
+
+	return consumerLimit, nil
+}
+
+func (svc *ConsumerLimitService) applyMovement(ctx context.Context, consumerLimit *entity.ConsumerLimit, limitAvailable uint64, movement *entity.ConsumerLimitMovement) error {
+	if _, err := svc.movementRepository.Create(ctx, movement); err != nil {
+		return err
+	}
+	if err := svc.consumerLimitRepository.UpdateLimitAvailable(ctx, consumerLimit.Id, limitAvailable); err != nil {
+		return err
+	}
+
+	consumerLimit.LimitAvailable = limitAvailable
+	consumerLimit.UpdatedAt = time.Now()
+	return nil
+}
+
+func (svc *ConsumerLimitService) findMovement(ctx context.Context, reference string, movementType string) (*entity.ConsumerLimitMovement, error) {
+	movement, err := svc.movementRepository.FindByReference(ctx, reference, movementType)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return movement, nil
+}
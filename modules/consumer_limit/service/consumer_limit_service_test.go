@@ -0,0 +1,169 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"xyz-transaction-service/common/config"
+	"xyz-transaction-service/modules/consumer_limit/entity"
+	"xyz-transaction-service/modules/consumer_limit/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Mock for ConsumerLimitRepositoryUseCase
+type MockConsumerLimitRepository struct {
+	mock.Mock
+}
+
+func (m *MockConsumerLimitRepository) FindByConsumerId(ctx context.Context, consumerId uint64) ([]*entity.ConsumerLimit, error) {
+	args := m.Called(ctx, consumerId)
+	return args.Get(0).([]*entity.ConsumerLimit), args.Error(1)
+}
+
+func (m *MockConsumerLimitRepository) FindByConsumerIdAndTenor(ctx context.Context, consumerId uint64, tenor uint32) (*entity.ConsumerLimit, error) {
+	args := m.Called(ctx, consumerId, tenor)
+	consumerLimit, _ := args.Get(0).(*entity.ConsumerLimit)
+	return consumerLimit, args.Error(1)
+}
+
+func (m *MockConsumerLimitRepository) FindByConsumerIdAndTenorForUpdate(ctx context.Context, consumerId uint64, tenor uint32) (*entity.ConsumerLimit, error) {
+	args := m.Called(ctx, consumerId, tenor)
+	consumerLimit, _ := args.Get(0).(*entity.ConsumerLimit)
+	return consumerLimit, args.Error(1)
+}
+
+func (m *MockConsumerLimitRepository) Create(ctx context.Context, req *entity.ConsumerLimit) (*entity.ConsumerLimit, error) {
+	args := m.Called(ctx, req)
+	consumerLimit, _ := args.Get(0).(*entity.ConsumerLimit)
+	return consumerLimit, args.Error(1)
+}
+
+func (m *MockConsumerLimitRepository) UpdateLimitAvailable(ctx context.Context, id uint64, limitAvailable uint64) error {
+	args := m.Called(ctx, id, limitAvailable)
+	return args.Error(0)
+}
+
+// fakeMovementRepository keeps movements in memory, keyed like the unique
+// index on (reference, type)
+type fakeMovementRepository struct {
+	movements map[string]*entity.ConsumerLimitMovement
+}
+
+func newFakeMovementRepository() *fakeMovementRepository {
+	return &fakeMovementRepository{movements: make(map[string]*entity.ConsumerLimitMovement)}
+}
+
+func (f *fakeMovementRepository) FindByReference(_ context.Context, reference string, movementType string) (*entity.ConsumerLimitMovement, error) {
+	movement, ok := f.movements[reference+"/"+movementType]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "not found")
+	}
+	return movement, nil
+}
+
+func (f *fakeMovementRepository) Create(_ context.Context, req *entity.ConsumerLimitMovement) (*entity.ConsumerLimitMovement, error) {
+	key := req.Reference + "/" + req.Type
+	if _, ok := f.movements[key]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "already exists")
+	}
+	f.movements[key] = req
+	return req, nil
+}
+
+// MockTransactor runs the callback without opening a database transaction
+type MockTransactor struct{}
+
+func (MockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func newConsumerLimitService(repo *MockConsumerLimitRepository, movements *fakeMovementRepository) *service.ConsumerLimitService {
+	return service.NewConsumerLimitService(config.Config{}, MockTransactor{}, repo, movements)
+}
+
+func TestDebitIsAppliedOncePerReference(t *testing.T) {
+	mockRepo := new(MockConsumerLimitRepository)
+	movements := newFakeMovementRepository()
+
+	limit := &entity.ConsumerLimit{Id: 1, ConsumerId: 10, Tenor: 3, LimitAmount: 1000, LimitAvailable: 1000}
+	mockRepo.On("FindByConsumerIdAndTenorForUpdate", mock.Anything, uint64(10), uint32(3)).Return(limit, nil)
+	mockRepo.On("UpdateLimitAvailable", mock.Anything, uint64(1), uint64(600)).Return(nil).Once()
+
+	svc := newConsumerLimitService(mockRepo, movements)
+
+	res, err := svc.Debit(context.Background(), 10, 3, 400, "CN-1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(600), res.LimitAvailable)
+
+	res, err = svc.Debit(context.Background(), 10, 3, 400, "CN-1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(600), res.LimitAvailable)
+
+	// the same reference with another amount is not a replay
+	_, err = svc.Debit(context.Background(), 10, 3, 500, "CN-1")
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDebitRejectsInsufficientLimit(t *testing.T) {
+	mockRepo := new(MockConsumerLimitRepository)
+
+	mockRepo.On("FindByConsumerIdAndTenorForUpdate", mock.Anything, uint64(10), uint32(3)).Return(&entity.ConsumerLimit{Id: 1, LimitAmount: 1000, LimitAvailable: 300}, nil)
+
+	svc := newConsumerLimitService(mockRepo, newFakeMovementRepository())
+
+	_, err := svc.Debit(context.Background(), 10, 3, 400, "CN-1")
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = svc.Debit(context.Background(), 10, 3, 400, "")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	mockRepo.AssertNotCalled(t, "UpdateLimitAvailable", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRestoreGivesBackTheDebitOnce(t *testing.T) {
+	mockRepo := new(MockConsumerLimitRepository)
+	movements := newFakeMovementRepository()
+
+	limit := &entity.ConsumerLimit{Id: 1, ConsumerId: 10, Tenor: 3, LimitAmount: 1000, LimitAvailable: 1000}
+	mockRepo.On("FindByConsumerIdAndTenorForUpdate", mock.Anything, uint64(10), uint32(3)).Return(limit, nil)
+	mockRepo.On("UpdateLimitAvailable", mock.Anything, uint64(1), uint64(600)).Return(nil).Once()
+	mockRepo.On("UpdateLimitAvailable", mock.Anything, uint64(1), uint64(1000)).Return(nil).Once()
+
+	svc := newConsumerLimitService(mockRepo, movements)
+
+	_, err := svc.Debit(context.Background(), 10, 3, 400, "CN-1")
+	assert.NoError(t, err)
+
+	res, err := svc.Restore(context.Background(), 10, 3, 400, "CN-1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1000), res.LimitAvailable)
+
+	res, err = svc.Restore(context.Background(), 10, 3, 400, "CN-1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1000), res.LimitAvailable)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRestoreBeforeDebitBlocksTheLateDebit(t *testing.T) {
+	mockRepo := new(MockConsumerLimitRepository)
+	movements := newFakeMovementRepository()
+
+	mockRepo.On("FindByConsumerIdAndTenorForUpdate", mock.Anything, uint64(10), uint32(3)).Return(&entity.ConsumerLimit{Id: 1, LimitAmount: 1000, LimitAvailable: 1000}, nil)
+
+	svc := newConsumerLimitService(mockRepo, movements)
+
+	res, err := svc.Restore(context.Background(), 10, 3, 400, "CN-1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1000), res.LimitAvailable)
+
+	_, err = svc.Debit(context.Background(), 10, 3, 400, "CN-1")
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	mockRepo.AssertNotCalled(t, "UpdateLimitAvailable", mock.Anything, mock.Anything, mock.Anything)
+}
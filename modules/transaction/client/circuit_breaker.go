@@ -0,0 +1,98 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	breakerClosed   = "closed"
+	breakerOpen     = "open"
+	breakerHalfOpen = "half_open"
+)
+
+// CircuitBreaker stops calling a failing dependency. After failureThreshold
+// consecutive failures it opens and rejects calls for openTimeout, then lets a
+// single trial call through: success closes it again, failure reopens it.
+type CircuitBreaker struct {
+	failureThreshold uint32
+	openTimeout      time.Duration
+	now              func() time.Time
+
+	mu       sync.Mutex
+	state    string
+	failures uint32
+	openedAt time.Time
+	trial    bool
+}
+
+func NewCircuitBreaker(failureThreshold uint32, openTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		now:              time.Now,
+		state:            breakerClosed,
+	}
+}
+
+// Allow reports whether a call may go out now. Every allowed call must be
+// followed by Success or Failure.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.state = breakerHalfOpen
+		b.trial = true
+		return true
+	case breakerHalfOpen:
+		// only the trial call is in flight
+		if b.trial {
+			return false
+		}
+		b.trial = true
+		return true
+	}
+
+	return true
+}
+
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = breakerClosed
+	b.failures = 0
+	b.trial = false
+}
+
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.failureThreshold {
+		b.state = breakerOpen
+		b.openedAt = b.now()
+	}
+	b.trial = false
+}
+
+// Ignore ends an allowed call without counting it either way.
+func (b *CircuitBreaker) Ignore() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+}
+
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
@@ -2,39 +2,219 @@ package client
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"time"
+	"xyz-transaction-service/common/config"
+	"xyz-transaction-service/common/metrics"
 	"xyz-transaction-service/pb"
 	"xyz-transaction-service/server"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
 )
 
+// ConsumerLimitServiceClient calls the consumer limit service with a deadline
+// per attempt and a circuit breaker shared by every call. Only the idempotent
+// read is retried; the limit updates are left to the saga, which replays them
+// with the same reference.
 type ConsumerLimitServiceClient struct {
-	Client pb.ConsumerLimitServiceClient
+	Client     pb.ConsumerLimitServiceClient
+	conn       *grpc.ClientConn
+	resilience config.ClientResilience
+	breaker    *CircuitBreaker
 }
 
-func BuildConsumerLimitServiceClient(url string) ConsumerLimitServiceClient {
-	cc := server.InitGRPCConn(url, false, "")
+// ErrCircuitOpen rejects a call while the circuit breaker is open. It is
+// Unavailable to callers but never retried, the breaker would reject every
+// attempt until it half-opens.
+var ErrCircuitOpen = status.Error(codes.Unavailable, "consumer limit service circuit breaker is open")
+
+func BuildConsumerLimitServiceClient(cfg config.Config) ConsumerLimitServiceClient {
+	var opts []server.DialOption
+	if cfg.ClientTLS.ConsumerSSL && cfg.ClientTLS.ConsumerCertFile != "" {
+		// mTLS, presenting our certificate on top of the CA of the server
+		opts = append(opts, server.WithTLS(cfg.ClientTLS.ConsumerCAFile, cfg.ClientTLS.ConsumerCertFile, cfg.ClientTLS.ConsumerKeyFile))
+	}
+	cc := server.InitGRPCConn(cfg.ClientURL.Consumer, cfg.ClientTLS.ConsumerSSL, cfg.ClientTLS.ConsumerCAFile, opts...)
+
+	c := NewConsumerLimitServiceClient(pb.NewConsumerLimitServiceClient(cc), cfg.ClientResilience)
+	c.conn = cc
+
+	return c
+}
 
+func NewConsumerLimitServiceClient(client pb.ConsumerLimitServiceClient, resilience config.ClientResilience) ConsumerLimitServiceClient {
 	c := ConsumerLimitServiceClient{
-		Client: pb.NewConsumerLimitServiceClient(cc),
+		Client:     client,
+		resilience: resilience,
+	}
+	if resilience.ConsumerBreakerFailures > 0 {
+		c.breaker = NewCircuitBreaker(resilience.ConsumerBreakerFailures, resilience.ConsumerBreakerOpenTimeout)
 	}
 
 	return c
 }
 
+// CheckConnection waits until the connection to the consumer limit service
+// is ready, dialing it if idle, and fails when ctx is done first.
+func (cla *ConsumerLimitServiceClient) CheckConnection(ctx context.Context) error {
+	if cla.conn == nil {
+		return errors.New("consumer limit service is not connected")
+	}
+
+	cla.conn.Connect()
+	for {
+		state := cla.conn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		if !cla.conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("consumer limit service connection is %s", state)
+		}
+	}
+}
+
 func (cla *ConsumerLimitServiceClient) GetConsumerLimitByConsumerIdAndTenor(ctx context.Context, consumerId uint64, tenor uint32) (*pb.ConsumerLimitResponse, error) {
 	req := &pb.ConsumerIdAndTenorRequest{
 		ConsumerId: consumerId,
 		Tenor:      tenor,
 	}
 
-	return cla.Client.GetConsumerLimitByConsumerIdAndTenor(ctx, req)
+	return cla.invoke(ctx, "GetConsumerLimitByConsumerIdAndTenor", true, func(ctx context.Context) (*pb.ConsumerLimitResponse, error) {
+		return cla.Client.GetConsumerLimitByConsumerIdAndTenor(ctx, req)
+	})
 }
 
-func (cla *ConsumerLimitServiceClient) UpdateAvailableLimit(ctx context.Context, consumerId uint64, tenor uint32, amountTransaction uint64) (*pb.ConsumerLimitResponse, error) {
+func (cla *ConsumerLimitServiceClient) UpdateAvailableLimit(ctx context.Context, consumerId uint64, tenor uint32, amountTransaction uint64, reference string) (*pb.ConsumerLimitResponse, error) {
 	req := &pb.UpdateAvailableLimitRequest{
 		ConsumerId:        consumerId,
 		Tenor:             tenor,
 		AmountTransaction: amountTransaction,
+		Reference:         reference,
 	}
 
-	return cla.Client.UpdateAvailableLimit(ctx, req)
+	return cla.invoke(ctx, "UpdateAvailableLimit", false, func(ctx context.Context) (*pb.ConsumerLimitResponse, error) {
+		return cla.Client.UpdateAvailableLimit(ctx, req)
+	})
+}
+
+func (cla *ConsumerLimitServiceClient) RestoreAvailableLimit(ctx context.Context, consumerId uint64, tenor uint32, amountTransaction uint64, reference string) (*pb.ConsumerLimitResponse, error) {
+	req := &pb.UpdateAvailableLimitRequest{
+		ConsumerId:        consumerId,
+		Tenor:             tenor,
+		AmountTransaction: amountTransaction,
+		Reference:         reference,
+	}
+
+	return cla.invoke(ctx, "RestoreAvailableLimit", false, func(ctx context.Context) (*pb.ConsumerLimitResponse, error) {
+		return cla.Client.RestoreAvailableLimit(ctx, req)
+	})
+}
+
+func (cla *ConsumerLimitServiceClient) invoke(ctx context.Context, method string, retry bool, call func(ctx context.Context) (*pb.ConsumerLimitResponse, error)) (*pb.ConsumerLimitResponse, error) {
+	attempts := 1
+	if retry {
+		attempts += cla.resilience.ConsumerMaxRetries
+	}
+
+	var res *pb.ConsumerLimitResponse
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if waitErr := sleepContext(ctx, cla.backoff(attempt)); waitErr != nil {
+				return nil, err
+			}
+			log.Printf("WARNING: [ConsumerLimitServiceClient - %s] Retrying after: %v\n", method, err)
+		}
+
+		res, err = cla.attempt(ctx, method, call)
+		if err == nil || !isRetryable(ctx, err) {
+			return res, err
+		}
+	}
+
+	return res, err
+}
+
+func (cla *ConsumerLimitServiceClient) attempt(ctx context.Context, method string, call func(ctx context.Context) (*pb.ConsumerLimitResponse, error)) (*pb.ConsumerLimitResponse, error) {
+	if cla.breaker != nil && !cla.breaker.Allow() {
+		metrics.ObserveConsumerLimitCall(method, time.Now(), ErrCircuitOpen)
+		return nil, ErrCircuitOpen
+	}
+
+	callCtx := ctx
+	if cla.resilience.ConsumerTimeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, cla.resilience.ConsumerTimeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	res, err := call(callCtx)
+	metrics.ObserveConsumerLimitCall(method, start, err)
+
+	if cla.breaker != nil {
+		switch {
+		case ctx.Err() != nil:
+			// the caller gave up, which says nothing about the dependency
+			cla.breaker.Ignore()
+		case isDependencyFailure(err):
+			cla.breaker.Failure()
+		default:
+			cla.breaker.Success()
+		}
+	}
+
+	return res, err
+}
+
+// backoff is a full jitter exponential delay before the given retry.
+func (cla *ConsumerLimitServiceClient) backoff(attempt int) time.Duration {
+	delay := cla.resilience.ConsumerRetryBackoff << (attempt - 1)
+	if maxDelay := cla.resilience.ConsumerRetryMaxBackoff; maxDelay > 0 && (delay > maxDelay || delay <= 0) {
+		delay = maxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int64N(int64(delay) + 1))
+}
+
+// isDependencyFailure tells errors of the limit service itself apart from
+// business answers such as NotFound or InvalidArgument.
+func isDependencyFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal, codes.Unknown:
+		return true
+	}
+	return false
+}
+
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
@@ -0,0 +1,152 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"xyz-transaction-service/common/config"
+	"xyz-transaction-service/modules/transaction/client"
+	"xyz-transaction-service/pb"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeConsumerLimitClient struct {
+	pb.ConsumerLimitServiceClient
+	errs  []error
+	calls int
+	delay time.Duration
+}
+
+func (f *fakeConsumerLimitClient) next(ctx context.Context) (*pb.ConsumerLimitResponse, error) {
+	f.calls++
+	if f.delay > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		case <-time.After(f.delay):
+		}
+	}
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &pb.ConsumerLimitResponse{Data: &pb.ConsumerLimit{LimitAvailable: 1000}}, nil
+}
+
+func (f *fakeConsumerLimitClient) GetConsumerLimitByConsumerIdAndTenor(ctx context.Context, in *pb.ConsumerIdAndTenorRequest, opts ...grpc.CallOption) (*pb.ConsumerLimitResponse, error) {
+	return f.next(ctx)
+}
+
+func (f *fakeConsumerLimitClient) UpdateAvailableLimit(ctx context.Context, in *pb.UpdateAvailableLimitRequest, opts ...grpc.CallOption) (*pb.ConsumerLimitResponse, error) {
+	return f.next(ctx)
+}
+
+var testResilience = config.ClientResilience{
+	ConsumerTimeout:            50 * time.Millisecond,
+	ConsumerMaxRetries:         2,
+	ConsumerRetryBackoff:       time.Millisecond,
+	ConsumerRetryMaxBackoff:    5 * time.Millisecond,
+	ConsumerBreakerFailures:    3,
+	ConsumerBreakerOpenTimeout: time.Hour,
+}
+
+func TestConsumerLimitClientRetriesReads(t *testing.T) {
+	fake := &fakeConsumerLimitClient{errs: []error{status.Error(codes.Unavailable, "connection refused"), nil}}
+	c := client.NewConsumerLimitServiceClient(fake, testResilience)
+
+	res, err := c.GetConsumerLimitByConsumerIdAndTenor(context.Background(), 1, 6)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1000), res.Data.LimitAvailable)
+	assert.Equal(t, 2, fake.calls)
+
+	// business errors are returned as is
+	fake = &fakeConsumerLimitClient{errs: []error{status.Error(codes.NotFound, "consumer limit not found")}}
+	c = client.NewConsumerLimitServiceClient(fake, testResilience)
+	_, err = c.GetConsumerLimitByConsumerIdAndTenor(context.Background(), 1, 6)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, 1, fake.calls)
+}
+
+func TestConsumerLimitClientDoesNotRetryWrites(t *testing.T) {
+	fake := &fakeConsumerLimitClient{errs: []error{status.Error(codes.Unavailable, "connection refused"), nil}}
+	c := client.NewConsumerLimitServiceClient(fake, testResilience)
+
+	_, err := c.UpdateAvailableLimit(context.Background(), 1, 6, 500, "saga-ref")
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 1, fake.calls)
+}
+
+func TestConsumerLimitClientDeadline(t *testing.T) {
+	fake := &fakeConsumerLimitClient{delay: time.Second}
+	c := client.NewConsumerLimitServiceClient(fake, testResilience)
+
+	start := time.Now()
+	_, err := c.UpdateAvailableLimit(context.Background(), 1, 6, 500, "saga-ref")
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestConsumerLimitClientCircuitBreaker(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+	fake := &fakeConsumerLimitClient{errs: []error{unavailable, unavailable, unavailable}}
+	resilience := testResilience
+	resilience.ConsumerMaxRetries = 0
+	c := client.NewConsumerLimitServiceClient(fake, resilience)
+
+	for i := 0; i < 3; i++ {
+		_, err := c.GetConsumerLimitByConsumerIdAndTenor(context.Background(), 1, 6)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	}
+
+	// open: fails fast without calling the service
+	_, err := c.GetConsumerLimitByConsumerIdAndTenor(context.Background(), 1, 6)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Contains(t, err.Error(), "circuit breaker is open")
+	assert.Equal(t, 3, fake.calls)
+}
+
+func TestConsumerLimitClientDoesNotRetryOpenCircuit(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+	fake := &fakeConsumerLimitClient{errs: []error{unavailable}}
+	resilience := testResilience
+	resilience.ConsumerMaxRetries = 5
+	resilience.ConsumerRetryBackoff = 50 * time.Millisecond
+	resilience.ConsumerRetryMaxBackoff = 50 * time.Millisecond
+	resilience.ConsumerBreakerFailures = 1
+	c := client.NewConsumerLimitServiceClient(fake, resilience)
+
+	// the first failure opens the breaker, the retry that follows stops there
+	_, err := c.GetConsumerLimitByConsumerIdAndTenor(context.Background(), 1, 6)
+	assert.ErrorIs(t, err, client.ErrCircuitOpen)
+	assert.Equal(t, 1, fake.calls)
+
+	// while open, reads fail fast without sleeping through the backoffs
+	start := time.Now()
+	_, err = c.GetConsumerLimitByConsumerIdAndTenor(context.Background(), 1, 6)
+	assert.ErrorIs(t, err, client.ErrCircuitOpen)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Less(t, time.Since(start), 25*time.Millisecond)
+	assert.Equal(t, 1, fake.calls)
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	breaker := client.NewCircuitBreaker(1, 10*time.Millisecond)
+
+	assert.True(t, breaker.Allow())
+	breaker.Failure()
+	assert.False(t, breaker.Allow())
+
+	time.Sleep(15 * time.Millisecond)
+	// a single trial call goes through
+	assert.True(t, breaker.Allow())
+	assert.False(t, breaker.Allow())
+	breaker.Success()
+	assert.True(t, breaker.Allow())
+}
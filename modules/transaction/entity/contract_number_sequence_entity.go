@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+const (
+	ContractNumberSequenceTableName = "contract_number_sequences"
+
+	// ContractNumberSequenceDayLayout keys the per-day counters
+	ContractNumberSequenceDayLayout = "20060102"
+)
+
+// ContractNumberSequence is the last contract number counter handed out on
+// one day by the sequence strategy.
+type ContractNumberSequence struct {
+	Day       string    `json:"day" gorm:"primaryKey"`
+	Counter   uint64    `json:"counter"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (c *ContractNumberSequence) TableName() string {
+	return ContractNumberSequenceTableName
+}
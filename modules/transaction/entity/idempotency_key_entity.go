@@ -0,0 +1,41 @@
+package entity
+
+import "time"
+
+const (
+	IdempotencyKeyTableName = "idempotency_keys"
+
+	IdempotencyKeyStatusInProgress = "IN_PROGRESS"
+	IdempotencyKeyStatusCompleted  = "COMPLETED"
+)
+
+// IdempotencyKey remembers the first response given for a client supplied
+// idempotency key, together with a hash of the request that produced it. An
+// IN_PROGRESS key is held by its request until LockedUntil. SagaReference is
+// reserved with the key for the saga its request starts.
+type IdempotencyKey struct {
+	Id            uint64     `json:"id"`
+	Key           string     `json:"key"`
+	Method        string     `json:"method"`
+	RequestHash   string     `json:"request_hash"`
+	Status        string     `json:"status"`
+	SagaReference string     `json:"saga_reference"`
+	Response      []byte     `json:"response"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	LockedUntil   *time.Time `json:"locked_until"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (i *IdempotencyKey) TableName() string {
+	return IdempotencyKeyTableName
+}
+
+func (i *IdempotencyKey) IsExpired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
+
+// IsLocked reports whether the request that reserved the key still holds it.
+func (i *IdempotencyKey) IsLocked(now time.Time) bool {
+	return i.LockedUntil != nil && now.Before(*i.LockedUntil)
+}
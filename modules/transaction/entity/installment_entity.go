@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"time"
+	"xyz-transaction-service/pb"
+)
+
+const (
+	InstallmentTableName = "installments"
+	DueDateLayout        = "2006-01-02"
+
+	InstallmentStatusUnpaid        = "UNPAID"
+	InstallmentStatusPartiallyPaid = "PARTIALLY_PAID"
+	InstallmentStatusPaid          = "PAID"
+)
+
+type Installment struct {
+	Id                 uint64     `json:"id"`
+	TransactionId      uint64     `json:"transaction_id"`
+	ContractNumber     string     `json:"contract_number"`
+	InstallmentNumber  uint32     `json:"installment_number"`
+	DueDate            time.Time  `json:"due_date" gorm:"type:date"`
+	Principal          uint64     `json:"principal"`
+	Interest           uint64     `json:"interest"`
+	Fee                uint64     `json:"fee"`
+	Amount             uint64     `json:"amount"`
+	OutstandingBalance uint64     `json:"outstanding_balance"`
+	Penalty            uint64     `json:"penalty"`
+	PaidPrincipal      uint64     `json:"paid_principal"`
+	PaidInterest       uint64     `json:"paid_interest"`
+	PaidFee            uint64     `json:"paid_fee"`
+	PaidPenalty        uint64     `json:"paid_penalty"`
+	Status             string     `json:"status"`
+	PaidAt             *time.Time `json:"paid_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+func (i *Installment) TableName() string {
+	return InstallmentTableName
+}
+
+// Outstanding returns what is still owed on the installment, penalty included.
+func (i *Installment) Outstanding() uint64 {
+	return i.Amount + i.Penalty - i.PaidPrincipal - i.PaidInterest - i.PaidFee - i.PaidPenalty
+}
+
+func ConvertInstallmentEntityToProto(i *Installment) *pb.Installment {
+	var paidAt string
+	if i.PaidAt != nil {
+		paidAt = i.PaidAt.Format(time.RFC3339)
+	}
+
+	return &pb.Installment{
+		Id:                 i.Id,
+		TransactionId:      i.TransactionId,
+		ContractNumber:     i.ContractNumber,
+		InstallmentNumber:  i.InstallmentNumber,
+		DueDate:            i.DueDate.Format(DueDateLayout),
+		Principal:          i.Principal,
+		Interest:           i.Interest,
+		Fee:                i.Fee,
+		Amount:             i.Amount,
+		OutstandingBalance: i.OutstandingBalance,
+		Penalty:            i.Penalty,
+		PaidPrincipal:      i.PaidPrincipal,
+		PaidInterest:       i.PaidInterest,
+		PaidFee:            i.PaidFee,
+		PaidPenalty:        i.PaidPenalty,
+		Status:             i.Status,
+		PaidAt:             paidAt,
+		CreatedAt:          i.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:          i.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"time"
+	"xyz-transaction-service/pb"
+)
+
+const (
+	PaymentTableName           = "payments"
+	PaymentAllocationTableName = "payment_allocations"
+)
+
+type Payment struct {
+	Id               uint64    `json:"id"`
+	TransactionId    uint64    `json:"transaction_id"`
+	ContractNumber   string    `json:"contract_number"`
+	PaymentReference string    `json:"payment_reference"`
+	Amount           uint64    `json:"amount"`
+	PaidAt           time.Time `json:"paid_at"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+
+	Allocations []*PaymentAllocation `json:"allocations,omitempty" gorm:"foreignKey:PaymentId"`
+}
+
+type PaymentAllocation struct {
+	Id                uint64    `json:"id"`
+	PaymentId         uint64    `json:"payment_id"`
+	InstallmentId     uint64    `json:"installment_id"`
+	InstallmentNumber uint32    `json:"installment_number"`
+	Component         string    `json:"component"`
+	Amount            uint64    `json:"amount"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func (p *Payment) TableName() string {
+	return PaymentTableName
+}
+
+func (pa *PaymentAllocation) TableName() string {
+	return PaymentAllocationTableName
+}
+
+func ConvertPaymentEntityToProto(p *Payment) *pb.Payment {
+	var allocations []*pb.PaymentAllocation
+	for _, a := range p.Allocations {
+		allocations = append(allocations, &pb.PaymentAllocation{
+			InstallmentId:     a.InstallmentId,
+			InstallmentNumber: a.InstallmentNumber,
+			Component:         a.Component,
+			Amount:            a.Amount,
+		})
+	}
+
+	return &pb.Payment{
+		Id:               p.Id,
+		TransactionId:    p.TransactionId,
+		ContractNumber:   p.ContractNumber,
+		PaymentReference: p.PaymentReference,
+		Amount:           p.Amount,
+		PaidAt:           p.PaidAt.Format(time.RFC3339),
+		Allocations:      allocations,
+		CreatedAt:        p.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,124 @@
+package entity
+
+import (
+	"math"
+	"math/bits"
+	"time"
+	"xyz-transaction-service/pb"
+)
+
+const (
+	ProductTableName       = "products"
+	ProductTenorTableName  = "product_tenors"
+	AdminFeeTypeFlat       = "FLAT"
+	AdminFeeTypePercentage = "PERCENTAGE"
+
+	bpsDenominator = 10000
+)
+
+// Product is one version of a financing product. A version is never changed
+// after it is created, except for closing its effective range, so a contract
+// priced with it can always be explained.
+type Product struct {
+	Id             uint64          `json:"id"`
+	Code           string          `json:"code"`
+	Version        uint32          `json:"version"`
+	Name           string          `json:"name"`
+	PricingMethod  string          `json:"pricing_method"`
+	AdminFeeType   string          `json:"admin_fee_type"`
+	AdminFeeAmount uint64          `json:"admin_fee_amount"`
+	AdminFeeBps    uint32          `json:"admin_fee_bps"`
+	AdminFeeMin    uint64          `json:"admin_fee_min"`
+	AdminFeeMax    uint64          `json:"admin_fee_max"`
+	EffectiveFrom  time.Time       `json:"effective_from"`
+	EffectiveTo    *time.Time      `json:"effective_to"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	Tenors         []*ProductTenor `json:"tenors" gorm:"foreignKey:ProductId"`
+}
+
+// ProductTenor is a tenor a product allows, with the rate it is priced at.
+type ProductTenor struct {
+	Id            uint64 `json:"id"`
+	ProductId     uint64 `json:"product_id"`
+	Tenor         uint32 `json:"tenor"`
+	AnnualRateBps uint32 `json:"annual_rate_bps"`
+}
+
+func (p *Product) TableName() string {
+	return ProductTableName
+}
+
+func (pt *ProductTenor) TableName() string {
+	return ProductTenorTableName
+}
+
+// IsEffective reports whether at falls in [EffectiveFrom, EffectiveTo).
+func (p *Product) IsEffective(at time.Time) bool {
+	return !at.Before(p.EffectiveFrom) && (p.EffectiveTo == nil || at.Before(*p.EffectiveTo))
+}
+
+// Tenor returns the terms of tenor, or nil when the product does not allow it.
+func (p *Product) Tenor(tenor uint32) *ProductTenor {
+	for _, t := range p.Tenors {
+		if t.Tenor == tenor {
+			return t
+		}
+	}
+	return nil
+}
+
+// AdminFee applies the admin fee formula to otr. A percentage is rounded half
+// up and then kept within AdminFeeMin and AdminFeeMax, when they are set. The
+// percentage is taken in 128 bits and saturates at math.MaxUint64.
+func (p *Product) AdminFee(otr uint64) uint64 {
+	if p.AdminFeeType == AdminFeeTypeFlat {
+		return p.AdminFeeAmount
+	}
+
+	fee := percentageFee(otr, p.AdminFeeBps)
+	if fee < p.AdminFeeMin {
+		fee = p.AdminFeeMin
+	}
+	if p.AdminFeeMax > 0 && fee > p.AdminFeeMax {
+		fee = p.AdminFeeMax
+	}
+	return fee
+}
+
+func percentageFee(otr uint64, bps uint32) uint64 {
+	hi, lo := bits.Mul64(otr, uint64(bps))
+	lo, carry := bits.Add64(lo, bpsDenominator/2, 0)
+	hi += carry
+	if hi >= bpsDenominator {
+		return math.MaxUint64
+	}
+	fee, _ := bits.Div64(hi, lo, bpsDenominator)
+	return fee
+}
+
+func ConvertProductEntityToProto(p *Product) *pb.Product {
+	product := &pb.Product{
+		Code:           p.Code,
+		Version:        p.Version,
+		Name:           p.Name,
+		PricingMethod:  p.PricingMethod,
+		AdminFeeType:   p.AdminFeeType,
+		AdminFeeAmount: p.AdminFeeAmount,
+		AdminFeeBps:    p.AdminFeeBps,
+		AdminFeeMin:    p.AdminFeeMin,
+		AdminFeeMax:    p.AdminFeeMax,
+		EffectiveFrom:  p.EffectiveFrom.Format(time.RFC3339),
+		CreatedAt:      p.CreatedAt.Format(time.RFC3339),
+	}
+	if p.EffectiveTo != nil {
+		product.EffectiveTo = p.EffectiveTo.Format(time.RFC3339)
+	}
+	for _, t := range p.Tenors {
+		product.Tenors = append(product.Tenors, &pb.ProductTenor{
+			Tenor:         t.Tenor,
+			AnnualRateBps: t.AnnualRateBps,
+		})
+	}
+	return product
+}
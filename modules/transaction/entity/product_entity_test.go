@@ -0,0 +1,46 @@
+package entity_test
+
+import (
+	"math"
+	"testing"
+	"time"
+	"xyz-transaction-service/modules/transaction/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProductAdminFee(t *testing.T) {
+	tests := []struct {
+		name    string
+		product entity.Product
+		otr     uint64
+		want    uint64
+	}{
+		{"flat", entity.Product{AdminFeeType: entity.AdminFeeTypeFlat, AdminFeeAmount: 50000}, 1000000, 50000},
+		{"percentage", entity.Product{AdminFeeType: entity.AdminFeeTypePercentage, AdminFeeBps: 150}, 1000000, 15000},
+		{"percentage rounds half up", entity.Product{AdminFeeType: entity.AdminFeeTypePercentage, AdminFeeBps: 150}, 1001, 15},
+		{"percentage below min", entity.Product{AdminFeeType: entity.AdminFeeTypePercentage, AdminFeeBps: 150, AdminFeeMin: 20000}, 1000000, 20000},
+		{"percentage of a large otr", entity.Product{AdminFeeType: entity.AdminFeeTypePercentage, AdminFeeBps: 10000}, math.MaxUint64 / 2, math.MaxUint64 / 2},
+		{"percentage saturates", entity.Product{AdminFeeType: entity.AdminFeeTypePercentage, AdminFeeBps: 20000}, math.MaxUint64, math.MaxUint64},
+		{"percentage above max", entity.Product{AdminFeeType: entity.AdminFeeTypePercentage, AdminFeeBps: 150, AdminFeeMax: 10000}, 1000000, 10000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.product.AdminFee(tt.otr))
+		})
+	}
+}
+
+func TestProductIsEffective(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 6, 0)
+	product := entity.Product{EffectiveFrom: from, EffectiveTo: &to}
+
+	assert.False(t, product.IsEffective(from.Add(-time.Second)))
+	assert.True(t, product.IsEffective(from))
+	assert.False(t, product.IsEffective(to))
+
+	product.EffectiveTo = nil
+	assert.True(t, product.IsEffective(to))
+}
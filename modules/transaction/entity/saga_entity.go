@@ -0,0 +1,86 @@
+package entity
+
+import "time"
+
+const (
+	SagaTableName     = "sagas"
+	SagaStepTableName = "saga_steps"
+
+	SagaTypeCreateTransaction = "CREATE_TRANSACTION"
+
+	SagaStatusStarted      = "STARTED"
+	SagaStatusCompleted    = "COMPLETED"
+	SagaStatusCompensating = "COMPENSATING"
+	SagaStatusCompensated  = "COMPENSATED"
+	SagaStatusFailed       = "FAILED"
+
+	SagaStepCreateTransaction   = "CREATE_TRANSACTION"
+	SagaStepDebitLimit          = "DEBIT_LIMIT"
+	SagaStepActivateTransaction = "ACTIVATE_TRANSACTION"
+
+	SagaStepStatusPending     = "PENDING"
+	SagaStepStatusInProgress  = "IN_PROGRESS"
+	SagaStepStatusDone        = "DONE"
+	SagaStepStatusFailed      = "FAILED"
+	SagaStepStatusCompensated = "COMPENSATED"
+)
+
+type Saga struct {
+	Id             uint64    `json:"id"`
+	SagaType       string    `json:"saga_type"`
+	Reference      string    `json:"reference"`
+	ContractNumber string    `json:"contract_number"`
+	TransactionId  uint64    `json:"transaction_id"`
+	ConsumerId     uint64    `json:"consumer_id"`
+	Tenor          uint32    `json:"tenor"`
+	Otr            uint64    `json:"otr"`
+	AdminFee       uint64    `json:"admin_fee"`
+	Installment    uint64    `json:"installment"`
+	Interest       uint64    `json:"interest"`
+	AssetName      string    `json:"asset_name"`
+	ProductCode    string    `json:"product_code"`
+	ProductVersion uint32    `json:"product_version"`
+	Status         string    `json:"status"`
+	LastError      string    `json:"last_error"`
+	Attempts       uint32    `json:"attempts"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	Steps []*SagaStep `json:"steps,omitempty" gorm:"foreignKey:SagaId"`
+}
+
+type SagaStep struct {
+	Id        uint64    `json:"id"`
+	SagaId    uint64    `json:"saga_id"`
+	Name      string    `json:"name"`
+	Sequence  uint32    `json:"sequence"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (s *Saga) TableName() string {
+	return SagaTableName
+}
+
+func (ss *SagaStep) TableName() string {
+	return SagaStepTableName
+}
+
+// IsTerminal reports whether the saga needs no further work.
+func (s *Saga) IsTerminal() bool {
+	return s.Status == SagaStatusCompleted || s.Status == SagaStatusCompensated || s.Status == SagaStatusFailed
+}
+
+// Booked reports whether transaction is the one the saga creates rather than
+// another contract that happens to hold its reserved contract number. The
+// creation time allows for a minute of clock skew between instances.
+func (s *Saga) Booked(transaction *Transaction) bool {
+	return transaction.ConsumerId == s.ConsumerId &&
+		transaction.Tenor == s.Tenor &&
+		transaction.Otr == s.Otr &&
+		transaction.AssetName == s.AssetName &&
+		transaction.ProductCode == s.ProductCode &&
+		!transaction.CreatedAt.Before(s.CreatedAt.Add(-time.Minute))
+}
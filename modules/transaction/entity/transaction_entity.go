@@ -3,12 +3,30 @@ package entity
 import (
 	"time"
 	"xyz-transaction-service/pb"
+
+	"gorm.io/gorm"
 )
 
 const (
 	TransactionTableName = "transactions"
+
+	TransactionStatusPending    = "PENDING"
+	TransactionStatusActive     = "ACTIVE"
+	TransactionStatusPaidOff    = "PAID_OFF"
+	TransactionStatusCancelled  = "CANCELLED"
+	TransactionStatusDefaulted  = "DEFAULTED"
+	TransactionStatusWrittenOff = "WRITTEN_OFF"
 )
 
+var TransactionStatuses = []string{
+	TransactionStatusPending,
+	TransactionStatusActive,
+	TransactionStatusPaidOff,
+	TransactionStatusCancelled,
+	TransactionStatusDefaulted,
+	TransactionStatusWrittenOff,
+}
+
 type Transaction struct {
 	Id             uint64    `json:"id"`
 	ContractNumber string    `json:"contract_number"`
@@ -19,8 +37,17 @@ type Transaction struct {
 	Installment    uint64    `json:"installment"`
 	Interest       uint64    `json:"interest"`
 	AssetName      string    `json:"asset_name"`
+	Status         string    `json:"status"`
+	ProductCode    string    `json:"product_code"`
+	ProductVersion uint32    `json:"product_version"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
+
+	// set instead of removing the row, which keeps the contract auditable
+	DeletedAt     gorm.DeletedAt `json:"deleted_at,omitempty"`
+	DeletedReason string         `json:"deleted_reason,omitempty"`
+
+	Installments []*Installment `json:"installments,omitempty" gorm:"foreignKey:TransactionId"`
 }
 
 func NewTransactionEntity(contractNumber string, consumerId uint64, tenor uint32, otr uint64, adminFee uint64, installment uint64, interest uint64, assetName string) *Transaction {
@@ -33,11 +60,21 @@ func NewTransactionEntity(contractNumber string, consumerId uint64, tenor uint32
 		Installment:    installment,
 		Interest:       interest,
 		AssetName:      assetName,
+		Status:         TransactionStatusPending,
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
 	}
 }
 
+func IsValidTransactionStatus(status string) bool {
+	for _, s := range TransactionStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
 func (t *Transaction) TableName() string {
 	return TransactionTableName
 }
@@ -53,6 +90,9 @@ func ConvertEntityToProto(t *Transaction) *pb.Transaction {
 		Installment:    t.Installment,
 		Interest:       t.Interest,
 		AssetName:      t.AssetName,
+		Status:         t.Status,
+		ProductCode:    t.ProductCode,
+		ProductVersion: t.ProductVersion,
 		CreatedAt:      t.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:      t.UpdatedAt.Format(time.RFC3339),
 	}
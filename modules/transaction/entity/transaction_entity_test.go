@@ -45,5 +45,8 @@ func TestNewTransactionEntity(t *testing.T) {
 		if e.AssetName != assetName {
 			t.Error("NewTransactionEntity() returned incorrect AssetName")
 		}
+		if e.Status != entity.TransactionStatusPending {
+			t.Error("NewTransactionEntity() returned incorrect Status")
+		}
 	}
 }
@@ -0,0 +1,97 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+	"xyz-transaction-service/pb"
+)
+
+const (
+	TransactionEventTableName = "transaction_events"
+
+	TransactionEventCreated       = "CREATED"
+	TransactionEventStatusChanged = "STATUS_CHANGED"
+	TransactionEventRolledBack    = "ROLLED_BACK"
+	TransactionEventAccessDenied  = "ACCESS_DENIED"
+
+	// TransactionEventSystemActor is recorded for changes made without a caller,
+	// such as the saga recovery worker
+	TransactionEventSystemActor = "system"
+)
+
+// TransactionEvent is one append-only audit record of a change to a contract.
+// Before and After hold JSON snapshots of the transaction; Before is empty on
+// creation and After is empty on rollback. ACCESS_DENIED events carry no
+// snapshot, and no contract at all when a whole consumer was requested.
+type TransactionEvent struct {
+	Id             uint64    `json:"id"`
+	TransactionId  uint64    `json:"transaction_id"`
+	ContractNumber string    `json:"contract_number"`
+	EventType      string    `json:"event_type"`
+	Actor          string    `json:"actor"`
+	ActorRole      uint32    `json:"actor_role"`
+	RequestId      string    `json:"request_id"`
+	Before         string    `json:"before"`
+	After          string    `json:"after"`
+	Reason         string    `json:"reason"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (e *TransactionEvent) TableName() string {
+	return TransactionEventTableName
+}
+
+// TransactionSnapshot serializes the contract row without its schedule.
+func TransactionSnapshot(t *Transaction) (string, error) {
+	if t == nil {
+		return "", nil
+	}
+
+	snapshot := *t
+	snapshot.Installments = nil
+
+	b, err := json.Marshal(&snapshot)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// TransactionFromHistory returns the latest snapshot in the history of a
+// contract, which outlives a rolled back contract. Without any snapshot the
+// returned transaction belongs to no consumer.
+func TransactionFromHistory(events []*TransactionEvent) *Transaction {
+	for i := len(events) - 1; i >= 0; i-- {
+		for _, snapshot := range []string{events[i].After, events[i].Before} {
+			if snapshot == "" {
+				continue
+			}
+			var t Transaction
+			if err := json.Unmarshal([]byte(snapshot), &t); err == nil {
+				return &t
+			}
+		}
+	}
+
+	transaction := &Transaction{}
+	if len(events) > 0 {
+		transaction.ContractNumber = events[0].ContractNumber
+	}
+	return transaction
+}
+
+func ConvertTransactionEventEntityToProto(e *TransactionEvent) *pb.TransactionEvent {
+	return &pb.TransactionEvent{
+		Id:             e.Id,
+		TransactionId:  e.TransactionId,
+		ContractNumber: e.ContractNumber,
+		EventType:      e.EventType,
+		Actor:          e.Actor,
+		ActorRole:      e.ActorRole,
+		RequestId:      e.RequestId,
+		Before:         e.Before,
+		After:          e.After,
+		Reason:         e.Reason,
+		CreatedAt:      e.CreatedAt.Format(time.RFC3339),
+	}
+}
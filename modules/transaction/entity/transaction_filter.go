@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TransactionFilter narrows transaction listings; zero values are ignored.
+// CreatedFrom is inclusive and CreatedTo is exclusive.
+type TransactionFilter struct {
+	Status      string
+	ConsumerId  uint64
+	Tenor       uint32
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	OtrMin      uint64
+	OtrMax      uint64
+	AssetName   string
+}
+
+// TransactionCursor is the position of the last row of a page in the
+// created_at desc, id desc ordering used for keyset pagination.
+type TransactionCursor struct {
+	CreatedAt time.Time
+	Id        uint64
+}
+
+type TransactionPage struct {
+	Size  int
+	After *TransactionCursor
+}
+
+func NewTransactionCursor(t *Transaction) *TransactionCursor {
+	return &TransactionCursor{CreatedAt: t.CreatedAt, Id: t.Id}
+}
+
+// Encode turns the cursor into an opaque page token.
+func (c *TransactionCursor) Encode() string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + "." + strconv.FormatUint(c.Id, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeTransactionCursor(token string) (*TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("malformed page token")
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return nil, fmt.Errorf("malformed page token")
+	}
+
+	nanos, err := strconv.ParseInt(createdAt, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("malformed page token")
+	}
+	transactionId, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("malformed page token")
+	}
+
+	return &TransactionCursor{CreatedAt: time.Unix(0, nanos).UTC(), Id: transactionId}, nil
+}
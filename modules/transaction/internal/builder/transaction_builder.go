@@ -1,20 +1,44 @@
 package builder
 
 import (
+	"xyz-transaction-service/common/authorization"
 	"xyz-transaction-service/common/config"
+	gormConn "xyz-transaction-service/common/gorm"
 	"xyz-transaction-service/modules/transaction/client"
 	"xyz-transaction-service/modules/transaction/internal/handler"
+	"xyz-transaction-service/modules/transaction/internal/numbering"
 	"xyz-transaction-service/modules/transaction/internal/repository"
+	"xyz-transaction-service/modules/transaction/internal/validator"
 	"xyz-transaction-service/modules/transaction/service"
+	"xyz-transaction-service/pb"
 
-	"google.golang.org/grpc"
 	"gorm.io/gorm"
 )
 
-func BuildTransactionHandler(cfg config.Config, db *gorm.DB, grpcConn *grpc.ClientConn) *handler.TransactionHandler {
+func BuildTransactionHandler(cfg config.Config, db *gorm.DB, consumerLimitClient pb.ConsumerLimitServiceClient, policy *authorization.Policy) (*handler.TransactionHandler, *service.SagaRecoveryWorker, *service.IdempotencyKeySweeper, client.ConsumerLimitServiceClient) {
 	transactionRepository := repository.NewTransactionRepository(db)
-	transactionSvc := service.NewTransactionService(cfg, transactionRepository)
-	consumerLimitSvc := client.BuildConsumerLimitServiceClient(cfg.ClientURL.Consumer)
+	installmentRepository := repository.NewInstallmentRepository(db)
+	transactionEventRepository := repository.NewTransactionEventRepository(db)
+	transactor := gormConn.NewTransactor(db)
+	contractNumberGenerator := numbering.NewContractNumberGenerator(cfg.ContractNumber, repository.NewContractNumberSequenceRepository(db))
+	productSvc := service.NewProductService(cfg, repository.NewProductRepository(db))
+	transactionSvc := service.NewTransactionService(cfg, transactor, transactionRepository, installmentRepository, transactionEventRepository, contractNumberGenerator, productSvc)
+	paymentRepository := repository.NewPaymentRepository(db)
+	paymentSvc := service.NewPaymentService(cfg, transactor, transactionRepository, installmentRepository, paymentRepository, transactionEventRepository)
+	var consumerLimitSvc client.ConsumerLimitServiceClient
+	if consumerLimitClient != nil {
+		consumerLimitSvc = client.NewConsumerLimitServiceClient(consumerLimitClient, cfg.ClientResilience)
+	} else {
+		consumerLimitSvc = client.BuildConsumerLimitServiceClient(cfg)
+	}
+	sagaRepository := repository.NewSagaRepository(db)
+	createTransactionSaga := service.NewCreateTransactionSaga(cfg, sagaRepository, transactionSvc, consumerLimitSvc)
+	idempotencySvc := service.NewIdempotencyService(cfg, repository.NewIdempotencyKeyRepository(db), sagaRepository)
+	consumerAccessGuard := service.NewConsumerAccessGuard(policy, transactionEventRepository)
+	sagaRecoveryWorker := service.NewSagaRecoveryWorker(cfg, sagaRepository, createTransactionSaga)
+	idempotencyKeySweeper := service.NewIdempotencyKeySweeper(cfg, idempotencySvc)
 
-	return handler.NewTransactionHandler(cfg, transactionSvc, consumerLimitSvc)
+	requestValidator := validator.NewRequestValidator(transactionSvc)
+
+	return handler.NewTransactionHandler(cfg, transactionSvc, paymentSvc, createTransactionSaga, idempotencySvc, consumerAccessGuard, consumerLimitSvc, requestValidator, productSvc), sagaRecoveryWorker, idempotencyKeySweeper, consumerLimitSvc
 }
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+	commonErr "xyz-transaction-service/common/error"
+	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/pb"
+
+	"google.golang.org/grpc/status"
+)
+
+func (th *TransactionHandler) CreateProduct(ctx context.Context, req *pb.Product) (*pb.ProductResponse, error) {
+	if err := th.requestValidator.Validate(req); err != nil {
+		log.Println("WARNING: [TransactionHandler - CreateProduct] Invalid request:", status.Convert(err).Message())
+		return &pb.ProductResponse{
+			Code:    uint32(http.StatusBadRequest),
+			Message: status.Convert(err).Message(),
+		}, err
+	}
+
+	product, err := th.productSvc.Create(ctx, productFromProto(req))
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [TransactionHandler - CreateProduct] Error while create product:", parseError.Message)
+		return &pb.ProductResponse{
+			Code:    uint32(commonErr.HttpStatusFromCode(parseError.Code)),
+			Message: parseError.Message,
+		}, status.Errorf(parseError.Code, parseError.Message)
+	}
+
+	return &pb.ProductResponse{
+		Code:    uint32(http.StatusCreated),
+		Message: "Success create product",
+		Data:    entity.ConvertProductEntityToProto(product),
+	}, nil
+}
+
+func (th *TransactionHandler) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.ProductResponse, error) {
+	if err := th.requestValidator.Validate(req); err != nil {
+		log.Println("WARNING: [TransactionHandler - GetProduct] Invalid request:", status.Convert(err).Message())
+		return &pb.ProductResponse{
+			Code:    uint32(http.StatusBadRequest),
+			Message: status.Convert(err).Message(),
+		}, err
+	}
+
+	product, err := th.productSvc.FindByCode(ctx, req.Code, req.Version)
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [TransactionHandler - GetProduct] Error while find product:", parseError.Message)
+		return &pb.ProductResponse{
+			Code:    uint32(commonErr.HttpStatusFromCode(parseError.Code)),
+			Message: parseError.Message,
+		}, status.Errorf(parseError.Code, parseError.Message)
+	}
+
+	return &pb.ProductResponse{
+		Code:    uint32(http.StatusOK),
+		Message: "Success get product",
+		Data:    entity.ConvertProductEntityToProto(product),
+	}, nil
+}
+
+func (th *TransactionHandler) ListProducts(ctx context.Context, req *pb.ListProductsRequest) (*pb.ProductListResponse, error) {
+	if err := th.requestValidator.Validate(req); err != nil {
+		log.Println("WARNING: [TransactionHandler - ListProducts] Invalid request:", status.Convert(err).Message())
+		return &pb.ProductListResponse{
+			Code:    uint32(http.StatusBadRequest),
+			Message: status.Convert(err).Message(),
+		}, err
+	}
+
+	productList, err := th.productSvc.FindAll(ctx, timeOrNow(req.EffectiveAt))
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [TransactionHandler - ListProducts] Error while find products:", parseError.Message)
+		return &pb.ProductListResponse{
+			Code:    uint32(commonErr.HttpStatusFromCode(parseError.Code)),
+			Message: parseError.Message,
+		}, status.Errorf(parseError.Code, parseError.Message)
+	}
+
+	var products []*pb.Product
+	for _, p := range productList {
+		products = append(products, entity.ConvertProductEntityToProto(p))
+	}
+
+	return &pb.ProductListResponse{
+		Code:    uint32(http.StatusOK),
+		Message: "Success get products",
+		Data:    products,
+	}, nil
+}
+
+func (th *TransactionHandler) RetireProduct(ctx context.Context, req *pb.RetireProductRequest) (*pb.ProductResponse, error) {
+	if err := th.requestValidator.Validate(req); err != nil {
+		log.Println("WARNING: [TransactionHandler - RetireProduct] Invalid request:", status.Convert(err).Message())
+		return &pb.ProductResponse{
+			Code:    uint32(http.StatusBadRequest),
+			Message: status.Convert(err).Message(),
+		}, err
+	}
+
+	product, err := th.productSvc.Retire(ctx, req.Code, timeOrNow(req.EffectiveTo))
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [TransactionHandler - RetireProduct] Error while retire product:", parseError.Message)
+		return &pb.ProductResponse{
+			Code:    uint32(commonErr.HttpStatusFromCode(parseError.Code)),
+			Message: parseError.Message,
+		}, status.Errorf(parseError.Code, parseError.Message)
+	}
+
+	return &pb.ProductResponse{
+		Code:    uint32(http.StatusOK),
+		Message: "Success retire product",
+		Data:    entity.ConvertProductEntityToProto(product),
+	}, nil
+}
+
+// productFromProto converts a product the request validator has accepted, so
+// its timestamps are known to parse.
+func productFromProto(p *pb.Product) *entity.Product {
+	product := &entity.Product{
+		Code:           p.Code,
+		Name:           p.Name,
+		PricingMethod:  p.PricingMethod,
+		AdminFeeType:   p.AdminFeeType,
+		AdminFeeAmount: p.AdminFeeAmount,
+		AdminFeeBps:    p.AdminFeeBps,
+		AdminFeeMin:    p.AdminFeeMin,
+		AdminFeeMax:    p.AdminFeeMax,
+	}
+	if p.EffectiveFrom != "" {
+		product.EffectiveFrom, _ = time.Parse(time.RFC3339, p.EffectiveFrom)
+	}
+	if p.EffectiveTo != "" {
+		effectiveTo, _ := time.Parse(time.RFC3339, p.EffectiveTo)
+		product.EffectiveTo = &effectiveTo
+	}
+	for _, t := range p.Tenors {
+		product.Tenors = append(product.Tenors, &entity.ProductTenor{
+			Tenor:         t.Tenor,
+			AnnualRateBps: t.AnnualRateBps,
+		})
+	}
+	return product
+}
+
+// timeOrNow parses a validated, optional RFC3339 value, defaulting to now.
+func timeOrNow(value string) time.Time {
+	if value == "" {
+		return time.Now()
+	}
+	parsed, _ := time.Parse(time.RFC3339, value)
+	return parsed
+}
@@ -2,43 +2,83 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
-	"sync"
+	"time"
 	"xyz-transaction-service/common/config"
 	commonErr "xyz-transaction-service/common/error"
+	commonJwt "xyz-transaction-service/common/jwt"
+	"xyz-transaction-service/common/metrics"
+	"xyz-transaction-service/common/tracing"
 	"xyz-transaction-service/modules/transaction/client"
 	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/modules/transaction/internal/validator"
 	"xyz-transaction-service/modules/transaction/service"
 	"xyz-transaction-service/pb"
 
+	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
-	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/proto"
 )
 
+// idempotencyKeyHeader is the metadata key clients use to make CreateTransaction retries safe
+const idempotencyKeyHeader = "idempotency-key"
+
 type TransactionHandler struct {
 	pb.UnimplementedTransactionServiceServer
-	config         config.Config
-	transactionSvc service.TransactionServiceUseCase
-	consumerLimitSvc client.ConsumerLimitServiceClient
+	config                config.Config
+	transactionSvc        service.TransactionServiceUseCase
+	paymentSvc            service.PaymentServiceUseCase
+	createTransactionSaga service.CreateTransactionSagaUseCase
+	idempotencySvc        service.IdempotencyServiceUseCase
+	consumerAccessGuard   service.ConsumerAccessGuardUseCase
+	consumerLimitSvc      client.ConsumerLimitServiceClient
+	requestValidator      validator.RequestValidatorUseCase
+	productSvc            service.ProductServiceUseCase
 }
 
-func NewTransactionHandler(config config.Config, transactionSvc service.TransactionServiceUseCase, consumerLimitSvc client.ConsumerLimitServiceClient) *TransactionHandler {
+func NewTransactionHandler(config config.Config, transactionSvc service.TransactionServiceUseCase, paymentSvc service.PaymentServiceUseCase, createTransactionSaga service.CreateTransactionSagaUseCase, idempotencySvc service.IdempotencyServiceUseCase, consumerAccessGuard service.ConsumerAccessGuardUseCase, consumerLimitSvc client.ConsumerLimitServiceClient, requestValidator validator.RequestValidatorUseCase, productSvc service.ProductServiceUseCase) *TransactionHandler {
 	return &TransactionHandler{
-		config:         config,
-		transactionSvc: transactionSvc,
-		consumerLimitSvc: consumerLimitSvc,
+		config:                config,
+		transactionSvc:        transactionSvc,
+		paymentSvc:            paymentSvc,
+		createTransactionSaga: createTransactionSaga,
+		idempotencySvc:        idempotencySvc,
+		consumerAccessGuard:   consumerAccessGuard,
+		consumerLimitSvc:      consumerLimitSvc,
+		requestValidator:      requestValidator,
+		productSvc:            productSvc,
 	}
 }
 
-func (th *TransactionHandler) GetAllTransactions(ctx context.Context, req *emptypb.Empty) (*pb.TransactionListResponse, error) {
-	transactionList, err := th.transactionSvc.FindAll(ctx, req)
+func (th *TransactionHandler) GetAllTransactions(ctx context.Context, req *pb.GetAllTransactionsRequest) (*pb.TransactionListResponse, error) {
+	if err := th.requestValidator.Validate(req); err != nil {
+		log.Println("WARNING: [TransactionHandler - GetAllTransactions] Invalid request:", status.Convert(err).Message())
+		return &pb.TransactionListResponse{
+			Code:    uint32(http.StatusBadRequest),
+			Message: status.Convert(err).Message(),
+		}, err
+	}
+
+	filter, err := transactionFilterFromProto(req.GetFilter())
+	if err != nil {
+		log.Println("WARNING: [TransactionHandler - GetAllTransactions] Invalid filter:", err)
+		return &pb.TransactionListResponse{
+			Code:    uint32(http.StatusBadRequest),
+			Message: err.Error(),
+		}, status.Errorf(codes.InvalidArgument, err.Error())
+	}
+
+	transactionList, nextPageToken, err := th.transactionSvc.FindAll(ctx, filter, req.PageSize, req.PageToken)
 	if err != nil {
 		parseError := commonErr.ParseError(err)
 		log.Println("ERROR: [TransactionHandler - GetAllTransactions] Error while find all transaction:", parseError.Message)
 		return &pb.TransactionListResponse{
-			Code:    uint32(http.StatusInternalServerError),
+			Code:    uint32(commonErr.HttpStatusFromCode(parseError.Code)),
 			Message: parseError.Message,
 		}, status.Errorf(parseError.Code, parseError.Message)
 	}
@@ -49,13 +89,52 @@ func (th *TransactionHandler) GetAllTransactions(ctx context.Context, req *empty
 	}
 
 	return &pb.TransactionListResponse{
-		Code:    uint32(http.StatusOK),
-		Message: "Success get all transactions",
-		Data:    transactions,
+		Code:          uint32(http.StatusOK),
+		Message:       "Success get all transactions",
+		Data:          transactions,
+		NextPageToken: nextPageToken,
 	}, nil
 }
 
+func (th *TransactionHandler) StreamTransactions(req *pb.StreamTransactionsRequest, stream pb.TransactionService_StreamTransactionsServer) error {
+	if err := th.requestValidator.Validate(req); err != nil {
+		log.Println("WARNING: [TransactionHandler - StreamTransactions] Invalid request:", status.Convert(err).Message())
+		return err
+	}
+
+	filter, err := transactionFilterFromProto(req.GetFilter())
+	if err != nil {
+		log.Println("WARNING: [TransactionHandler - StreamTransactions] Invalid filter:", err)
+		return status.Errorf(codes.InvalidArgument, err.Error())
+	}
+
+	sent := 0
+	err = th.transactionSvc.Stream(stream.Context(), filter, req.BatchSize, func(t *entity.Transaction) error {
+		sent++
+		return stream.Send(entity.ConvertEntityToProto(t))
+	})
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		if parseError.Code == codes.Canceled || parseError.Code == codes.DeadlineExceeded {
+			log.Printf("WARNING: [TransactionHandler - StreamTransactions] Stream stopped by client after %d transactions\n", sent)
+		} else {
+			log.Println("ERROR: [TransactionHandler - StreamTransactions] Error while stream transactions:", parseError.Message)
+		}
+		return status.Errorf(parseError.Code, parseError.Message)
+	}
+
+	return nil
+}
+
 func (th *TransactionHandler) GetTransactionByContractNumber(ctx context.Context, req *pb.TransactionContractNumberRequest) (*pb.TransactionResponse, error) {
+	if err := th.requestValidator.Validate(req); err != nil {
+		log.Println("WARNING: [TransactionHandler - GetTransactionByContractNumber] Invalid request:", status.Convert(err).Message())
+		return &pb.TransactionResponse{
+			Code:    uint32(http.StatusBadRequest),
+			Message: status.Convert(err).Message(),
+		}, err
+	}
+
 	transaction, err := th.transactionSvc.FindByContractNumber(ctx, req.ContractNumber)
 	if err != nil {
 		if transaction == nil {
@@ -73,6 +152,13 @@ func (th *TransactionHandler) GetTransactionByContractNumber(ctx context.Context
 		}, status.Errorf(parseError.Code, parseError.Message)
 	}
 
+	if err := th.consumerAccessGuard.AuthorizeTransaction(ctx, pb.TransactionService_GetTransactionByContractNumber_FullMethodName, transaction); err != nil {
+		return &pb.TransactionResponse{
+			Code:    uint32(http.StatusForbidden),
+			Message: "No permission to access this transaction",
+		}, err
+	}
+
 	return &pb.TransactionResponse{
 		Code:    uint32(http.StatusOK),
 		Message: "Success get transaction by contract number",
@@ -81,12 +167,31 @@ func (th *TransactionHandler) GetTransactionByContractNumber(ctx context.Context
 }
 
 func (th *TransactionHandler) GetTransactionsByConsumerId(ctx context.Context, req *pb.TransactionConsumerIdRequest) (*pb.TransactionListResponse, error) {
-	transactionList, err := th.transactionSvc.FindByConsumerId(ctx, req.ConsumerId)
+	if err := th.requestValidator.Validate(req); err != nil {
+		log.Println("WARNING: [TransactionHandler - GetTransactionsByConsumerId] Invalid request:", status.Convert(err).Message())
+		return &pb.TransactionListResponse{
+			Code:    uint32(http.StatusBadRequest),
+			Message: status.Convert(err).Message(),
+		}, err
+	}
+
+	if err := th.consumerAccessGuard.AuthorizeConsumer(ctx, pb.TransactionService_GetTransactionsByConsumerId_FullMethodName, req.ConsumerId); err != nil {
+		return &pb.TransactionListResponse{
+			Code:    uint32(http.StatusForbidden),
+			Message: "No permission to access transactions of this consumer",
+		}, err
+	}
+
+	filter := &entity.TransactionFilter{
+		Status: req.Status,
+	}
+
+	transactionList, err := th.transactionSvc.FindByConsumerId(ctx, req.ConsumerId, filter)
 	if err != nil {
 		parseError := commonErr.ParseError(err)
 		log.Println("ERROR: [TransactionHandler - GetTransactionsByConsumerId] Error while find transactions by consumer id:", parseError.Message)
 		return &pb.TransactionListResponse{
-			Code:    uint32(http.StatusInternalServerError),
+			Code:    uint32(commonErr.HttpStatusFromCode(parseError.Code)),
 			Message: parseError.Message,
 		}, status.Errorf(parseError.Code, parseError.Message)
 	}
@@ -104,87 +209,552 @@ func (th *TransactionHandler) GetTransactionsByConsumerId(ctx context.Context, r
 }
 
 func (th *TransactionHandler) CreateTransaction(ctx context.Context, req *pb.Transaction) (*pb.TransactionResponse, error) {
-	var wg sync.WaitGroup
-	errChan := make(chan error, 2)
-    transactionChan := make(chan *entity.Transaction, 1)
-	
+	if err := th.requestValidator.Validate(req); err != nil {
+		log.Println("WARNING: [TransactionHandler - CreateTransaction] Invalid request:", status.Convert(err).Message())
+		return &pb.TransactionResponse{
+			Code:    uint32(http.StatusBadRequest),
+			Message: status.Convert(err).Message(),
+		}, err
+	}
+
+	if err := th.consumerAccessGuard.AuthorizeConsumer(ctx, pb.TransactionService_CreateTransaction_FullMethodName, req.ConsumerId); err != nil {
+		return &pb.TransactionResponse{
+			Code:    uint32(http.StatusForbidden),
+			Message: "No permission to create transactions for this consumer",
+		}, err
+	}
+
+	key := idempotencyKeyFromContext(ctx)
+	if key == "" {
+		res, _, err := th.createTransaction(ctx, req, uuid.NewString())
+		return res, err
+	}
+
+	idempotencyKey, err := th.idempotencySvc.Begin(ctx, "CreateTransaction", key, req)
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("WARNING: [TransactionHandler - CreateTransaction] Idempotency key rejected:", parseError.Message)
+		return &pb.TransactionResponse{
+			Code:    uint32(commonErr.HttpStatusFromCode(parseError.Code)),
+			Message: parseError.Message,
+		}, status.Errorf(parseError.Code, parseError.Message)
+	}
+
+	if idempotencyKey.Status == entity.IdempotencyKeyStatusCompleted {
+		var res pb.TransactionResponse
+		if err := proto.Unmarshal(idempotencyKey.Response, &res); err != nil {
+			log.Println("ERROR: [TransactionHandler - CreateTransaction] Error while unmarshal stored response:", err)
+			return &pb.TransactionResponse{
+				Code:    uint32(http.StatusInternalServerError),
+				Message: "Failed to replay stored response",
+			}, status.Errorf(codes.Internal, "Failed to replay stored response")
+		}
+		return &res, nil
+	}
+
+	// a key taken over from an earlier request may have started a saga already
+	saga, err := th.createTransactionSaga.FindByReference(ctx, idempotencyKey.SagaReference)
+	if err == nil {
+		return th.sagaOutcome(context.WithoutCancel(ctx), idempotencyKey, saga)
+	}
+	if status.Code(err) != codes.NotFound {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [TransactionHandler - CreateTransaction] Error while find saga of idempotency key:", parseError.Message)
+		return &pb.TransactionResponse{
+			Code:    uint32(http.StatusInternalServerError),
+			Message: parseError.Message,
+		}, status.Errorf(parseError.Code, parseError.Message)
+	}
+
+	// the outcome is recorded even if the client has already given up waiting
+	res, saga, err := th.createTransaction(ctx, req, idempotencyKey.SagaReference)
+	if err != nil {
+		// a saga that is still running, or still rolling back, keeps the key
+		if saga == nil || saga.Status == entity.SagaStatusCompensated {
+			th.idempotencySvc.Release(context.WithoutCancel(ctx), idempotencyKey)
+		}
+		return res, err
+	}
+	if res.Code == http.StatusAccepted {
+		// a pending saga is not an outcome yet, keep the key reserved
+		return res, nil
+	}
+	if err := th.idempotencySvc.Complete(context.WithoutCancel(ctx), idempotencyKey, res); err != nil {
+		log.Println("ERROR: [TransactionHandler - CreateTransaction] Error while store response for idempotency key:", key)
+	}
+
+	return res, nil
+}
+
+// sagaOutcome answers a retry whose key already started saga: the stored
+// outcome once the saga is final, otherwise that the contract is pending.
+func (th *TransactionHandler) sagaOutcome(ctx context.Context, idempotencyKey *entity.IdempotencyKey, saga *entity.Saga) (*pb.TransactionResponse, error) {
+	switch saga.Status {
+	case entity.SagaStatusCompleted:
+		transaction, err := th.transactionSvc.FindById(ctx, saga.TransactionId)
+		if err != nil {
+			parseError := commonErr.ParseError(err)
+			log.Println("ERROR: [TransactionHandler - sagaOutcome] Error while find transaction of saga:", parseError.Message)
+			return &pb.TransactionResponse{
+				Code:    uint32(commonErr.HttpStatusFromCode(parseError.Code)),
+				Message: parseError.Message,
+			}, status.Errorf(parseError.Code, parseError.Message)
+		}
+
+		res := &pb.TransactionResponse{
+			Code:    uint32(http.StatusOK),
+			Message: "Success create transaction",
+			Data:    entity.ConvertEntityToProto(transaction),
+		}
+		if err := th.idempotencySvc.Complete(ctx, idempotencyKey, res); err != nil {
+			log.Println("ERROR: [TransactionHandler - sagaOutcome] Error while store response for idempotency key:", idempotencyKey.Key)
+		}
+		return res, nil
+
+	case entity.SagaStatusCompensated:
+		// nothing was booked, the next retry starts over
+		th.idempotencySvc.Release(ctx, idempotencyKey)
+		return &pb.TransactionResponse{
+			Code:    uint32(http.StatusConflict),
+			Message: "Transaction was not created: " + saga.LastError,
+		}, status.Errorf(codes.Aborted, "Transaction was not created: %s", saga.LastError)
+
+	case entity.SagaStatusCompensating:
+		return &pb.TransactionResponse{
+			Code:    uint32(http.StatusConflict),
+			Message: "Transaction is being rolled back, saga reference " + saga.Reference,
+		}, status.Errorf(codes.Aborted, "Transaction is being rolled back, saga reference %s", saga.Reference)
+	}
+
+	return pendingTransactionResponse(saga, nil), nil
+}
+
+// pendingTransactionResponse reports a contract the recovery worker will
+// still book, or compensate.
+func pendingTransactionResponse(saga *entity.Saga, transaction *entity.Transaction) *pb.TransactionResponse {
+	res := &pb.TransactionResponse{
+		Code:    uint32(http.StatusAccepted),
+		Message: fmt.Sprintf("Transaction is pending, saga reference %s", saga.Reference),
+	}
+	if transaction != nil {
+		res.Data = entity.ConvertEntityToProto(transaction)
+	}
+	return res
+}
+
+// createTransaction books the contract with a saga under sagaReference. The
+// saga is returned whenever one was started.
+func (th *TransactionHandler) createTransaction(ctx context.Context, req *pb.Transaction, sagaReference string) (*pb.TransactionResponse, *entity.Saga, error) {
+	ctx, span := tracing.StartSpan(ctx, "TransactionHandler - createTransaction")
+	defer span.End()
+
+	// derive pricing server-side before touching the consumer limit
+	quote, err := th.transactionSvc.Quote(ctx, req.ProductCode, 0, req.Otr, req.Tenor, req.AdminFee, req.Installment, req.Interest)
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("WARNING: [TransactionHandler - createTransaction] Pricing rejected for consumer id:", req.ConsumerId)
+		return &pb.TransactionResponse{
+			Code:    uint32(commonErr.HttpStatusFromCode(parseError.Code)),
+			Message: parseError.Message,
+		}, nil, err
+	}
+
 	// check limit available
 	consumerLimit, err := th.consumerLimitSvc.GetConsumerLimitByConsumerIdAndTenor(ctx, req.ConsumerId, req.Tenor)
 	if err != nil {
 		parseError := commonErr.ParseError(err)
-		log.Println("ERROR: [TransactionHandler - CreateTransaction] Error while get consumer limit by consumer id and tenor:", parseError.Message)
+		log.Println("ERROR: [TransactionHandler - createTransaction] Error while get consumer limit by consumer id and tenor:", parseError.Message)
 		return &pb.TransactionResponse{
 			Code:    uint32(http.StatusInternalServerError),
 			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
+		}, nil, status.Errorf(parseError.Code, parseError.Message)
 	}
 
 	if consumerLimit.Data.LimitAvailable < req.Otr {
-		log.Println("WARNING: [TransactionHandler - CreateTransaction] Limit available not enough for consumer id:", req.ConsumerId)
+		metrics.LimitRejected()
+		log.Println("WARNING: [TransactionHandler - createTransaction] Limit available not enough for consumer id:", req.ConsumerId)
 		return &pb.TransactionResponse{
 			Code:    uint32(http.StatusBadRequest),
 			Message: "Limit available not enough",
-		}, status.Errorf(codes.InvalidArgument, "Limit available not enough")
+		}, nil, status.Errorf(codes.InvalidArgument, "Limit available not enough")
+	}
+
+	// create the contract, debit the limit and activate the contract as one
+	// saga, pinned to the product version quoted above
+	saga, transaction, err := th.createTransactionSaga.Execute(ctx, sagaReference, req.ConsumerId, quote.ProductCode, quote.ProductVersion, req.Tenor, req.Otr, req.AdminFee, req.Installment, req.Interest, req.AssetName)
+	if errors.Is(err, service.ErrSagaPending) {
+		// the contract will still be booked, or compensated, by the recovery
+		// worker; failing here would invite a retry that books it twice
+		log.Println("WARNING: [TransactionHandler - createTransaction] Transaction pending for saga:", saga.Reference)
+		return pendingTransactionResponse(saga, transaction), saga, nil
+	}
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [TransactionHandler - createTransaction] Error while create transaction:", parseError.Message)
+		return &pb.TransactionResponse{
+			Code:    uint32(commonErr.HttpStatusFromCode(parseError.Code)),
+			Message: parseError.Message,
+		}, saga, status.Errorf(parseError.Code, parseError.Message)
 	}
 
-	// Run transaction creation and limit update concurrently
-    wg.Add(2)
+	return &pb.TransactionResponse{
+		Code:    uint32(http.StatusOK),
+		Message: "Success create transaction",
+		Data:    entity.ConvertEntityToProto(transaction),
+	}, saga, nil
+}
 
-	// Create transaction concurrently
-	go func() {
-		defer wg.Done()
+func (th *TransactionHandler) GetInstallmentSchedule(ctx context.Context, req *pb.TransactionContractNumberRequest) (*pb.InstallmentScheduleResponse, error) {
+	if err := th.requestValidator.Validate(req); err != nil {
+		log.Println("WARNING: [TransactionHandler - GetInstallmentSchedule] Invalid request:", status.Convert(err).Message())
+		return &pb.InstallmentScheduleResponse{
+			Code:    uint32(http.StatusBadRequest),
+			Message: status.Convert(err).Message(),
+		}, err
+	}
 
-		transaction, err := th.transactionSvc.Create(ctx, req.ConsumerId, req.Tenor, req.Otr, req.AdminFee, req.Installment, req.Interest, req.AssetName)
-		if err != nil {
-			parseError := commonErr.ParseError(err)
-			log.Println("ERROR: [TransactionHandler - CreateTransaction] Error while create transaction:", parseError.Message)
-			errChan <- err
-			return
+	transaction, err := th.transactionSvc.FindByContractNumber(ctx, req.ContractNumber)
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		if parseError.Code == codes.NotFound {
+			log.Println("WARNING: [TransactionHandler - GetInstallmentSchedule] Transaction not found for contract number:", req.ContractNumber)
+			return &pb.InstallmentScheduleResponse{
+				Code:    uint32(http.StatusNotFound),
+				Message: "Transaction not found",
+			}, status.Errorf(codes.NotFound, "Transaction not found")
 		}
-		transactionChan <- transaction
-	}()
+		log.Println("ERROR: [TransactionHandler - GetInstallmentSchedule] Error while find transaction by contract number:", parseError.Message)
+		return &pb.InstallmentScheduleResponse{
+			Code:    uint32(http.StatusInternalServerError),
+			Message: parseError.Message,
+		}, status.Errorf(parseError.Code, parseError.Message)
+	}
+
+	if err := th.consumerAccessGuard.AuthorizeTransaction(ctx, pb.TransactionService_GetInstallmentSchedule_FullMethodName, transaction); err != nil {
+		return &pb.InstallmentScheduleResponse{
+			Code:    uint32(http.StatusForbidden),
+			Message: "No permission to access this transaction",
+		}, err
+	}
+
+	installmentList, err := th.transactionSvc.FindInstallmentsByTransactionId(ctx, transaction.Id)
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [TransactionHandler - GetInstallmentSchedule] Error while find installment schedule:", parseError.Message)
+		return &pb.InstallmentScheduleResponse{
+			Code:    uint32(http.StatusInternalServerError),
+			Message: parseError.Message,
+		}, status.Errorf(parseError.Code, parseError.Message)
+	}
+
+	var installments []*pb.Installment
+	for _, i := range installmentList {
+		installments = append(installments, entity.ConvertInstallmentEntityToProto(i))
+	}
 
-	// Update limit available concurrently
-	go func() {
-		defer wg.Done()
+	return &pb.InstallmentScheduleResponse{
+		Code:    uint32(http.StatusOK),
+		Message: "Success get installment schedule",
+		Data:    installments,
+	}, nil
+}
 
-		_, err = th.consumerLimitSvc.UpdateAvailableLimit(ctx, req.ConsumerId, req.Tenor, req.Otr)
+func (th *TransactionHandler) RecordPayment(ctx context.Context, req *pb.RecordPaymentRequest) (*pb.PaymentResponse, error) {
+	if err := th.requestValidator.Validate(req); err != nil {
+		log.Println("WARNING: [TransactionHandler - RecordPayment] Invalid request:", status.Convert(err).Message())
+		return &pb.PaymentResponse{
+			Code:    uint32(http.StatusBadRequest),
+			Message: status.Convert(err).Message(),
+		}, err
+	}
+
+	paidAt := time.Now()
+	if req.PaidAt != "" {
+		parsed, err := time.Parse(time.RFC3339, req.PaidAt)
 		if err != nil {
-			parseError := commonErr.ParseError(err)
-			log.Println("ERROR: [TransactionHandler - CreateTransaction] Error while update available limit:", parseError.Message)
-			errChan <- err
-			return
+			log.Println("WARNING: [TransactionHandler - RecordPayment] Invalid paid at:", req.PaidAt)
+			return &pb.PaymentResponse{
+				Code:    uint32(http.StatusBadRequest),
+				Message: "paid_at must be an RFC3339 timestamp",
+			}, status.Errorf(codes.InvalidArgument, "paid_at must be an RFC3339 timestamp")
 		}
-	}()
-
-	wg.Wait()
-	close(errChan)
-	close(transactionChan)
-
-	if err := <-errChan; err != nil {
-		transaction := <-transactionChan
-		if transaction != nil {
-			rollbackErr := th.transactionSvc.Rollback(ctx, transaction.Id)
-			if rollbackErr != nil {
-				parseError := commonErr.ParseError(rollbackErr)
-				log.Println("ERROR: [TransactionHandler - CreateTransaction] Error while rollback transaction:", parseError.Message)
-				return &pb.TransactionResponse{
-					Code:    uint32(http.StatusInternalServerError),
-					Message: parseError.Message,
-				}, status.Errorf(parseError.Code, parseError.Message)
-			}
+		paidAt = parsed
+	}
+
+	if err := th.authorizeContract(ctx, pb.TransactionService_RecordPayment_FullMethodName, req.ContractNumber); err != nil {
+		parseError := commonErr.ParseError(err)
+		return &pb.PaymentResponse{
+			Code:    uint32(commonErr.HttpStatusFromCode(parseError.Code)),
+			Message: parseError.Message,
+		}, err
+	}
+
+	payment, duplicate, err := th.paymentSvc.RecordPayment(ctx, req.ContractNumber, req.Amount, req.PaymentReference, paidAt)
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [TransactionHandler - RecordPayment] Error while record payment:", parseError.Message)
+		return &pb.PaymentResponse{
+			Code:    uint32(commonErr.HttpStatusFromCode(parseError.Code)),
+			Message: parseError.Message,
+		}, status.Errorf(parseError.Code, parseError.Message)
+	}
+
+	message := "Success record payment"
+	if duplicate {
+		message = "Payment already recorded"
+	}
+
+	return &pb.PaymentResponse{
+		Code:    uint32(http.StatusOK),
+		Message: message,
+		Data:    entity.ConvertPaymentEntityToProto(payment),
+	}, nil
+}
+
+func (th *TransactionHandler) UpdateTransactionStatus(ctx context.Context, req *pb.UpdateTransactionStatusRequest) (*pb.TransactionResponse, error) {
+	if err := th.requestValidator.Validate(req); err != nil {
+		log.Println("WARNING: [TransactionHandler - UpdateTransactionStatus] Invalid request:", status.Convert(err).Message())
+		return &pb.TransactionResponse{
+			Code:    uint32(http.StatusBadRequest),
+			Message: status.Convert(err).Message(),
+		}, err
+	}
+
+	if err := th.authorizeContract(ctx, pb.TransactionService_UpdateTransactionStatus_FullMethodName, req.ContractNumber); err != nil {
+		parseError := commonErr.ParseError(err)
+		return &pb.TransactionResponse{
+			Code:    uint32(commonErr.HttpStatusFromCode(parseError.Code)),
+			Message: parseError.Message,
+		}, err
+	}
+
+	transaction, err := th.transactionSvc.UpdateStatus(ctx, req.ContractNumber, req.Status)
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [TransactionHandler - UpdateTransactionStatus] Error while update transaction status:", parseError.Message)
+		return &pb.TransactionResponse{
+			Code:    uint32(commonErr.HttpStatusFromCode(parseError.Code)),
+			Message: parseError.Message,
+		}, status.Errorf(parseError.Code, parseError.Message)
+	}
+
+	return &pb.TransactionResponse{
+		Code:    uint32(http.StatusOK),
+		Message: "Success update transaction status",
+		Data:    entity.ConvertEntityToProto(transaction),
+	}, nil
+}
+
+// authorizeContract loads a contract and checks that the caller may act on it.
+func (th *TransactionHandler) authorizeContract(ctx context.Context, method, contractNumber string) error {
+	transaction, err := th.transactionSvc.FindByContractNumber(ctx, contractNumber)
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		if parseError.Code == codes.NotFound {
+			log.Println("WARNING: [TransactionHandler - authorizeContract] Transaction not found for contract number:", contractNumber)
+			return status.Errorf(codes.NotFound, "Transaction not found")
 		}
+		log.Println("ERROR: [TransactionHandler - authorizeContract] Error while find transaction by contract number:", parseError.Message)
+		return err
+	}
+
+	if err := th.consumerAccessGuard.AuthorizeTransaction(ctx, method, transaction); err != nil {
+		return status.Errorf(codes.PermissionDenied, "No permission to access this transaction")
+	}
+	return nil
+}
+
+func idempotencyKeyFromContext(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(idempotencyKeyHeader); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+func (th *TransactionHandler) GetTransactionHistory(ctx context.Context, req *pb.TransactionContractNumberRequest) (*pb.TransactionHistoryResponse, error) {
+	if err := th.requestValidator.Validate(req); err != nil {
+		log.Println("WARNING: [TransactionHandler - GetTransactionHistory] Invalid request:", status.Convert(err).Message())
+		return &pb.TransactionHistoryResponse{
+			Code:    uint32(http.StatusBadRequest),
+			Message: status.Convert(err).Message(),
+		}, err
+	}
+
+	eventList, err := th.transactionSvc.FindHistoryByContractNumber(ctx, req.ContractNumber)
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [TransactionHandler - GetTransactionHistory] Error while find transaction history:", parseError.Message)
+		return &pb.TransactionHistoryResponse{
+			Code:    uint32(commonErr.HttpStatusFromCode(parseError.Code)),
+			Message: parseError.Message,
+		}, status.Errorf(parseError.Code, parseError.Message)
+	}
+
+	// the history outlives a rolled back contract, so ownership is read from it
+	if err := th.consumerAccessGuard.AuthorizeTransaction(ctx, pb.TransactionService_GetTransactionHistory_FullMethodName, entity.TransactionFromHistory(eventList)); err != nil {
+		return &pb.TransactionHistoryResponse{
+			Code:    uint32(http.StatusForbidden),
+			Message: "No permission to access this transaction",
+		}, err
+	}
+
+	var events []*pb.TransactionEvent
+	for _, e := range eventList {
+		events = append(events, entity.ConvertTransactionEventEntityToProto(e))
+	}
+
+	return &pb.TransactionHistoryResponse{
+		Code:    uint32(http.StatusOK),
+		Message: "Success get transaction history",
+		Data:    events,
+	}, nil
+}
+
+func (th *TransactionHandler) ListMyTransactions(ctx context.Context, req *pb.ListMyTransactionsRequest) (*pb.TransactionListResponse, error) {
+	if err := th.requestValidator.Validate(req); err != nil {
+		log.Println("WARNING: [TransactionHandler - ListMyTransactions] Invalid request:", status.Convert(err).Message())
+		return &pb.TransactionListResponse{
+			Code:    uint32(http.StatusBadRequest),
+			Message: status.Convert(err).Message(),
+		}, err
+	}
+
+	consumerId, err := consumerIdFromContext(ctx)
+	if err != nil {
+		log.Println("WARNING: [TransactionHandler - ListMyTransactions] Token is not bound to a consumer")
+		return &pb.TransactionListResponse{
+			Code:    uint32(http.StatusForbidden),
+			Message: "Token is not bound to a consumer",
+		}, err
+	}
+
+	transactionList, err := th.transactionSvc.FindByConsumerId(ctx, consumerId, &entity.TransactionFilter{Status: req.Status})
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [TransactionHandler - ListMyTransactions] Error while find transactions by consumer id:", parseError.Message)
+		return &pb.TransactionListResponse{
+			Code:    uint32(commonErr.HttpStatusFromCode(parseError.Code)),
+			Message: parseError.Message,
+		}, status.Errorf(parseError.Code, parseError.Message)
+	}
+
+	var transactions []*pb.Transaction
+	for _, t := range transactionList {
+		transactions = append(transactions, entity.ConvertEntityToProto(t))
+	}
+
+	return &pb.TransactionListResponse{
+		Code:    uint32(http.StatusOK),
+		Message: "Success get my transactions",
+		Data:    transactions,
+	}, nil
+}
+
+func (th *TransactionHandler) GetMyTransaction(ctx context.Context, req *pb.TransactionContractNumberRequest) (*pb.TransactionResponse, error) {
+	if err := th.requestValidator.Validate(req); err != nil {
+		log.Println("WARNING: [TransactionHandler - GetMyTransaction] Invalid request:", status.Convert(err).Message())
 		return &pb.TransactionResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: "Error while create transaction",
-		}, status.Errorf(codes.Internal, "Error while create transaction")
+			Code:    uint32(http.StatusBadRequest),
+			Message: status.Convert(err).Message(),
+		}, err
+	}
+
+	consumerId, err := consumerIdFromContext(ctx)
+	if err != nil {
+		log.Println("WARNING: [TransactionHandler - GetMyTransaction] Token is not bound to a consumer")
+		return &pb.TransactionResponse{
+			Code:    uint32(http.StatusForbidden),
+			Message: "Token is not bound to a consumer",
+		}, err
 	}
 
-	transaction := <-transactionChan
+	transaction, err := th.transactionSvc.FindByContractNumber(ctx, req.ContractNumber)
+	// another consumer's contract is reported as missing so contract numbers cannot be probed
+	if err == nil && transaction.ConsumerId != consumerId {
+		log.Printf("WARNING: [TransactionHandler - GetMyTransaction] Consumer %d requested contract number %s of another consumer\n", consumerId, req.ContractNumber)
+		err = status.Errorf(codes.NotFound, "Transaction not found")
+	}
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [TransactionHandler - GetMyTransaction] Error while find transaction by contract number:", parseError.Message)
+		return &pb.TransactionResponse{
+			Code:    uint32(commonErr.HttpStatusFromCode(parseError.Code)),
+			Message: parseError.Message,
+		}, status.Errorf(parseError.Code, parseError.Message)
+	}
 
 	return &pb.TransactionResponse{
 		Code:    uint32(http.StatusOK),
-		Message: "Success create transaction",
+		Message: "Success get my transaction",
 		Data:    entity.ConvertEntityToProto(transaction),
 	}, nil
 }
+
+func (th *TransactionHandler) CreateMyTransaction(ctx context.Context, req *pb.CreateMyTransactionRequest) (*pb.TransactionResponse, error) {
+	if err := th.requestValidator.Validate(req); err != nil {
+		log.Println("WARNING: [TransactionHandler - CreateMyTransaction] Invalid request:", status.Convert(err).Message())
+		return &pb.TransactionResponse{
+			Code:    uint32(http.StatusBadRequest),
+			Message: status.Convert(err).Message(),
+		}, err
+	}
+
+	consumerId, err := consumerIdFromContext(ctx)
+	if err != nil {
+		log.Println("WARNING: [TransactionHandler - CreateMyTransaction] Token is not bound to a consumer")
+		return &pb.TransactionResponse{
+			Code:    uint32(http.StatusForbidden),
+			Message: "Token is not bound to a consumer",
+		}, err
+	}
+
+	return th.CreateTransaction(ctx, &pb.Transaction{
+		ConsumerId:  consumerId,
+		Tenor:       req.Tenor,
+		Otr:         req.Otr,
+		AdminFee:    req.AdminFee,
+		Installment: req.Installment,
+		Interest:    req.Interest,
+		AssetName:   req.AssetName,
+		ProductCode: req.ProductCode,
+	})
+}
+
+// consumerIdFromContext returns the consumer bound to the caller token.
+func consumerIdFromContext(ctx context.Context) (uint64, error) {
+	claims, ok := commonJwt.FromContext(ctx)
+	if !ok || claims.ConsumerId == 0 {
+		return 0, status.Errorf(codes.PermissionDenied, "token is not bound to a consumer")
+	}
+	return claims.ConsumerId, nil
+}
+
+func transactionFilterFromProto(f *pb.TransactionFilter) (*entity.TransactionFilter, error) {
+	filter := &entity.TransactionFilter{
+		Status:     f.GetStatus(),
+		ConsumerId: f.GetConsumerId(),
+		Tenor:      f.GetTenor(),
+		OtrMin:     f.GetOtrMin(),
+		OtrMax:     f.GetOtrMax(),
+		AssetName:  f.GetAssetName(),
+	}
+
+	if f.GetCreatedFrom() != "" {
+		createdFrom, err := time.Parse(time.RFC3339, f.GetCreatedFrom())
+		if err != nil {
+			return nil, fmt.Errorf("created_from must be an RFC3339 timestamp")
+		}
+		filter.CreatedFrom = createdFrom
+	}
+	if f.GetCreatedTo() != "" {
+		createdTo, err := time.Parse(time.RFC3339, f.GetCreatedTo())
+		if err != nil {
+			return nil, fmt.Errorf("created_to must be an RFC3339 timestamp")
+		}
+		filter.CreatedTo = createdTo
+	}
+
+	return filter, nil
+}
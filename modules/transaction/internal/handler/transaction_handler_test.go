@@ -0,0 +1,243 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+	"xyz-transaction-service/common/config"
+	"xyz-transaction-service/modules/transaction/client"
+	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/modules/transaction/internal/handler"
+	"xyz-transaction-service/modules/transaction/internal/pricing"
+	"xyz-transaction-service/pb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Mock for TransactionServiceUseCase
+type MockTransactionService struct {
+	mock.Mock
+}
+
+func (m *MockTransactionService) FindAll(ctx context.Context, filter *entity.TransactionFilter, pageSize uint32, pageToken string) ([]*entity.Transaction, string, error) {
+	args := m.Called(ctx, filter, pageSize, pageToken)
+	transactions, _ := args.Get(0).([]*entity.Transaction)
+	return transactions, args.String(1), args.Error(2)
+}
+
+func (m *MockTransactionService) Stream(ctx context.Context, filter *entity.TransactionFilter, batchSize uint32, send func(*entity.Transaction) error) error {
+	return m.Called(ctx, filter, batchSize, send).Error(0)
+}
+
+func (m *MockTransactionService) FindByConsumerId(ctx context.Context, consumerId uint64, filter *entity.TransactionFilter) ([]*entity.Transaction, error) {
+	args := m.Called(ctx, consumerId, filter)
+	transactions, _ := args.Get(0).([]*entity.Transaction)
+	return transactions, args.Error(1)
+}
+
+func (m *MockTransactionService) FindById(ctx context.Context, id uint64) (*entity.Transaction, error) {
+	args := m.Called(ctx, id)
+	transaction, _ := args.Get(0).(*entity.Transaction)
+	return transaction, args.Error(1)
+}
+
+func (m *MockTransactionService) FindByContractNumber(ctx context.Context, contractNumber string) (*entity.Transaction, error) {
+	args := m.Called(ctx, contractNumber)
+	transaction, _ := args.Get(0).(*entity.Transaction)
+	return transaction, args.Error(1)
+}
+
+func (m *MockTransactionService) Quote(ctx context.Context, productCode string, productVersion uint32, otr uint64, tenor uint32, adminFee, installment, interest uint64) (*pricing.Quote, error) {
+	args := m.Called(ctx, productCode, productVersion, otr, tenor, adminFee, installment, interest)
+	quote, _ := args.Get(0).(*pricing.Quote)
+	return quote, args.Error(1)
+}
+
+func (m *MockTransactionService) GenerateContractNumber(ctx context.Context, consumerId uint64) (string, error) {
+	args := m.Called(ctx, consumerId)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTransactionService) ValidateContractNumber(contractNumber string) error {
+	return m.Called(contractNumber).Error(0)
+}
+
+func (m *MockTransactionService) Create(ctx context.Context, contractNumber string, consumerId uint64, productCode string, productVersion uint32, tenor uint32, otr, adminFee, installment, interest uint64, assetName string) (*entity.Transaction, error) {
+	args := m.Called(ctx, contractNumber, consumerId, productCode, productVersion, tenor, otr, adminFee, installment, interest, assetName)
+	transaction, _ := args.Get(0).(*entity.Transaction)
+	return transaction, args.Error(1)
+}
+
+func (m *MockTransactionService) UpdateStatus(ctx context.Context, contractNumber string, newStatus string) (*entity.Transaction, error) {
+	args := m.Called(ctx, contractNumber, newStatus)
+	transaction, _ := args.Get(0).(*entity.Transaction)
+	return transaction, args.Error(1)
+}
+
+func (m *MockTransactionService) Rollback(ctx context.Context, id uint64, reason string) error {
+	return m.Called(ctx, id, reason).Error(0)
+}
+
+func (m *MockTransactionService) FindInstallmentsByTransactionId(ctx context.Context, transactionId uint64) ([]*entity.Installment, error) {
+	args := m.Called(ctx, transactionId)
+	installments, _ := args.Get(0).([]*entity.Installment)
+	return installments, args.Error(1)
+}
+
+func (m *MockTransactionService) FindHistoryByContractNumber(ctx context.Context, contractNumber string) ([]*entity.TransactionEvent, error) {
+	args := m.Called(ctx, contractNumber)
+	events, _ := args.Get(0).([]*entity.TransactionEvent)
+	return events, args.Error(1)
+}
+
+// Mock for PaymentServiceUseCase
+type MockPaymentService struct {
+	mock.Mock
+}
+
+func (m *MockPaymentService) RecordPayment(ctx context.Context, contractNumber string, amount uint64, paymentReference string, paidAt time.Time) (*entity.Payment, bool, error) {
+	args := m.Called(ctx, contractNumber, amount, paymentReference, paidAt)
+	payment, _ := args.Get(0).(*entity.Payment)
+	return payment, args.Bool(1), args.Error(2)
+}
+
+// Mock for ConsumerAccessGuardUseCase
+type MockConsumerAccessGuard struct {
+	mock.Mock
+}
+
+func (m *MockConsumerAccessGuard) AuthorizeConsumer(ctx context.Context, method string, consumerId uint64) error {
+	return m.Called(ctx, method, consumerId).Error(0)
+}
+
+func (m *MockConsumerAccessGuard) AuthorizeTransaction(ctx context.Context, method string, transaction *entity.Transaction) error {
+	return m.Called(ctx, method, transaction).Error(0)
+}
+
+// acceptAll stands in for the request validator
+type acceptAll struct{}
+
+func (acceptAll) Validate(proto.Message) error {
+	return nil
+}
+
+var errDenied = status.Error(codes.PermissionDenied, "no permission to access transactions of consumer 7")
+
+func newTransactionHandler(transactionSvc *MockTransactionService, paymentSvc *MockPaymentService, guard *MockConsumerAccessGuard) *handler.TransactionHandler {
+	return handler.NewTransactionHandler(config.Config{}, transactionSvc, paymentSvc, nil, nil, guard, client.ConsumerLimitServiceClient{}, acceptAll{}, nil)
+}
+
+func consumerOf(consumerId uint64) interface{} {
+	return mock.MatchedBy(func(t *entity.Transaction) bool { return t.ConsumerId == consumerId })
+}
+
+func TestRecordPaymentDeniesOtherConsumer(t *testing.T) {
+	transactionSvc := new(MockTransactionService)
+	paymentSvc := new(MockPaymentService)
+	guard := new(MockConsumerAccessGuard)
+
+	transactionSvc.On("FindByContractNumber", mock.Anything, "CN123").Return(&entity.Transaction{Id: 1, ContractNumber: "CN123", ConsumerId: 7}, nil)
+	guard.On("AuthorizeTransaction", mock.Anything, pb.TransactionService_RecordPayment_FullMethodName, consumerOf(7)).Return(errDenied)
+
+	res, err := newTransactionHandler(transactionSvc, paymentSvc, guard).RecordPayment(context.Background(), &pb.RecordPaymentRequest{ContractNumber: "CN123", Amount: 1000, PaymentReference: "PAY-1"})
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, uint32(http.StatusForbidden), res.Code)
+	paymentSvc.AssertNotCalled(t, "RecordPayment", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRecordPaymentAllowsOwner(t *testing.T) {
+	transactionSvc := new(MockTransactionService)
+	paymentSvc := new(MockPaymentService)
+	guard := new(MockConsumerAccessGuard)
+
+	transactionSvc.On("FindByContractNumber", mock.Anything, "CN123").Return(&entity.Transaction{Id: 1, ContractNumber: "CN123", ConsumerId: 7}, nil)
+	guard.On("AuthorizeTransaction", mock.Anything, pb.TransactionService_RecordPayment_FullMethodName, consumerOf(7)).Return(nil)
+	paymentSvc.On("RecordPayment", mock.Anything, "CN123", uint64(1000), "PAY-1", mock.Anything).Return(&entity.Payment{Id: 3, ContractNumber: "CN123", Amount: 1000}, false, nil)
+
+	res, err := newTransactionHandler(transactionSvc, paymentSvc, guard).RecordPayment(context.Background(), &pb.RecordPaymentRequest{ContractNumber: "CN123", Amount: 1000, PaymentReference: "PAY-1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(http.StatusOK), res.Code)
+	paymentSvc.AssertExpectations(t)
+}
+
+func TestRecordPaymentUnknownContract(t *testing.T) {
+	transactionSvc := new(MockTransactionService)
+	paymentSvc := new(MockPaymentService)
+
+	transactionSvc.On("FindByContractNumber", mock.Anything, "CN404").Return(nil, status.Error(codes.NotFound, "not found"))
+
+	res, err := newTransactionHandler(transactionSvc, paymentSvc, new(MockConsumerAccessGuard)).RecordPayment(context.Background(), &pb.RecordPaymentRequest{ContractNumber: "CN404", Amount: 1000, PaymentReference: "PAY-1"})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, uint32(http.StatusNotFound), res.Code)
+}
+
+func TestUpdateTransactionStatusDeniesOtherConsumer(t *testing.T) {
+	transactionSvc := new(MockTransactionService)
+	guard := new(MockConsumerAccessGuard)
+
+	transactionSvc.On("FindByContractNumber", mock.Anything, "CN123").Return(&entity.Transaction{Id: 1, ContractNumber: "CN123", ConsumerId: 7}, nil)
+	guard.On("AuthorizeTransaction", mock.Anything, pb.TransactionService_UpdateTransactionStatus_FullMethodName, consumerOf(7)).Return(errDenied)
+
+	res, err := newTransactionHandler(transactionSvc, new(MockPaymentService), guard).UpdateTransactionStatus(context.Background(), &pb.UpdateTransactionStatusRequest{ContractNumber: "CN123", Status: entity.TransactionStatusCancelled})
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, uint32(http.StatusForbidden), res.Code)
+	transactionSvc.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateTransactionStatusAllowsOwner(t *testing.T) {
+	transactionSvc := new(MockTransactionService)
+	guard := new(MockConsumerAccessGuard)
+
+	transactionSvc.On("FindByContractNumber", mock.Anything, "CN123").Return(&entity.Transaction{Id: 1, ContractNumber: "CN123", ConsumerId: 7}, nil)
+	guard.On("AuthorizeTransaction", mock.Anything, pb.TransactionService_UpdateTransactionStatus_FullMethodName, consumerOf(7)).Return(nil)
+	transactionSvc.On("UpdateStatus", mock.Anything, "CN123", entity.TransactionStatusCancelled).Return(&entity.Transaction{Id: 1, ContractNumber: "CN123", ConsumerId: 7, Status: entity.TransactionStatusCancelled}, nil)
+
+	res, err := newTransactionHandler(transactionSvc, new(MockPaymentService), guard).UpdateTransactionStatus(context.Background(), &pb.UpdateTransactionStatusRequest{ContractNumber: "CN123", Status: entity.TransactionStatusCancelled})
+
+	assert.NoError(t, err)
+	assert.Equal(t, entity.TransactionStatusCancelled, res.Data.Status)
+}
+
+func TestGetTransactionHistoryDeniesOtherConsumer(t *testing.T) {
+	transactionSvc := new(MockTransactionService)
+	guard := new(MockConsumerAccessGuard)
+
+	snapshot, _ := entity.TransactionSnapshot(&entity.Transaction{Id: 1, ContractNumber: "CN123", ConsumerId: 7})
+	transactionSvc.On("FindHistoryByContractNumber", mock.Anything, "CN123").Return([]*entity.TransactionEvent{
+		{Id: 1, ContractNumber: "CN123", EventType: entity.TransactionEventCreated, After: snapshot},
+	}, nil)
+	guard.On("AuthorizeTransaction", mock.Anything, pb.TransactionService_GetTransactionHistory_FullMethodName, consumerOf(7)).Return(errDenied)
+
+	res, err := newTransactionHandler(transactionSvc, new(MockPaymentService), guard).GetTransactionHistory(context.Background(), &pb.TransactionContractNumberRequest{ContractNumber: "CN123"})
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, uint32(http.StatusForbidden), res.Code)
+	assert.Empty(t, res.Data)
+}
+
+func TestGetTransactionHistoryOfRolledBackContract(t *testing.T) {
+	transactionSvc := new(MockTransactionService)
+	guard := new(MockConsumerAccessGuard)
+
+	snapshot, _ := entity.TransactionSnapshot(&entity.Transaction{Id: 1, ContractNumber: "CN123", ConsumerId: 7})
+	transactionSvc.On("FindHistoryByContractNumber", mock.Anything, "CN123").Return([]*entity.TransactionEvent{
+		{Id: 1, ContractNumber: "CN123", EventType: entity.TransactionEventCreated, After: snapshot},
+		{Id: 2, ContractNumber: "CN123", EventType: entity.TransactionEventRolledBack, Before: snapshot},
+	}, nil)
+	guard.On("AuthorizeTransaction", mock.Anything, pb.TransactionService_GetTransactionHistory_FullMethodName, consumerOf(7)).Return(nil)
+
+	res, err := newTransactionHandler(transactionSvc, new(MockPaymentService), guard).GetTransactionHistory(context.Background(), &pb.TransactionContractNumberRequest{ContractNumber: "CN123"})
+
+	assert.NoError(t, err)
+	assert.Len(t, res.Data, 2)
+	transactionSvc.AssertNotCalled(t, "FindByContractNumber", mock.Anything, mock.Anything)
+}
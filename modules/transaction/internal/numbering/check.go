@@ -0,0 +1,24 @@
+package numbering
+
+import "fmt"
+
+// CheckDigits returns the two ISO 7064 MOD 97-10 check digits of s, the
+// scheme of IBAN. Letters count as 10 to 35 regardless of case and any other
+// character is skipped, so separators do not change the result. A character
+// mistyped as another of its kind, digit or letter, always changes the
+// digits, as do nearly all other typos and swapped neighbours.
+func CheckDigits(s string) string {
+	remainder := 0
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			remainder = (remainder*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			remainder = (remainder*100 + int(c-'A'+10)) % 97
+		case c >= 'a' && c <= 'z':
+			remainder = (remainder*100 + int(c-'a'+10)) % 97
+		}
+	}
+	remainder = remainder * 100 % 97
+	return fmt.Sprintf("%02d", 98-remainder)
+}
@@ -0,0 +1,208 @@
+package numbering
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"xyz-transaction-service/common/config"
+	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/modules/transaction/internal/repository"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	StrategyLegacy   = "legacy"
+	StrategySequence = "sequence"
+	StrategyOpaque   = "opaque"
+
+	DefaultLegacyTemplate   = "CNTR-{date}-{consumer}-{unique}"
+	DefaultSequenceTemplate = "CNTR-{date}-{unique}-{check}"
+	DefaultOpaqueTemplate   = "CN-{unique}{check}"
+
+	// crockford base32, without I, L, O and U
+	opaqueAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	opaqueLength   = 10
+)
+
+// ContractNumberGenerator issues contract numbers and recognises the numbers
+// it issues. Generated numbers are unique with high probability only; the
+// unique key of transactions decides, and callers regenerate on a collision.
+type ContractNumberGenerator interface {
+	Generate(ctx context.Context, consumerId uint64) (string, error)
+	Validate(contractNumber string) error
+}
+
+// NewContractNumberGenerator builds the configured strategy. A template the
+// strategy cannot use is logged and replaced by the strategy default, and an
+// unknown strategy falls back to legacy. The returned generator only checks
+// the shape of a number, see ValidateShape, so contracts issued under any
+// earlier strategy or template can still be looked up.
+func NewContractNumberGenerator(cfg config.ContractNumber, sequenceRepository repository.ContractNumberSequenceRepositoryUseCase) ContractNumberGenerator {
+	var generator ContractNumberGenerator
+	var err error
+	switch strings.ToLower(strings.TrimSpace(cfg.Strategy)) {
+	case StrategySequence:
+		if generator, err = NewSequenceGenerator(cfg.Template, sequenceRepository); err != nil {
+			log.Println("ERROR: [ContractNumberGenerator] Falling back to the default template:", err)
+			generator, _ = NewSequenceGenerator("", sequenceRepository)
+		}
+	case StrategyOpaque:
+		if generator, err = NewOpaqueGenerator(cfg.Template); err != nil {
+			log.Println("ERROR: [ContractNumberGenerator] Falling back to the default template:", err)
+			generator, _ = NewOpaqueGenerator("")
+		}
+	default:
+		if generator, err = NewLegacyGenerator(cfg.Template); err != nil {
+			log.Println("ERROR: [ContractNumberGenerator] Falling back to the default template:", err)
+			generator, _ = NewLegacyGenerator("")
+		}
+	}
+
+	return &shapeValidatingGenerator{ContractNumberGenerator: generator}
+}
+
+// MaxAttempts is the number of contract numbers tried for one contract.
+func MaxAttempts(cfg config.ContractNumber) int {
+	if cfg.MaxAttempts < 1 {
+		return 1
+	}
+	return cfg.MaxAttempts
+}
+
+// ValidateShape checks that contractNumber fits the contract_number column and
+// only uses the characters a template may produce.
+func ValidateShape(contractNumber string) error {
+	if contractNumber == "" || len(contractNumber) > maxLength {
+		return malformed(contractNumber)
+	}
+	for _, c := range contractNumber {
+		if !isLiteral(c) {
+			return malformed(contractNumber)
+		}
+	}
+	return nil
+}
+
+func malformed(contractNumber string) error {
+	return status.Errorf(codes.InvalidArgument, "malformed contract number: %q", contractNumber)
+}
+
+// LegacyGenerator fills {unique} with 8 hex digits of a random uuid. Its
+// default template is the original CNTR-YYYYMMDD-<consumer>-<hex> layout.
+type LegacyGenerator struct {
+	template *Template
+}
+
+func NewLegacyGenerator(template string) (*LegacyGenerator, error) {
+	if template == "" {
+		template = DefaultLegacyTemplate
+	}
+	t, err := ParseTemplate(template, `[0-9a-f]{8}`)
+	if err != nil {
+		return nil, err
+	}
+	return &LegacyGenerator{template: t}, nil
+}
+
+func (g *LegacyGenerator) Generate(ctx context.Context, consumerId uint64) (string, error) {
+	return g.template.Render(time.Now(), consumerId, uuid.NewString()[:8]), nil
+}
+
+func (g *LegacyGenerator) Validate(contractNumber string) error {
+	if !g.template.Matches(contractNumber) {
+		return malformed(contractNumber)
+	}
+	return nil
+}
+
+// SequenceGenerator fills {unique} with a counter kept per day in
+// contract_number_sequences, zero padded to 6 digits. The counter restarts
+// every day, so the template must carry {date}.
+type SequenceGenerator struct {
+	template           *Template
+	sequenceRepository repository.ContractNumberSequenceRepositoryUseCase
+}
+
+func NewSequenceGenerator(template string, sequenceRepository repository.ContractNumberSequenceRepositoryUseCase) (*SequenceGenerator, error) {
+	if template == "" {
+		template = DefaultSequenceTemplate
+	}
+	t, err := ParseTemplate(template, `\d{6,20}`)
+	if err != nil {
+		return nil, err
+	}
+	if !t.Has(TokenDate) {
+		return nil, fmt.Errorf("contract number template %q: the sequence strategy requires {date}", template)
+	}
+	return &SequenceGenerator{template: t, sequenceRepository: sequenceRepository}, nil
+}
+
+func (g *SequenceGenerator) Generate(ctx context.Context, consumerId uint64) (string, error) {
+	now := time.Now()
+	sequence, err := g.sequenceRepository.Next(ctx, now.Format(entity.ContractNumberSequenceDayLayout))
+	if err != nil {
+		return "", err
+	}
+	return g.template.Render(now, consumerId, fmt.Sprintf("%06d", sequence)), nil
+}
+
+func (g *SequenceGenerator) Validate(contractNumber string) error {
+	if !g.template.Matches(contractNumber) {
+		return malformed(contractNumber)
+	}
+	return nil
+}
+
+// OpaqueGenerator fills {unique} with 10 random crockford base32 characters,
+// 50 bits that reveal neither the consumer nor the issue order.
+type OpaqueGenerator struct {
+	template *Template
+}
+
+func NewOpaqueGenerator(template string) (*OpaqueGenerator, error) {
+	if template == "" {
+		template = DefaultOpaqueTemplate
+	}
+	t, err := ParseTemplate(template, fmt.Sprintf("[%s]{%d}", opaqueAlphabet, opaqueLength))
+	if err != nil {
+		return nil, err
+	}
+	return &OpaqueGenerator{template: t}, nil
+}
+
+func (g *OpaqueGenerator) Generate(ctx context.Context, consumerId uint64) (string, error) {
+	random := make([]byte, opaqueLength)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	// 256 is a multiple of 32, so masking keeps every character equally likely
+	for i, b := range random {
+		random[i] = opaqueAlphabet[b&31]
+	}
+	return g.template.Render(time.Now(), consumerId, string(random)), nil
+}
+
+func (g *OpaqueGenerator) Validate(contractNumber string) error {
+	if !g.template.Matches(contractNumber) {
+		return malformed(contractNumber)
+	}
+	return nil
+}
+
+// shapeValidatingGenerator issues numbers with the configured layout but
+// accepts every number of a plausible shape. The layout may have changed since
+// a contract was issued, and the database answers NotFound for a number it
+// never issued.
+type shapeValidatingGenerator struct {
+	ContractNumberGenerator
+}
+
+func (g *shapeValidatingGenerator) Validate(contractNumber string) error {
+	return ValidateShape(contractNumber)
+}
@@ -0,0 +1,141 @@
+package numbering_test
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"xyz-transaction-service/common/config"
+	"xyz-transaction-service/modules/transaction/internal/numbering"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeSequenceRepository struct {
+	days map[string]uint64
+}
+
+func (f *fakeSequenceRepository) Next(ctx context.Context, day string) (uint64, error) {
+	f.days[day]++
+	return f.days[day], nil
+}
+
+func TestCheckDigits(t *testing.T) {
+	// the IBAN of the ISO 13616 example, GB82 WEST 1234 5698 7654 32
+	assert.Equal(t, "82", numbering.CheckDigits("WEST12345698765432GB"))
+	assert.Equal(t, numbering.CheckDigits("CN-ABC"), numbering.CheckDigits("cnabc"))
+}
+
+func TestParseTemplate(t *testing.T) {
+	for _, raw := range []string{
+		"CNTR-{date}",
+		"CNTR-{unique}-{unique}",
+		"CNTR-{unique}-{serial}",
+		"CNTR-{check}-{unique}",
+		"CNTR-{unique",
+		"CNTR}-{unique}",
+		"CNTR {unique}",
+	} {
+		_, err := numbering.ParseTemplate(raw, `\d+`)
+		assert.Error(t, err, raw)
+	}
+
+	template, err := numbering.ParseTemplate("X{date}/{consumer}/{unique}{check}", `\d{3}`)
+	assert.NoError(t, err)
+
+	contractNumber := template.Render(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), 42, "007")
+	assert.Equal(t, "X20240102/00000042/007"+numbering.CheckDigits("X20240102/00000042/007"), contractNumber)
+	assert.True(t, template.Matches(contractNumber))
+}
+
+func TestLegacyGeneratorKeepsOriginalLayout(t *testing.T) {
+	generator := numbering.NewContractNumberGenerator(config.ContractNumber{}, nil)
+
+	contractNumber, err := generator.Generate(context.Background(), 123)
+
+	assert.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^CNTR-\d{8}-00000123-[0-9a-f]{8}$`), contractNumber)
+	assert.NoError(t, generator.Validate(contractNumber))
+}
+
+func TestSequenceGenerator(t *testing.T) {
+	cfg := config.ContractNumber{Strategy: numbering.StrategySequence}
+	generator := numbering.NewContractNumberGenerator(cfg, &fakeSequenceRepository{days: map[string]uint64{}})
+
+	first, err := generator.Generate(context.Background(), 123)
+	assert.NoError(t, err)
+	second, err := generator.Generate(context.Background(), 123)
+	assert.NoError(t, err)
+
+	day := time.Now().Format("20060102")
+	assert.Regexp(t, regexp.MustCompile(`^CNTR-`+day+`-000001-\d{2}$`), first)
+	assert.Regexp(t, regexp.MustCompile(`^CNTR-`+day+`-000002-\d{2}$`), second)
+	assert.NotContains(t, first, "123")
+	assert.NoError(t, generator.Validate(first))
+
+	_, err = numbering.NewSequenceGenerator("CN-{unique}", nil)
+	assert.Error(t, err)
+}
+
+func TestOpaqueGeneratorValidate(t *testing.T) {
+	generator, err := numbering.NewOpaqueGenerator("")
+	assert.NoError(t, err)
+
+	contractNumber, err := generator.Generate(context.Background(), 123)
+	assert.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^CN-[0-9A-HJKMNP-TV-Z]{10}\d{2}$`), contractNumber)
+	assert.NoError(t, generator.Validate(contractNumber))
+
+	// a mistyped character or swapped neighbours fail the check digits
+	valid := "CN-7K3Q9M2XAB" + numbering.CheckDigits("CN-7K3Q9M2XAB")
+	assert.NoError(t, generator.Validate(valid))
+	for _, malformed := range []string{
+		"CN-7X3Q9M2XAB" + valid[13:],
+		"CN-7K3Q8M2XAB" + valid[13:],
+		"CN-K73Q9M2XAB" + valid[13:],
+		"",
+		"CN-123",
+		"'; DROP TABLE transactions; --",
+	} {
+		assert.Equal(t, codes.InvalidArgument, status.Code(generator.Validate(malformed)), malformed)
+	}
+}
+
+func TestContractNumberGeneratorAcceptsEarlierLayouts(t *testing.T) {
+	sequence := config.ContractNumber{Strategy: numbering.StrategySequence}
+	issued, err := numbering.NewContractNumberGenerator(sequence, &fakeSequenceRepository{days: map[string]uint64{}}).Generate(context.Background(), 123)
+	assert.NoError(t, err)
+
+	// the operator switched from sequence to an opaque custom template
+	generator := numbering.NewContractNumberGenerator(config.ContractNumber{Strategy: numbering.StrategyOpaque, Template: "XYZ/{unique}{check}"}, nil)
+
+	for _, contractNumber := range []string{
+		issued,
+		"CNTR-20240101-00000123-0a1b2c3d",
+		"CN-7K3Q9M2XAB00",
+	} {
+		assert.NoError(t, generator.Validate(contractNumber), contractNumber)
+	}
+
+	for _, malformed := range []string{
+		"",
+		"CN 123",
+		"'; DROP TABLE transactions; --",
+		strings.Repeat("A", 65),
+	} {
+		assert.Equal(t, codes.InvalidArgument, status.Code(generator.Validate(malformed)), malformed)
+	}
+}
+
+func TestInvalidTemplateFallsBackToDefault(t *testing.T) {
+	cfg := config.ContractNumber{Strategy: numbering.StrategyOpaque, Template: "CN-{serial}"}
+	generator := numbering.NewContractNumberGenerator(cfg, nil)
+
+	contractNumber, err := generator.Generate(context.Background(), 123)
+
+	assert.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^CN-[0-9A-HJKMNP-TV-Z]{10}\d{2}$`), contractNumber)
+}
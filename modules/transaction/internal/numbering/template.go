@@ -0,0 +1,161 @@
+package numbering
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	TokenDate     = "date"
+	TokenConsumer = "consumer"
+	TokenUnique   = "unique"
+	TokenCheck    = "check"
+)
+
+// maxLength is the size of transactions.contract_number
+const maxLength = 64
+
+// Template is a contract number layout. Literal text is copied as is and the
+// tokens are replaced by:
+//
+//	{date}     the issue date as YYYYMMDD
+//	{consumer} the consumer id, zero padded to 8 digits
+//	{unique}   the part supplied by the strategy, required
+//	{check}    two ISO 7064 mod-97 check digits over everything before it
+type Template struct {
+	raw      string
+	segments []segment
+	pattern  *regexp.Regexp
+}
+
+type segment struct {
+	literal string
+	token   string
+}
+
+// ParseTemplate compiles raw for a strategy whose {unique} part matches
+// uniquePattern.
+func ParseTemplate(raw, uniquePattern string) (*Template, error) {
+	template := &Template{raw: raw}
+	expr := "^"
+	seen := map[string]bool{}
+
+	rest := raw
+	for rest != "" {
+		if seen[TokenCheck] {
+			return nil, fmt.Errorf("contract number template %q: {check} must come last", raw)
+		}
+
+		start := strings.IndexAny(rest, "{}")
+		literal := rest
+		if start >= 0 {
+			literal = rest[:start]
+		}
+		if strings.IndexFunc(literal, func(c rune) bool { return !isLiteral(c) }) >= 0 {
+			return nil, fmt.Errorf("contract number template %q: literal text may only contain letters, digits, '-', '_', '.' and '/'", raw)
+		}
+		if start < 0 {
+			template.segments = append(template.segments, segment{literal: rest})
+			expr += regexp.QuoteMeta(rest)
+			break
+		}
+		if start > 0 {
+			template.segments = append(template.segments, segment{literal: rest[:start]})
+			expr += regexp.QuoteMeta(rest[:start])
+		}
+		end := strings.IndexByte(rest, '}')
+		if rest[start] == '}' || end < 0 {
+			return nil, fmt.Errorf("contract number template %q: unbalanced braces", raw)
+		}
+
+		token := rest[start+1 : end]
+		if seen[token] {
+			return nil, fmt.Errorf("contract number template %q: {%s} used twice", raw, token)
+		}
+		switch token {
+		case TokenDate:
+			expr += `(\d{8})`
+		case TokenConsumer:
+			expr += `(\d{8,20})`
+		case TokenUnique:
+			expr += "(" + uniquePattern + ")"
+		case TokenCheck:
+			expr += `(\d{2})`
+		default:
+			return nil, fmt.Errorf("contract number template %q: unknown token {%s}", raw, token)
+		}
+		seen[token] = true
+		template.segments = append(template.segments, segment{token: token})
+		rest = rest[end+1:]
+	}
+
+	if !seen[TokenUnique] {
+		return nil, fmt.Errorf("contract number template %q: {unique} is required", raw)
+	}
+
+	pattern, err := regexp.Compile(expr + "$")
+	if err != nil {
+		return nil, fmt.Errorf("contract number template %q: %w", raw, err)
+	}
+	template.pattern = pattern
+
+	return template, nil
+}
+
+// isLiteral reports whether c may appear in a contract number.
+func isLiteral(c rune) bool {
+	switch {
+	case c >= '0' && c <= '9', c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z':
+		return true
+	}
+	return c == '-' || c == '_' || c == '.' || c == '/'
+}
+
+func (t *Template) String() string {
+	return t.raw
+}
+
+// Has reports whether the template uses token.
+func (t *Template) Has(token string) bool {
+	for _, segment := range t.segments {
+		if segment.token == token {
+			return true
+		}
+	}
+	return false
+}
+
+// Render fills the template in, computing {check} last.
+func (t *Template) Render(date time.Time, consumerId uint64, unique string) string {
+	var b strings.Builder
+	for _, segment := range t.segments {
+		switch segment.token {
+		case "":
+			b.WriteString(segment.literal)
+		case TokenDate:
+			b.WriteString(date.Format("20060102"))
+		case TokenConsumer:
+			fmt.Fprintf(&b, "%08d", consumerId)
+		case TokenUnique:
+			b.WriteString(unique)
+		case TokenCheck:
+			b.WriteString(CheckDigits(b.String()))
+		}
+	}
+	return b.String()
+}
+
+// Matches reports whether contractNumber has the layout of the template and,
+// when it carries {check}, whether the check digits are right.
+func (t *Template) Matches(contractNumber string) bool {
+	if len(contractNumber) > maxLength || !t.pattern.MatchString(contractNumber) {
+		return false
+	}
+	if !t.Has(TokenCheck) {
+		return true
+	}
+	body, check := contractNumber[:len(contractNumber)-2], contractNumber[len(contractNumber)-2:]
+	return CheckDigits(body) == check
+}
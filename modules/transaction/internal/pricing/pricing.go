@@ -0,0 +1,235 @@
+// Package pricing derives the admin fee, interest and monthly installment of a
+// contract from its OTR, tenor and an annual interest rate.
+//
+// Rounding policy: every amount is expressed in whole currency units.
+//   - The admin fee is OTR * AdminFeeBps / 10000, rounded half up to a unit.
+//   - The installment is computed exactly and then rounded UP to the next
+//     multiple of RoundingUnit, so the lender never under-collects.
+//   - Interest is derived as Installment*Tenor - OTR - AdminFee, which means any
+//     rounding surplus is booked as interest and the three components always
+//     reconcile with the sum of the installments.
+package pricing
+
+import (
+	"log"
+	"math"
+	"math/bits"
+	"strings"
+	"xyz-transaction-service/common/config"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type Method string
+
+const (
+	// MethodFlat charges interest on the original OTR for the whole tenor.
+	MethodFlat Method = "flat"
+	// MethodAnnuity charges interest on the outstanding principal (effective
+	// rate) and keeps the installment constant.
+	MethodAnnuity Method = "annuity"
+
+	bpsDenominator = 10000
+	monthsPerYear  = 12
+
+	// MaxOtr is the largest OTR that is priced: its admin fee at 10000 bps,
+	// rounding included, still fits in uint64.
+	MaxOtr = (math.MaxUint64 - bpsDenominator/2) / bpsDenominator
+)
+
+type Params struct {
+	Method        Method
+	Otr           uint64
+	Tenor         uint32
+	AnnualRateBps uint32
+	AdminFee      uint64
+	RoundingUnit  uint64
+}
+
+type Quote struct {
+	Method        Method
+	Otr           uint64
+	Tenor         uint32
+	AnnualRateBps uint32
+	AdminFee      uint64
+	Interest      uint64
+	Installment   uint64
+	// catalog product the quote was priced with, empty for the configured pricing
+	ProductCode    string
+	ProductVersion uint32
+}
+
+// Terms replace the configured method, rate and admin fee, e.g. with the
+// terms a catalog product sets for one tenor.
+type Terms struct {
+	Method        Method
+	AnnualRateBps uint32
+	AdminFee      uint64
+}
+
+type Engine struct {
+	method         Method
+	annualRateBps  uint32
+	adminFeeBps    uint32
+	roundingUnit   uint64
+	rejectMismatch bool
+}
+
+func NewEngine(cfg config.Pricing) *Engine {
+	return &Engine{
+		method:         ParseMethod(cfg.Method),
+		annualRateBps:  cfg.AnnualRateBps,
+		adminFeeBps:    cfg.AdminFeeBps,
+		roundingUnit:   cfg.RoundingUnit,
+		rejectMismatch: cfg.RejectMismatch,
+	}
+}
+
+type EngineUseCase interface {
+	Quote(otr uint64, tenor uint32) (*Quote, error)
+	QuoteTerms(otr uint64, tenor uint32, terms Terms) (*Quote, error)
+	Reconcile(quote *Quote, adminFee, installment, interest uint64) error
+}
+
+// ParseMethod maps a configured method name to a Method, falling back to flat.
+func ParseMethod(method string) Method {
+	switch Method(strings.ToLower(strings.TrimSpace(method))) {
+	case MethodAnnuity:
+		return MethodAnnuity
+	default:
+		return MethodFlat
+	}
+}
+
+// Quote prices a contract with the configured method, rate and admin fee.
+func (e *Engine) Quote(otr uint64, tenor uint32) (*Quote, error) {
+	return Calculate(Params{
+		Method:        e.method,
+		Otr:           otr,
+		Tenor:         tenor,
+		AnnualRateBps: e.annualRateBps,
+		AdminFee:      AdminFee(otr, e.adminFeeBps),
+		RoundingUnit:  e.roundingUnit,
+	})
+}
+
+// QuoteTerms prices a contract with terms and the configured rounding unit.
+func (e *Engine) QuoteTerms(otr uint64, tenor uint32, terms Terms) (*Quote, error) {
+	return Calculate(Params{
+		Method:        terms.Method,
+		Otr:           otr,
+		Tenor:         tenor,
+		AnnualRateBps: terms.AnnualRateBps,
+		AdminFee:      terms.AdminFee,
+		RoundingUnit:  e.roundingUnit,
+	})
+}
+
+// Reconcile compares client-supplied amounts against the quote. Zero values are
+// treated as "not supplied". Mismatches are rejected when the engine is strict,
+// otherwise the quote silently wins.
+func (e *Engine) Reconcile(quote *Quote, adminFee, installment, interest uint64) error {
+	mismatch := func(field string, got, want uint64) error {
+		if got == 0 || got == want {
+			return nil
+		}
+		if e.rejectMismatch {
+			log.Printf("WARNING: [Pricing - Reconcile] Client %s %d does not match server-side pricing %d\n", field, got, want)
+			return status.Errorf(codes.InvalidArgument, "%s does not match server-side pricing: expected %d, got %d", field, want, got)
+		}
+		log.Printf("WARNING: [Pricing - Reconcile] Overriding client %s %d with server-side pricing %d\n", field, got, want)
+		return nil
+	}
+
+	if err := mismatch("admin_fee", adminFee, quote.AdminFee); err != nil {
+		return err
+	}
+	if err := mismatch("installment", installment, quote.Installment); err != nil {
+		return err
+	}
+	return mismatch("interest", interest, quote.Interest)
+}
+
+// AdminFee returns otr * feeBps / 10000 rounded half up. The product is taken
+// in 128 bits, and a fee that does not fit in uint64 saturates at
+// math.MaxUint64.
+func AdminFee(otr uint64, feeBps uint32) uint64 {
+	hi, lo := bits.Mul64(otr, uint64(feeBps))
+	lo, carry := bits.Add64(lo, bpsDenominator/2, 0)
+	hi += carry
+	if hi >= bpsDenominator {
+		return math.MaxUint64
+	}
+	fee, _ := bits.Div64(hi, lo, bpsDenominator)
+	return fee
+}
+
+// Calculate prices a contract according to the package rounding policy.
+func Calculate(p Params) (*Quote, error) {
+	if p.Tenor == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "tenor must be greater than zero")
+	}
+	if p.Otr == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "otr must be greater than zero")
+	}
+	if p.Otr > MaxOtr {
+		return nil, status.Errorf(codes.InvalidArgument, "otr must be at most %d", uint64(MaxOtr))
+	}
+
+	unit := p.RoundingUnit
+	if unit == 0 {
+		unit = 1
+	}
+
+	otr := float64(p.Otr)
+	tenor := float64(p.Tenor)
+	annualRate := float64(p.AnnualRateBps) / bpsDenominator
+
+	var base float64
+	switch p.Method {
+	case MethodAnnuity:
+		monthlyRate := annualRate / monthsPerYear
+		if monthlyRate == 0 {
+			base = otr / tenor
+		} else {
+			base = otr * monthlyRate / (1 - math.Pow(1+monthlyRate, -tenor))
+		}
+	case MethodFlat, "":
+		totalInterest := otr * annualRate * tenor / monthsPerYear
+		base = (otr + totalInterest) / tenor
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown pricing method: %s", p.Method)
+	}
+
+	exact := base + float64(p.AdminFee)/tenor
+	installment := roundUp(exact, unit)
+
+	total := installment * uint64(p.Tenor)
+	if total < p.Otr+p.AdminFee {
+		// only reachable through float error on huge amounts; keep the invariant.
+		installment += unit
+		total = installment * uint64(p.Tenor)
+	}
+
+	method := p.Method
+	if method == "" {
+		method = MethodFlat
+	}
+
+	return &Quote{
+		Method:        method,
+		Otr:           p.Otr,
+		Tenor:         p.Tenor,
+		AnnualRateBps: p.AnnualRateBps,
+		AdminFee:      p.AdminFee,
+		Interest:      total - p.Otr - p.AdminFee,
+		Installment:   installment,
+	}, nil
+}
+
+func roundUp(amount float64, unit uint64) uint64 {
+	// trim float noise such as 100000.00000000001 before taking the ceiling.
+	units := math.Ceil(math.Round(amount/float64(unit)*1e6) / 1e6)
+	return uint64(units) * unit
+}
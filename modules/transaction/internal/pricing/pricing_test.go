@@ -0,0 +1,121 @@
+package pricing_test
+
+import (
+	"math"
+	"testing"
+	"time"
+	"xyz-transaction-service/common/config"
+	"xyz-transaction-service/modules/transaction/internal/pricing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCalculateFlat(t *testing.T) {
+	quote, err := pricing.Calculate(pricing.Params{
+		Method:        pricing.MethodFlat,
+		Otr:           1000000,
+		Tenor:         12,
+		AnnualRateBps: 2400,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(103334), quote.Installment)
+	assert.Equal(t, uint64(240008), quote.Interest)
+	assert.Equal(t, quote.Otr+quote.AdminFee+quote.Interest, quote.Installment*uint64(quote.Tenor))
+}
+
+func TestCalculateAnnuity(t *testing.T) {
+	quote, err := pricing.Calculate(pricing.Params{
+		Method:        pricing.MethodAnnuity,
+		Otr:           1000000,
+		Tenor:         12,
+		AnnualRateBps: 2400,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(94560), quote.Installment)
+	assert.Equal(t, uint64(134720), quote.Interest)
+}
+
+func TestCalculateAdminFeeAndRoundingUnit(t *testing.T) {
+	quote, err := pricing.Calculate(pricing.Params{
+		Method:        pricing.MethodFlat,
+		Otr:           1000000,
+		Tenor:         12,
+		AnnualRateBps: 2400,
+		AdminFee:      pricing.AdminFee(1000000, 500),
+		RoundingUnit:  100,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(50000), quote.AdminFee)
+	assert.Equal(t, uint64(107500), quote.Installment)
+	assert.Equal(t, uint64(240000), quote.Interest)
+}
+
+func TestCalculateZeroTenor(t *testing.T) {
+	_, err := pricing.Calculate(pricing.Params{Otr: 1000000})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAdminFeeDoesNotOverflow(t *testing.T) {
+	assert.Equal(t, uint64(pricing.MaxOtr), pricing.AdminFee(pricing.MaxOtr, 10000))
+	assert.Equal(t, uint64(math.MaxUint64/2), pricing.AdminFee(math.MaxUint64/2, 10000))
+	assert.Equal(t, uint64(math.MaxUint64), pricing.AdminFee(math.MaxUint64, 20000))
+}
+
+func TestCalculateOtrTooLarge(t *testing.T) {
+	_, err := pricing.Calculate(pricing.Params{Otr: pricing.MaxOtr + 1, Tenor: 12})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = pricing.Calculate(pricing.Params{Otr: pricing.MaxOtr, Tenor: 12, AdminFee: pricing.AdminFee(pricing.MaxOtr, 10000)})
+	assert.NoError(t, err)
+}
+
+func TestReconcile(t *testing.T) {
+	cfg := config.Pricing{Method: "flat", AnnualRateBps: 2400}
+
+	lenient := pricing.NewEngine(cfg)
+	quote, err := lenient.Quote(1000000, 12)
+	assert.NoError(t, err)
+	assert.NoError(t, lenient.Reconcile(quote, 0, 1, 0))
+
+	cfg.RejectMismatch = true
+	strict := pricing.NewEngine(cfg)
+	assert.NoError(t, strict.Reconcile(quote, 0, quote.Installment, 0))
+	assert.Equal(t, codes.InvalidArgument, status.Code(strict.Reconcile(quote, 0, 1, 0)))
+}
+
+func TestSchedule(t *testing.T) {
+	for _, method := range []pricing.Method{pricing.MethodFlat, pricing.MethodAnnuity} {
+		quote, err := pricing.Calculate(pricing.Params{
+			Method:        method,
+			Otr:           1000000,
+			Tenor:         12,
+			AnnualRateBps: 2400,
+			AdminFee:      50001,
+		})
+		assert.NoError(t, err)
+
+		items := pricing.Schedule(quote, time.Date(2024, time.January, 31, 10, 0, 0, 0, time.UTC))
+		assert.Len(t, items, 12)
+
+		var principal, interest, fee uint64
+		for _, item := range items {
+			assert.Equal(t, quote.Installment, item.Amount)
+			principal += item.Principal
+			interest += item.Interest
+			fee += item.Fee
+		}
+		assert.Equal(t, quote.Otr, principal)
+		assert.Equal(t, quote.Interest, interest)
+		assert.Equal(t, quote.AdminFee, fee)
+		assert.Equal(t, uint64(0), items[11].OutstandingBalance)
+
+		assert.Equal(t, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), items[0].DueDate)
+		assert.Equal(t, time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC), items[1].DueDate)
+	}
+}
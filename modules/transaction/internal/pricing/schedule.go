@@ -0,0 +1,72 @@
+package pricing
+
+import (
+	"math"
+	"time"
+)
+
+type ScheduleItem struct {
+	Number             uint32
+	DueDate            time.Time
+	Principal          uint64
+	Interest           uint64
+	Fee                uint64
+	Amount             uint64
+	OutstandingBalance uint64
+}
+
+// Schedule splits a quote into one item per tenor month. Fee and flat interest
+// are spread evenly, annuity interest follows the outstanding principal, and
+// the last item absorbs every rounding remainder so that the items add up to
+// the quote exactly. OutstandingBalance is the principal left after the item.
+func Schedule(quote *Quote, start time.Time) []ScheduleItem {
+	tenor := uint64(quote.Tenor)
+	monthlyRate := float64(quote.AnnualRateBps) / bpsDenominator / monthsPerYear
+
+	items := make([]ScheduleItem, 0, quote.Tenor)
+	balance := quote.Otr
+	var interestPaid, feePaid uint64
+
+	for n := uint32(1); n <= quote.Tenor; n++ {
+		item := ScheduleItem{
+			Number:  n,
+			DueDate: DueDate(start, n),
+		}
+
+		if n == quote.Tenor {
+			item.Fee = quote.AdminFee - feePaid
+			item.Interest = quote.Interest - interestPaid
+			item.Principal = balance
+		} else {
+			item.Fee = quote.AdminFee / tenor
+			switch quote.Method {
+			case MethodAnnuity:
+				item.Interest = uint64(math.Round(float64(balance) * monthlyRate))
+			default:
+				item.Interest = quote.Interest / tenor
+			}
+			item.Interest = min(item.Interest, quote.Interest-interestPaid)
+			item.Principal = min(quote.Installment-min(quote.Installment, item.Fee+item.Interest), balance)
+		}
+
+		balance -= item.Principal
+		interestPaid += item.Interest
+		feePaid += item.Fee
+
+		item.Amount = item.Principal + item.Interest + item.Fee
+		item.OutstandingBalance = balance
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// DueDate returns the date n months after start, clamped to the last day of the
+// target month so that a contract opened on the 31st is due on the 28th/30th.
+func DueDate(start time.Time, n uint32) time.Time {
+	year, month, day := start.Date()
+	first := time.Date(year, month+time.Month(n), 1, 0, 0, 0, 0, start.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+
+	return time.Date(first.Year(), first.Month(), min(day, lastDay), 0, 0, 0, 0, start.Location())
+}
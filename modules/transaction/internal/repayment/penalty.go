@@ -0,0 +1,36 @@
+package repayment
+
+import (
+	"time"
+	"xyz-transaction-service/modules/transaction/entity"
+)
+
+// AccruePenalty charges a one-off late penalty of bps basis points of the
+// scheduled amount on every installment that is still owed after its due date.
+// An installment is charged at most once, so replaying it for a later payment
+// does not stack penalties. It returns the installments it charged.
+func AccruePenalty(installments []*entity.Installment, bps uint32, asOf time.Time) []*entity.Installment {
+	if bps == 0 {
+		return nil
+	}
+
+	today := asOf.Format(entity.DueDateLayout)
+	var charged []*entity.Installment
+	for _, installment := range installments {
+		if installment.Penalty > 0 || installment.Outstanding() == 0 {
+			continue
+		}
+		if installment.DueDate.Format(entity.DueDateLayout) >= today {
+			continue
+		}
+
+		penalty := installment.Amount * uint64(bps) / 10000
+		if penalty == 0 {
+			continue
+		}
+		installment.Penalty = penalty
+		charged = append(charged, installment)
+	}
+
+	return charged
+}
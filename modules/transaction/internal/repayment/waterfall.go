@@ -0,0 +1,114 @@
+// Package repayment allocates incoming money to a contract's installments.
+//
+// Installments are settled oldest first. Within one installment the amount is
+// applied component by component in waterfall order, and only once an
+// installment is fully paid does the remainder move on to the next one.
+package repayment
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"xyz-transaction-service/modules/transaction/entity"
+)
+
+type Component string
+
+const (
+	ComponentPenalty   Component = "penalty"
+	ComponentFee       Component = "fee"
+	ComponentInterest  Component = "interest"
+	ComponentPrincipal Component = "principal"
+)
+
+var DefaultWaterfall = []Component{ComponentPenalty, ComponentFee, ComponentInterest, ComponentPrincipal}
+
+type Allocation struct {
+	Installment *entity.Installment
+	Component   Component
+	Amount      uint64
+}
+
+// ParseWaterfall reads a comma separated component order such as
+// "penalty,fee,interest,principal". Every component must appear exactly once.
+func ParseWaterfall(order string) ([]Component, error) {
+	if strings.TrimSpace(order) == "" {
+		return DefaultWaterfall, nil
+	}
+
+	seen := make(map[Component]bool)
+	var waterfall []Component
+	for _, part := range strings.Split(order, ",") {
+		c := Component(strings.ToLower(strings.TrimSpace(part)))
+		switch c {
+		case ComponentPenalty, ComponentFee, ComponentInterest, ComponentPrincipal:
+		default:
+			return nil, fmt.Errorf("unknown waterfall component: %q", part)
+		}
+		if seen[c] {
+			return nil, fmt.Errorf("duplicate waterfall component: %q", part)
+		}
+		seen[c] = true
+		waterfall = append(waterfall, c)
+	}
+
+	if len(waterfall) != len(DefaultWaterfall) {
+		return nil, fmt.Errorf("waterfall must list %d components, got %d", len(DefaultWaterfall), len(waterfall))
+	}
+
+	return waterfall, nil
+}
+
+// Allocate applies amount to installments, which must be sorted by installment
+// number. It updates the paid columns and status of the touched installments
+// in place and returns the allocations plus any amount left unallocated.
+func Allocate(installments []*entity.Installment, amount uint64, waterfall []Component, paidAt time.Time) ([]Allocation, uint64) {
+	var allocations []Allocation
+
+	for _, installment := range installments {
+		if amount == 0 {
+			break
+		}
+		if installment.Outstanding() == 0 {
+			continue
+		}
+
+		for _, component := range waterfall {
+			due, paid := components(installment, component)
+			applied := min(amount, due-*paid)
+			if applied == 0 {
+				continue
+			}
+
+			*paid += applied
+			amount -= applied
+			allocations = append(allocations, Allocation{
+				Installment: installment,
+				Component:   component,
+				Amount:      applied,
+			})
+		}
+
+		if installment.Outstanding() == 0 {
+			installment.Status = entity.InstallmentStatusPaid
+			installment.PaidAt = &paidAt
+		} else {
+			installment.Status = entity.InstallmentStatusPartiallyPaid
+		}
+	}
+
+	return allocations, amount
+}
+
+func components(i *entity.Installment, c Component) (uint64, *uint64) {
+	switch c {
+	case ComponentPenalty:
+		return i.Penalty, &i.PaidPenalty
+	case ComponentFee:
+		return i.Fee, &i.PaidFee
+	case ComponentInterest:
+		return i.Interest, &i.PaidInterest
+	default:
+		return i.Principal, &i.PaidPrincipal
+	}
+}
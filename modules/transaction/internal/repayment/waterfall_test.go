@@ -0,0 +1,82 @@
+package repayment_test
+
+import (
+	"testing"
+	"time"
+	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/modules/transaction/internal/repayment"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newSchedule() []*entity.Installment {
+	return []*entity.Installment{
+		{Id: 1, InstallmentNumber: 1, Principal: 800, Interest: 150, Fee: 50, Amount: 1000, Penalty: 20, Status: entity.InstallmentStatusUnpaid},
+		{Id: 2, InstallmentNumber: 2, Principal: 800, Interest: 150, Fee: 50, Amount: 1000, Status: entity.InstallmentStatusUnpaid},
+	}
+}
+
+func TestParseWaterfall(t *testing.T) {
+	waterfall, err := repayment.ParseWaterfall("")
+	assert.NoError(t, err)
+	assert.Equal(t, repayment.DefaultWaterfall, waterfall)
+
+	waterfall, err = repayment.ParseWaterfall("principal, interest, fee, penalty")
+	assert.NoError(t, err)
+	assert.Equal(t, repayment.ComponentPrincipal, waterfall[0])
+
+	_, err = repayment.ParseWaterfall("penalty,fee,interest")
+	assert.Error(t, err)
+
+	_, err = repayment.ParseWaterfall("penalty,fee,fee,principal")
+	assert.Error(t, err)
+}
+
+func TestAllocatePartialPayment(t *testing.T) {
+	installments := newSchedule()
+
+	allocations, remainder := repayment.Allocate(installments, 100, repayment.DefaultWaterfall, time.Now())
+
+	assert.Equal(t, uint64(0), remainder)
+	assert.Len(t, allocations, 3)
+	assert.Equal(t, repayment.ComponentPenalty, allocations[0].Component)
+	assert.Equal(t, uint64(20), installments[0].PaidPenalty)
+	assert.Equal(t, uint64(50), installments[0].PaidFee)
+	assert.Equal(t, uint64(30), installments[0].PaidInterest)
+	assert.Equal(t, entity.InstallmentStatusPartiallyPaid, installments[0].Status)
+	assert.Equal(t, entity.InstallmentStatusUnpaid, installments[1].Status)
+}
+
+func TestAllocateSpillsOverAndReturnsRemainder(t *testing.T) {
+	installments := newSchedule()
+
+	_, remainder := repayment.Allocate(installments, 1500, repayment.DefaultWaterfall, time.Now())
+	assert.Equal(t, uint64(0), remainder)
+	assert.Equal(t, entity.InstallmentStatusPaid, installments[0].Status)
+	assert.NotNil(t, installments[0].PaidAt)
+	assert.Equal(t, entity.InstallmentStatusPartiallyPaid, installments[1].Status)
+	assert.Equal(t, uint64(520), installments[1].Outstanding())
+
+	_, remainder = repayment.Allocate(installments, 600, repayment.DefaultWaterfall, time.Now())
+	assert.Equal(t, uint64(80), remainder)
+	assert.Equal(t, entity.InstallmentStatusPaid, installments[1].Status)
+}
+
+func TestAccruePenalty(t *testing.T) {
+	installments := newSchedule()
+	installments[0].Penalty = 0
+	installments[0].DueDate = time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	installments[1].DueDate = time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)
+
+	assert.Empty(t, repayment.AccruePenalty(installments, 0, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)))
+
+	charged := repayment.AccruePenalty(installments, 500, time.Date(2026, 2, 10, 12, 0, 0, 0, time.UTC))
+	assert.Len(t, charged, 1)
+	assert.Equal(t, uint64(50), installments[0].Penalty)
+	assert.Equal(t, uint64(0), installments[1].Penalty)
+
+	// charged once, not again on a later payment
+	charged = repayment.AccruePenalty(installments, 500, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, []*entity.Installment{installments[1]}, charged)
+	assert.Equal(t, uint64(50), installments[0].Penalty)
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+	gormConn "xyz-transaction-service/common/gorm"
+	"xyz-transaction-service/common/tracing"
+	"xyz-transaction-service/modules/transaction/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ContractNumberSequenceRepository struct {
+	db *gorm.DB
+}
+
+func NewContractNumberSequenceRepository(db *gorm.DB) *ContractNumberSequenceRepository {
+	return &ContractNumberSequenceRepository{
+		db: db,
+	}
+}
+
+type ContractNumberSequenceRepositoryUseCase interface {
+	Next(ctx context.Context, day string) (uint64, error)
+}
+
+// Next increments the counter of day, starting it at 1, and returns the new
+// value. The upsert keeps the row locked until the value is read back, so
+// concurrent callers never share a value.
+func (c *ContractNumberSequenceRepository) Next(ctx context.Context, day string) (uint64, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "ContractNumberSequenceRepository - Next")
+	defer span.End()
+
+	var sequence entity.ContractNumberSequence
+	err := gormConn.Conn(ctx, c.db).Debug().WithContext(ctxSpan).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "day"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"counter":    gorm.Expr(entity.ContractNumberSequenceTableName + ".counter + 1"),
+				"updated_at": now,
+			}),
+		}).Create(&entity.ContractNumberSequence{Day: day, Counter: 1, UpdatedAt: now}).Error; err != nil {
+			return err
+		}
+		return tx.Where("day = ?", day).First(&sequence).Error
+	})
+	if err != nil {
+		log.Println("ERROR: [ContractNumberSequenceRepository - Next] Internal server error:", err)
+		return 0, err
+	}
+
+	return sequence.Counter, nil
+}
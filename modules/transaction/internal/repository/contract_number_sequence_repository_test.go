@@ -0,0 +1,24 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"xyz-transaction-service/modules/transaction/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContractNumberSequenceNextSQLite(t *testing.T) {
+	repo := repository.NewContractNumberSequenceRepository(setupSQLiteDB(t))
+
+	for want := uint64(1); want <= 3; want++ {
+		next, err := repo.Next(context.Background(), "20240101")
+		assert.NoError(t, err)
+		assert.Equal(t, want, next)
+	}
+
+	// every day counts from 1
+	next, err := repo.Next(context.Background(), "20240102")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), next)
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	gormConn "xyz-transaction-service/common/gorm"
+	"xyz-transaction-service/common/tracing"
+	"xyz-transaction-service/modules/transaction/entity"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyKeyRepository(db *gorm.DB) *IdempotencyKeyRepository {
+	return &IdempotencyKeyRepository{
+		db: db,
+	}
+}
+
+type IdempotencyKeyRepositoryUseCase interface {
+	FindByKey(ctx context.Context, method, key string) (*entity.IdempotencyKey, error)
+	FindExpired(ctx context.Context, now time.Time, limit int) ([]*entity.IdempotencyKey, error)
+	Create(ctx context.Context, req *entity.IdempotencyKey) (*entity.IdempotencyKey, error)
+	Complete(ctx context.Context, req *entity.IdempotencyKey) error
+	TakeOver(ctx context.Context, req *entity.IdempotencyKey, lockedUntil time.Time) (bool, error)
+	Delete(ctx context.Context, id uint64) error
+}
+
+func (i *IdempotencyKeyRepository) FindByKey(ctx context.Context, method, key string) (*entity.IdempotencyKey, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "IdempotencyKeyRepository - FindByKey")
+	defer span.End()
+
+	// key is reserved in MySQL, the column is quoted by the dialect
+	var idempotencyKey entity.IdempotencyKey
+	if err := gormConn.Conn(ctx, i.db).Debug().WithContext(ctxSpan).Where("method = ?", method).Where(clause.Eq{Column: clause.Column{Name: "key"}, Value: key}).First(&idempotencyKey).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "Idempotency key not found: %v", key)
+		}
+		log.Println("ERROR: [IdempotencyKeyRepository - FindByKey] Internal server error:", err)
+		return nil, err
+	}
+
+	return &idempotencyKey, nil
+}
+
+func (i *IdempotencyKeyRepository) FindExpired(ctx context.Context, now time.Time, limit int) ([]*entity.IdempotencyKey, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "IdempotencyKeyRepository - FindExpired")
+	defer span.End()
+
+	var idempotencyKeys []*entity.IdempotencyKey
+	if err := gormConn.Conn(ctx, i.db).Debug().WithContext(ctxSpan).Where("expires_at <= ?", now).Order("expires_at asc").Limit(limit).Find(&idempotencyKeys).Error; err != nil {
+		log.Println("ERROR: [IdempotencyKeyRepository - FindExpired] Internal server error:", err)
+		return nil, err
+	}
+
+	return idempotencyKeys, nil
+}
+
+func (i *IdempotencyKeyRepository) Create(ctx context.Context, req *entity.IdempotencyKey) (*entity.IdempotencyKey, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "IdempotencyKeyRepository - Create")
+	defer span.End()
+
+	if err := gormConn.Conn(ctx, i.db).Debug().WithContext(ctxSpan).Create(req).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, status.Errorf(codes.AlreadyExists, "Idempotency key already exists: %v", req.Key)
+		}
+		log.Println("ERROR: [IdempotencyKeyRepository - Create] Internal server error:", err)
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (i *IdempotencyKeyRepository) Complete(ctx context.Context, req *entity.IdempotencyKey) error {
+	ctxSpan, span := tracing.StartSpan(ctx, "IdempotencyKeyRepository - Complete")
+	defer span.End()
+
+	if err := gormConn.Conn(ctx, i.db).Debug().WithContext(ctxSpan).Model(req).
+		Select("status", "response", "updated_at").Updates(req).Error; err != nil {
+		log.Println("ERROR: [IdempotencyKeyRepository - Complete] Internal server error:", err)
+		return err
+	}
+
+	return nil
+}
+
+// TakeOver locks a stale IN_PROGRESS key for a new request with a
+// compare-and-set on its updated_at, so only one retry wins the key.
+func (i *IdempotencyKeyRepository) TakeOver(ctx context.Context, req *entity.IdempotencyKey, lockedUntil time.Time) (bool, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "IdempotencyKeyRepository - TakeOver")
+	defer span.End()
+
+	now := time.Now()
+	res := gormConn.Conn(ctx, i.db).Debug().WithContext(ctxSpan).Model(&entity.IdempotencyKey{}).
+		Where("id = ? AND status = ? AND updated_at = ?", req.Id, entity.IdempotencyKeyStatusInProgress, req.UpdatedAt).
+		Updates(map[string]interface{}{"locked_until": lockedUntil, "saga_reference": req.SagaReference, "updated_at": now})
+	if res.Error != nil {
+		log.Println("ERROR: [IdempotencyKeyRepository - TakeOver] Internal server error:", res.Error)
+		return false, res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return false, nil
+	}
+
+	req.LockedUntil = &lockedUntil
+	req.UpdatedAt = now
+	return true, nil
+}
+
+func (i *IdempotencyKeyRepository) Delete(ctx context.Context, id uint64) error {
+	ctxSpan, span := tracing.StartSpan(ctx, "IdempotencyKeyRepository - Delete")
+	defer span.End()
+
+	if err := gormConn.Conn(ctx, i.db).Debug().WithContext(ctxSpan).Delete(&entity.IdempotencyKey{}, id).Error; err != nil {
+		log.Println("ERROR: [IdempotencyKeyRepository - Delete] Internal server error:", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/modules/transaction/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIdempotencyKeyFindByKeySQLite(t *testing.T) {
+	repo := repository.NewIdempotencyKeyRepository(setupSQLiteDB(t))
+
+	key := &entity.IdempotencyKey{
+		Key:         "key-1",
+		Method:      "/xyz_grpc.TransactionService/CreateTransaction",
+		RequestHash: "hash",
+		Status:      entity.IdempotencyKeyStatusInProgress,
+		ExpiresAt:   time.Now().Add(time.Hour),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	_, err := repo.Create(context.Background(), key)
+	assert.NoError(t, err)
+
+	duplicate := *key
+	duplicate.Id = 0
+	_, err = repo.Create(context.Background(), &duplicate)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	found, err := repo.FindByKey(context.Background(), key.Method, "key-1")
+	assert.NoError(t, err)
+	assert.Equal(t, key.Id, found.Id)
+
+	_, err = repo.FindByKey(context.Background(), key.Method, "key-2")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestIdempotencyKeyTakeOverSQLite(t *testing.T) {
+	repo := repository.NewIdempotencyKeyRepository(setupSQLiteDB(t))
+
+	now := time.Now()
+	_, err := repo.Create(context.Background(), &entity.IdempotencyKey{
+		Key:         "key-1",
+		Method:      "/xyz_grpc.TransactionService/CreateTransaction",
+		RequestHash: "hash",
+		Status:      entity.IdempotencyKeyStatusInProgress,
+		ExpiresAt:   now.Add(time.Hour),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	assert.NoError(t, err)
+
+	// both retries read the key before either takes it over
+	first, err := repo.FindByKey(context.Background(), "/xyz_grpc.TransactionService/CreateTransaction", "key-1")
+	assert.NoError(t, err)
+	second := *first
+
+	ok, err := repo.TakeOver(context.Background(), first, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = repo.TakeOver(context.Background(), &second, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	found, err := repo.FindByKey(context.Background(), "/xyz_grpc.TransactionService/CreateTransaction", "key-1")
+	assert.NoError(t, err)
+	assert.True(t, found.IsLocked(now))
+}
+
+func TestIdempotencyKeyFindExpiredSQLite(t *testing.T) {
+	repo := repository.NewIdempotencyKeyRepository(setupSQLiteDB(t))
+
+	now := time.Now()
+	for i, expiresAt := range []time.Time{now.Add(-time.Hour), now.Add(time.Hour), now.Add(-time.Minute)} {
+		_, err := repo.Create(context.Background(), &entity.IdempotencyKey{
+			Key:         fmt.Sprintf("key-%d", i),
+			Method:      "/xyz_grpc.TransactionService/CreateTransaction",
+			RequestHash: "hash",
+			Status:      entity.IdempotencyKeyStatusCompleted,
+			ExpiresAt:   expiresAt,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+		assert.NoError(t, err)
+	}
+
+	expired, err := repo.FindExpired(context.Background(), now, 10)
+	assert.NoError(t, err)
+	assert.Len(t, expired, 2)
+	assert.Equal(t, "key-0", expired[0].Key)
+
+	expired, err = repo.FindExpired(context.Background(), now, 1)
+	assert.NoError(t, err)
+	assert.Len(t, expired, 1)
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"log"
+	gormConn "xyz-transaction-service/common/gorm"
+	"xyz-transaction-service/common/tracing"
+	"xyz-transaction-service/modules/transaction/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InstallmentRepository struct {
+	db *gorm.DB
+}
+
+func NewInstallmentRepository(db *gorm.DB) *InstallmentRepository {
+	return &InstallmentRepository{
+		db: db,
+	}
+}
+
+type InstallmentRepositoryUseCase interface {
+	FindByTransactionId(ctx context.Context, transactionId uint64) ([]*entity.Installment, error)
+	FindByTransactionIdForUpdate(ctx context.Context, transactionId uint64) ([]*entity.Installment, error)
+	UpdatePayment(ctx context.Context, installment *entity.Installment) error
+}
+
+func (i *InstallmentRepository) FindByTransactionId(ctx context.Context, transactionId uint64) ([]*entity.Installment, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "InstallmentRepository - FindByTransactionId")
+	defer span.End()
+
+	var installments []*entity.Installment
+	if err := gormConn.Conn(ctx, i.db).Debug().WithContext(ctxSpan).Where("transaction_id = ?", transactionId).Order("installment_number asc").Find(&installments).Error; err != nil {
+		log.Println("ERROR: [InstallmentRepository - FindByTransactionId] Internal server error:", err)
+		return nil, err
+	}
+
+	return installments, nil
+}
+
+// FindByTransactionIdForUpdate locks the schedule rows until the surrounding
+// transaction ends, so concurrent payments are applied one after another.
+func (i *InstallmentRepository) FindByTransactionIdForUpdate(ctx context.Context, transactionId uint64) ([]*entity.Installment, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "InstallmentRepository - FindByTransactionIdForUpdate")
+	defer span.End()
+
+	var installments []*entity.Installment
+	if err := gormConn.Conn(ctx, i.db).Debug().WithContext(ctxSpan).Clauses(clause.Locking{Strength: "UPDATE"}).Where("transaction_id = ?", transactionId).Order("installment_number asc").Find(&installments).Error; err != nil {
+		log.Println("ERROR: [InstallmentRepository - FindByTransactionIdForUpdate] Internal server error:", err)
+		return nil, err
+	}
+
+	return installments, nil
+}
+
+func (i *InstallmentRepository) UpdatePayment(ctx context.Context, installment *entity.Installment) error {
+	ctxSpan, span := tracing.StartSpan(ctx, "InstallmentRepository - UpdatePayment")
+	defer span.End()
+
+	if err := gormConn.Conn(ctx, i.db).Debug().WithContext(ctxSpan).Model(installment).
+		Select("penalty", "paid_principal", "paid_interest", "paid_fee", "paid_penalty", "status", "paid_at", "updated_at").
+		Updates(installment).Error; err != nil {
+		log.Println("ERROR: [InstallmentRepository - UpdatePayment] Internal server error:", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	gormConn "xyz-transaction-service/common/gorm"
+	"xyz-transaction-service/common/tracing"
+	"xyz-transaction-service/modules/transaction/entity"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+type PaymentRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) *PaymentRepository {
+	return &PaymentRepository{
+		db: db,
+	}
+}
+
+type PaymentRepositoryUseCase interface {
+	FindByPaymentReference(ctx context.Context, paymentReference string) (*entity.Payment, error)
+	Create(ctx context.Context, req *entity.Payment) (*entity.Payment, error)
+}
+
+func (p *PaymentRepository) FindByPaymentReference(ctx context.Context, paymentReference string) (*entity.Payment, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "PaymentRepository - FindByPaymentReference")
+	defer span.End()
+
+	var payment entity.Payment
+	if err := gormConn.Conn(ctx, p.db).Debug().WithContext(ctxSpan).Preload("Allocations").Where("payment_reference = ?", paymentReference).First(&payment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Println("WARNING: [PaymentRepository - FindByPaymentReference] Payment not found for payment reference:", paymentReference)
+			return nil, status.Errorf(codes.NotFound, "Payment not found for payment reference: %v", paymentReference)
+		}
+		log.Println("ERROR: [PaymentRepository - FindByPaymentReference] Internal server error:", err)
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+func (p *PaymentRepository) Create(ctx context.Context, req *entity.Payment) (*entity.Payment, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "PaymentRepository - Create")
+	defer span.End()
+
+	if err := gormConn.Conn(ctx, p.db).Debug().WithContext(ctxSpan).Create(req).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			log.Println("WARNING: [PaymentRepository - Create] Payment already exists for payment reference:", req.PaymentReference)
+			return nil, status.Errorf(codes.AlreadyExists, "Payment already exists for payment reference: %v", req.PaymentReference)
+		}
+		log.Println("ERROR: [PaymentRepository - Create] Internal server error:", err)
+		return nil, err
+	}
+
+	return req, nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	gormConn "xyz-transaction-service/common/gorm"
+	"xyz-transaction-service/common/tracing"
+	"xyz-transaction-service/modules/transaction/entity"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+type ProductRepository struct {
+	db *gorm.DB
+}
+
+func NewProductRepository(db *gorm.DB) *ProductRepository {
+	return &ProductRepository{
+		db: db,
+	}
+}
+
+type ProductRepositoryUseCase interface {
+	FindAllEffective(ctx context.Context, at time.Time) ([]*entity.Product, error)
+	FindEffective(ctx context.Context, code string, at time.Time) (*entity.Product, error)
+	FindByCodeAndVersion(ctx context.Context, code string, version uint32) (*entity.Product, error)
+	FindLatest(ctx context.Context, code string) (*entity.Product, error)
+	Create(ctx context.Context, req *entity.Product) (*entity.Product, error)
+	UpdateEffectiveTo(ctx context.Context, code string, effectiveTo time.Time) error
+}
+
+func preloadProductTenors(db *gorm.DB) *gorm.DB {
+	return db.Order("tenor asc")
+}
+
+// effectiveAt narrows a query to the versions whose range contains at.
+func effectiveAt(at time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("effective_from <= ? AND (effective_to IS NULL OR effective_to > ?)", at, at)
+	}
+}
+
+// FindAllEffective returns, per product code, the highest version effective at at.
+func (p *ProductRepository) FindAllEffective(ctx context.Context, at time.Time) ([]*entity.Product, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "ProductRepository - FindAllEffective")
+	defer span.End()
+
+	var versions []*entity.Product
+	if err := gormConn.Conn(ctx, p.db).Debug().WithContext(ctxSpan).Preload("Tenors", preloadProductTenors).
+		Scopes(effectiveAt(at)).Order("code asc, version desc").Find(&versions).Error; err != nil {
+		log.Println("ERROR: [ProductRepository - FindAllEffective] Internal server error:", err)
+		return nil, err
+	}
+
+	var products []*entity.Product
+	for _, version := range versions {
+		if len(products) == 0 || products[len(products)-1].Code != version.Code {
+			products = append(products, version)
+		}
+	}
+
+	return products, nil
+}
+
+// FindEffective returns the highest version of code effective at at.
+func (p *ProductRepository) FindEffective(ctx context.Context, code string, at time.Time) (*entity.Product, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "ProductRepository - FindEffective")
+	defer span.End()
+
+	var product entity.Product
+	if err := gormConn.Conn(ctx, p.db).Debug().WithContext(ctxSpan).Preload("Tenors", preloadProductTenors).
+		Scopes(effectiveAt(at)).Where("code = ?", code).Order("version desc").First(&product).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "No version of product %v is effective", code)
+		}
+		log.Println("ERROR: [ProductRepository - FindEffective] Internal server error:", err)
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+func (p *ProductRepository) FindByCodeAndVersion(ctx context.Context, code string, version uint32) (*entity.Product, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "ProductRepository - FindByCodeAndVersion")
+	defer span.End()
+
+	var product entity.Product
+	if err := gormConn.Conn(ctx, p.db).Debug().WithContext(ctxSpan).Preload("Tenors", preloadProductTenors).
+		Where("code = ? AND version = ?", code, version).First(&product).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "Product %v version %v not found", code, version)
+		}
+		log.Println("ERROR: [ProductRepository - FindByCodeAndVersion] Internal server error:", err)
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+// FindLatest returns the highest version of code, effective or not.
+func (p *ProductRepository) FindLatest(ctx context.Context, code string) (*entity.Product, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "ProductRepository - FindLatest")
+	defer span.End()
+
+	var product entity.Product
+	if err := gormConn.Conn(ctx, p.db).Debug().WithContext(ctxSpan).Preload("Tenors", preloadProductTenors).
+		Where("code = ?", code).Order("version desc").First(&product).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "Product not found: %v", code)
+		}
+		log.Println("ERROR: [ProductRepository - FindLatest] Internal server error:", err)
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+func (p *ProductRepository) Create(ctx context.Context, req *entity.Product) (*entity.Product, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "ProductRepository - Create")
+	defer span.End()
+
+	if err := gormConn.Conn(ctx, p.db).Debug().WithContext(ctxSpan).Create(req).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, status.Errorf(codes.AlreadyExists, "Product %v version %v already exists", req.Code, req.Version)
+		}
+		log.Println("ERROR: [ProductRepository - Create] Internal server error:", err)
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// UpdateEffectiveTo ends every version of code still effective at, or
+// scheduled after, effectiveTo.
+func (p *ProductRepository) UpdateEffectiveTo(ctx context.Context, code string, effectiveTo time.Time) error {
+	ctxSpan, span := tracing.StartSpan(ctx, "ProductRepository - UpdateEffectiveTo")
+	defer span.End()
+
+	if err := gormConn.Conn(ctx, p.db).Debug().WithContext(ctxSpan).Model(&entity.Product{}).
+		Where("code = ? AND (effective_to IS NULL OR effective_to > ?)", code, effectiveTo).
+		Updates(map[string]interface{}{"effective_to": effectiveTo, "updated_at": time.Now()}).Error; err != nil {
+		log.Println("ERROR: [ProductRepository - UpdateEffectiveTo] Internal server error:", err)
+		return err
+	}
+
+	return nil
+}
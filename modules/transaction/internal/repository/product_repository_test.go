@@ -0,0 +1,74 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/modules/transaction/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newProduct(code string, version uint32, from time.Time, tenors ...uint32) *entity.Product {
+	product := &entity.Product{
+		Code:          code,
+		Version:       version,
+		Name:          code,
+		PricingMethod: "flat",
+		AdminFeeType:  entity.AdminFeeTypeFlat,
+		EffectiveFrom: from,
+		CreatedAt:     from,
+		UpdatedAt:     from,
+	}
+	for _, tenor := range tenors {
+		product.Tenors = append(product.Tenors, &entity.ProductTenor{Tenor: tenor, AnnualRateBps: 1200})
+	}
+	return product
+}
+
+func TestProductRepositoryEffectiveSQLite(t *testing.T) {
+	repo := repository.NewProductRepository(setupSQLiteDB(t))
+	ctx := context.Background()
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, product := range []*entity.Product{
+		newProduct("MOTOR", 1, jan, 12, 6),
+		newProduct("MOTOR", 2, mar, 6),
+		newProduct("CAR", 1, mar, 24),
+	} {
+		_, err := repo.Create(ctx, product)
+		assert.NoError(t, err)
+	}
+
+	_, err := repo.Create(ctx, newProduct("MOTOR", 2, mar, 6))
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	// the next version takes over from its effective date
+	feb, err := repo.FindEffective(ctx, "MOTOR", jan.AddDate(0, 1, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1), feb.Version)
+	assert.Equal(t, uint32(6), feb.Tenors[0].Tenor)
+	assert.Equal(t, uint32(12), feb.Tenors[1].Tenor)
+
+	products, err := repo.FindAllEffective(ctx, mar)
+	assert.NoError(t, err)
+	if assert.Len(t, products, 2) {
+		assert.Equal(t, "CAR", products[0].Code)
+		assert.Equal(t, "MOTOR", products[1].Code)
+		assert.Equal(t, uint32(2), products[1].Version)
+	}
+
+	// retiring closes every version still running
+	assert.NoError(t, repo.UpdateEffectiveTo(ctx, "MOTOR", mar.AddDate(0, 1, 0)))
+	_, err = repo.FindEffective(ctx, "MOTOR", mar.AddDate(0, 2, 0))
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	latest, err := repo.FindLatest(ctx, "MOTOR")
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), latest.Version)
+	assert.NotNil(t, latest.EffectiveTo)
+}
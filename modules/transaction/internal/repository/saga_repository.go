@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	gormConn "xyz-transaction-service/common/gorm"
+	"xyz-transaction-service/common/tracing"
+	"xyz-transaction-service/modules/transaction/entity"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+type SagaRepository struct {
+	db *gorm.DB
+}
+
+func NewSagaRepository(db *gorm.DB) *SagaRepository {
+	return &SagaRepository{
+		db: db,
+	}
+}
+
+type SagaRepositoryUseCase interface {
+	FindPending(ctx context.Context, updatedBefore time.Time, limit int) ([]*entity.Saga, error)
+	FindByReference(ctx context.Context, reference string) (*entity.Saga, error)
+	Create(ctx context.Context, req *entity.Saga) (*entity.Saga, error)
+	Update(ctx context.Context, req *entity.Saga) error
+	UpdateStep(ctx context.Context, req *entity.SagaStep) error
+	Claim(ctx context.Context, req *entity.Saga) (bool, error)
+}
+
+func (s *SagaRepository) FindPending(ctx context.Context, updatedBefore time.Time, limit int) ([]*entity.Saga, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "SagaRepository - FindPending")
+	defer span.End()
+
+	var sagas []*entity.Saga
+	if err := gormConn.Conn(ctx, s.db).Debug().WithContext(ctxSpan).
+		Preload("Steps", func(db *gorm.DB) *gorm.DB { return db.Order("sequence asc") }).
+		Where("status IN ? AND updated_at < ?", []string{entity.SagaStatusStarted, entity.SagaStatusCompensating}, updatedBefore).
+		Order("updated_at asc").Limit(limit).Find(&sagas).Error; err != nil {
+		log.Println("ERROR: [SagaRepository - FindPending] Internal server error:", err)
+		return nil, err
+	}
+
+	return sagas, nil
+}
+
+func (s *SagaRepository) FindByReference(ctx context.Context, reference string) (*entity.Saga, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "SagaRepository - FindByReference")
+	defer span.End()
+
+	var saga entity.Saga
+	if err := gormConn.Conn(ctx, s.db).Debug().WithContext(ctxSpan).
+		Preload("Steps", func(db *gorm.DB) *gorm.DB { return db.Order("sequence asc") }).
+		Where("reference = ?", reference).First(&saga).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "Saga not found: %v", reference)
+		}
+		log.Println("ERROR: [SagaRepository - FindByReference] Internal server error:", err)
+		return nil, err
+	}
+
+	return &saga, nil
+}
+
+func (s *SagaRepository) Create(ctx context.Context, req *entity.Saga) (*entity.Saga, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "SagaRepository - Create")
+	defer span.End()
+
+	if err := gormConn.Conn(ctx, s.db).Debug().WithContext(ctxSpan).Create(req).Error; err != nil {
+		log.Println("ERROR: [SagaRepository - Create] Internal server error:", err)
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (s *SagaRepository) Update(ctx context.Context, req *entity.Saga) error {
+	ctxSpan, span := tracing.StartSpan(ctx, "SagaRepository - Update")
+	defer span.End()
+
+	req.UpdatedAt = time.Now()
+	if err := gormConn.Conn(ctx, s.db).Debug().WithContext(ctxSpan).Model(req).
+		Select("transaction_id", "contract_number", "status", "last_error", "updated_at").
+		Updates(req).Error; err != nil {
+		log.Println("ERROR: [SagaRepository - Update] Internal server error:", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *SagaRepository) UpdateStep(ctx context.Context, req *entity.SagaStep) error {
+	ctxSpan, span := tracing.StartSpan(ctx, "SagaRepository - UpdateStep")
+	defer span.End()
+
+	req.UpdatedAt = time.Now()
+	if err := gormConn.Conn(ctx, s.db).Debug().WithContext(ctxSpan).Model(req).
+		Select("status", "error", "updated_at").
+		Updates(req).Error; err != nil {
+		log.Println("ERROR: [SagaRepository - UpdateStep] Internal server error:", err)
+		return err
+	}
+
+	return nil
+}
+
+// Claim bumps the attempt counter with a compare-and-set on the previous value,
+// so only one replica picks up a stuck saga per recovery round.
+func (s *SagaRepository) Claim(ctx context.Context, req *entity.Saga) (bool, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "SagaRepository - Claim")
+	defer span.End()
+
+	now := time.Now()
+	res := gormConn.Conn(ctx, s.db).Debug().WithContext(ctxSpan).Model(&entity.Saga{}).
+		Where("id = ? AND attempts = ?", req.Id, req.Attempts).
+		Updates(map[string]interface{}{"attempts": req.Attempts + 1, "updated_at": now})
+	if res.Error != nil {
+		log.Println("ERROR: [SagaRepository - Claim] Internal server error:", res.Error)
+		return false, res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return false, nil
+	}
+
+	req.Attempts++
+	req.UpdatedAt = now
+	return true, nil
+}
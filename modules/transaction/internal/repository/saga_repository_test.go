@@ -0,0 +1,46 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/modules/transaction/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSagaFindByReferenceSQLite(t *testing.T) {
+	repo := repository.NewSagaRepository(setupSQLiteDB(t))
+
+	now := time.Now()
+	saga := &entity.Saga{
+		SagaType:       entity.SagaTypeCreateTransaction,
+		Reference:      "ref-1",
+		ContractNumber: "CN123",
+		ConsumerId:     3,
+		Tenor:          12,
+		Otr:            1000000,
+		AssetName:      "Laptop",
+		Status:         entity.SagaStatusStarted,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	for i, name := range []string{entity.SagaStepDebitLimit, entity.SagaStepCreateTransaction} {
+		saga.Steps = append(saga.Steps, &entity.SagaStep{Name: name, Sequence: uint32(2 - i), Status: entity.SagaStepStatusPending, CreatedAt: now, UpdatedAt: now})
+	}
+	_, err := repo.Create(context.Background(), saga)
+	assert.NoError(t, err)
+
+	found, err := repo.FindByReference(context.Background(), "ref-1")
+	assert.NoError(t, err)
+	assert.Equal(t, saga.Id, found.Id)
+	if assert.Len(t, found.Steps, 2) {
+		assert.Equal(t, entity.SagaStepCreateTransaction, found.Steps[0].Name)
+	}
+
+	_, err = repo.FindByReference(context.Background(), "ref-2")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"log"
+	gormConn "xyz-transaction-service/common/gorm"
+	"xyz-transaction-service/common/tracing"
+	"xyz-transaction-service/modules/transaction/entity"
+
+	"gorm.io/gorm"
+)
+
+// TransactionEventRepository only appends and reads; audit rows are never
+// updated or deleted.
+type TransactionEventRepository struct {
+	db *gorm.DB
+}
+
+func NewTransactionEventRepository(db *gorm.DB) *TransactionEventRepository {
+	return &TransactionEventRepository{
+		db: db,
+	}
+}
+
+type TransactionEventRepositoryUseCase interface {
+	FindByContractNumber(ctx context.Context, contractNumber string) ([]*entity.TransactionEvent, error)
+	Create(ctx context.Context, req *entity.TransactionEvent) error
+}
+
+func (t *TransactionEventRepository) FindByContractNumber(ctx context.Context, contractNumber string) ([]*entity.TransactionEvent, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "TransactionEventRepository - FindByContractNumber")
+	defer span.End()
+
+	var events []*entity.TransactionEvent
+	if err := gormConn.Conn(ctx, t.db).Debug().WithContext(ctxSpan).Where("contract_number = ?", contractNumber).Order("id asc").Find(&events).Error; err != nil {
+		log.Println("ERROR: [TransactionEventRepository - FindByContractNumber] Internal server error:", err)
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (t *TransactionEventRepository) Create(ctx context.Context, req *entity.TransactionEvent) error {
+	ctxSpan, span := tracing.StartSpan(ctx, "TransactionEventRepository - Create")
+	defer span.End()
+
+	if err := gormConn.Conn(ctx, t.db).Debug().WithContext(ctxSpan).Create(req).Error; err != nil {
+		log.Println("ERROR: [TransactionEventRepository - Create] Internal server error:", err)
+		return err
+	}
+
+	return nil
+}
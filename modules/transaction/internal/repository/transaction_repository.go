@@ -4,10 +4,11 @@ import (
 	"context"
 	"errors"
 	"log"
+	"time"
+	gormConn "xyz-transaction-service/common/gorm"
+	"xyz-transaction-service/common/tracing"
 	"xyz-transaction-service/modules/transaction/entity"
 
-	"github.com/go-sql-driver/mysql"
-	"go.opencensus.io/trace"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"gorm.io/gorm"
@@ -24,20 +25,69 @@ func NewTransactionRepository(db *gorm.DB) *TransactionRepository {
 }
 
 type TransactionRepositoryUseCase interface {
-	FindAll(ctx context.Context, req any) ([]*entity.Transaction, error)
-	FindByConsumerId(ctx context.Context, consumerId uint64) ([]*entity.Transaction, error)
+	FindAll(ctx context.Context, filter *entity.TransactionFilter, page *entity.TransactionPage) ([]*entity.Transaction, error)
+	FindByConsumerId(ctx context.Context, consumerId uint64, filter *entity.TransactionFilter) ([]*entity.Transaction, error)
 	FindById(ctx context.Context, id uint64) (*entity.Transaction, error)
 	FindByContractNumber(ctx context.Context, contractNumber string) (*entity.Transaction, error)
 	Create(ctx context.Context, req *entity.Transaction) (*entity.Transaction, error)
-	Delete(ctx context.Context, id uint64) error
+	UpdateStatus(ctx context.Context, id uint64, from, to string) error
+	Delete(ctx context.Context, id uint64, reason string) error
 }
 
-func (t *TransactionRepository) FindAll(ctx context.Context, req any) ([]*entity.Transaction, error) {
-	ctxSpan, span := trace.StartSpan(ctx, "TransactionRepository - FindAll")
+func applyTransactionFilter(query *gorm.DB, filter *entity.TransactionFilter) *gorm.DB {
+	if filter == nil {
+		return query
+	}
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.ConsumerId != 0 {
+		query = query.Where("consumer_id = ?", filter.ConsumerId)
+	}
+	if filter.Tenor != 0 {
+		query = query.Where("tenor = ?", filter.Tenor)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		query = query.Where("created_at < ?", filter.CreatedTo)
+	}
+	if filter.OtrMin != 0 {
+		query = query.Where("otr >= ?", filter.OtrMin)
+	}
+	if filter.OtrMax != 0 {
+		query = query.Where("otr <= ?", filter.OtrMax)
+	}
+	if filter.AssetName != "" {
+		query = query.Where("asset_name LIKE ?", "%"+filter.AssetName+"%")
+	}
+
+	return query
+}
+
+// applyTransactionPage seeks past the cursor in created_at desc, id desc
+// order, so deep pages cost the same as the first one.
+func applyTransactionPage(query *gorm.DB, page *entity.TransactionPage) *gorm.DB {
+	if page == nil {
+		return query.Order("created_at desc")
+	}
+
+	if page.After != nil {
+		query = query.Where("created_at < ? OR (created_at = ? AND id < ?)", page.After.CreatedAt, page.After.CreatedAt, page.After.Id)
+	}
+
+	return query.Order("created_at desc").Order("id desc").Limit(page.Size)
+}
+
+func (t *TransactionRepository) FindAll(ctx context.Context, filter *entity.TransactionFilter, page *entity.TransactionPage) ([]*entity.Transaction, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "TransactionRepository - FindAll")
 	defer span.End()
 
 	var transactions []*entity.Transaction
-	if err := t.db.Debug().WithContext(ctxSpan).Order("created_at desc").Find(&transactions).Error; err != nil {
+	query := applyTransactionFilter(gormConn.Conn(ctx, t.db).Debug().WithContext(ctxSpan), filter)
+	if err := applyTransactionPage(query, page).Find(&transactions).Error; err != nil {
 		log.Println("ERROR: [TransactionRepository - FindAll] Internal server error:", err)
 		return nil, err
 	}
@@ -45,12 +95,13 @@ func (t *TransactionRepository) FindAll(ctx context.Context, req any) ([]*entity
 	return transactions, nil
 }
 
-func (t *TransactionRepository) FindByConsumerId(ctx context.Context, consumerId uint64) ([]*entity.Transaction, error) {
-	ctxSpan, span := trace.StartSpan(ctx, "TransactionRepository - FindByConsumerId")
+func (t *TransactionRepository) FindByConsumerId(ctx context.Context, consumerId uint64, filter *entity.TransactionFilter) ([]*entity.Transaction, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "TransactionRepository - FindByConsumerId")
 	defer span.End()
 
 	var transactions []*entity.Transaction
-	if err := t.db.Debug().WithContext(ctxSpan).Where("consumer_id = ?", consumerId).Order("created_at desc").Find(&transactions).Error; err != nil {
+	query := applyTransactionFilter(gormConn.Conn(ctx, t.db).Debug().WithContext(ctxSpan).Where("consumer_id = ?", consumerId), filter)
+	if err := query.Order("created_at desc").Find(&transactions).Error; err != nil {
 		log.Println("ERROR: [TransactionRepository - FindByConsumerId] Internal server error:", err)
 		return nil, err
 	}
@@ -59,11 +110,11 @@ func (t *TransactionRepository) FindByConsumerId(ctx context.Context, consumerId
 }
 
 func (t *TransactionRepository) FindById(ctx context.Context, id uint64) (*entity.Transaction, error) {
-	ctxSpan, span := trace.StartSpan(ctx, "TransactionRepository - FindById")
+	ctxSpan, span := tracing.StartSpan(ctx, "TransactionRepository - FindById")
 	defer span.End()
 
 	var transaction entity.Transaction
-	if err := t.db.Debug().WithContext(ctxSpan).Where("id = ?", id).First(&transaction).Error; err != nil {
+	if err := gormConn.Conn(ctx, t.db).Debug().WithContext(ctxSpan).Where("id = ?", id).First(&transaction).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			log.Println("WARNING: [TransactionRepository - FindById] Transaction not found for id:", id)
 			return nil, status.Errorf(codes.NotFound, "Transaction not found for id: %v", id)
@@ -76,11 +127,11 @@ func (t *TransactionRepository) FindById(ctx context.Context, id uint64) (*entit
 }
 
 func (t *TransactionRepository) FindByContractNumber(ctx context.Context, contractNumber string) (*entity.Transaction, error) {
-	ctxSpan, span := trace.StartSpan(ctx, "TransactionRepository - FindByContractNumber")
+	ctxSpan, span := tracing.StartSpan(ctx, "TransactionRepository - FindByContractNumber")
 	defer span.End()
 
 	var transaction entity.Transaction
-	if err := t.db.Debug().WithContext(ctxSpan).Where("contract_number = ?", contractNumber).First(&transaction).Error; err != nil {
+	if err := gormConn.Conn(ctx, t.db).Debug().WithContext(ctxSpan).Where("contract_number = ?", contractNumber).First(&transaction).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			log.Println("WARNING: [TransactionRepository - FindByContractNumber] Transaction not found for contract number:", contractNumber)
 			return nil, status.Errorf(codes.NotFound, "Transaction not found for contract number: %v", contractNumber)
@@ -93,12 +144,11 @@ func (t *TransactionRepository) FindByContractNumber(ctx context.Context, contra
 }
 
 func (t *TransactionRepository) Create(ctx context.Context, req *entity.Transaction) (*entity.Transaction, error) {
-	ctxSpan, span := trace.StartSpan(ctx, "TransactionRepository - Create")
+	ctxSpan, span := tracing.StartSpan(ctx, "TransactionRepository - Create")
 	defer span.End()
 
-	if err := t.db.Debug().WithContext(ctxSpan).Create(req).Error; err != nil {
-		var mysqlErr *mysql.MySQLError
-		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+	if err := gormConn.Conn(ctx, t.db).Debug().WithContext(ctxSpan).Create(req).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
 			log.Println("WARNING: [TransactionRepository - Create] Transaction already exists for contract number:", req.ContractNumber)
 			return nil, status.Errorf(codes.AlreadyExists, "Transaction already exists for contract number: %v", req.ContractNumber)
 		}
@@ -109,13 +159,46 @@ func (t *TransactionRepository) Create(ctx context.Context, req *entity.Transact
 	return req, nil
 }
 
-func (t *TransactionRepository) Delete(ctx context.Context, id uint64) error {
-	ctxSpan, span := trace.StartSpan(ctx, "TransactionRepository - Delete")
+// UpdateStatus moves the row from one status to another. The current status is
+// part of the WHERE clause, so a concurrent change makes the update a no-op and
+// is reported as Aborted instead of being silently overwritten.
+func (t *TransactionRepository) UpdateStatus(ctx context.Context, id uint64, from, to string) error {
+	ctxSpan, span := tracing.StartSpan(ctx, "TransactionRepository - UpdateStatus")
 	defer span.End()
 
-	if err := t.db.Debug().WithContext(ctxSpan).Where("id = ?", id).Delete(&entity.Transaction{}).Error; err != nil {
-		log.Println("ERROR: [TransactionRepository - Delete] Internal server error:", err)
-		return err
+	res := gormConn.Conn(ctx, t.db).Debug().WithContext(ctxSpan).Model(&entity.Transaction{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(map[string]interface{}{"status": to, "updated_at": time.Now()})
+	if res.Error != nil {
+		log.Println("ERROR: [TransactionRepository - UpdateStatus] Internal server error:", res.Error)
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		log.Println("WARNING: [TransactionRepository - UpdateStatus] Transaction status changed concurrently for id:", id)
+		return status.Errorf(codes.Aborted, "Transaction status changed concurrently for id: %v", id)
+	}
+
+	return nil
+}
+
+// Delete soft-deletes the transaction, recording why it was removed.
+// Delete soft-deletes a transaction and returns NotFound when there is no live
+// row to delete. The row keeps its contract number in the unique index, so
+// the number of a deleted contract stays burned and is never issued again.
+func (t *TransactionRepository) Delete(ctx context.Context, id uint64, reason string) error {
+	ctxSpan, span := tracing.StartSpan(ctx, "TransactionRepository - Delete")
+	defer span.End()
+
+	res := gormConn.Conn(ctx, t.db).Debug().WithContext(ctxSpan).Model(&entity.Transaction{}).Where("id = ?", id).
+		Updates(map[string]any{"deleted_at": time.Now(), "deleted_reason": reason})
+	if res.Error != nil {
+		log.Println("ERROR: [TransactionRepository - Delete] Internal server error:", res.Error)
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return status.Errorf(codes.NotFound, "Transaction not found with id: %v", id)
 	}
 
 	return nil
@@ -5,11 +5,17 @@ import (
 	"regexp"
 	"testing"
 	"time"
+	gormConn "xyz-transaction-service/common/gorm"
+	"xyz-transaction-service/common/migration"
+	"xyz-transaction-service/migrations"
 	"xyz-transaction-service/modules/transaction/entity"
 	"xyz-transaction-service/modules/transaction/internal/repository"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	mysqlDriver "github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
@@ -23,23 +29,43 @@ func setupMockDB() (*gorm.DB, sqlmock.Sqlmock, error) {
 	gormDB, err := gorm.Open(mysql.New(mysql.Config{
 		Conn:                      db,
 		SkipInitializeWithVersion: true,
-	}), &gorm.Config{})
+	}), &gorm.Config{TranslateError: true})
 
 	return gormDB, mock, err
 }
 
+// setupSQLiteDB migrates a throwaway in-memory SQLite database, for the
+// behaviour that depends on the schema, such as its unique keys.
+func setupSQLiteDB(t *testing.T) *gorm.DB {
+	db, err := gormConn.NewSQLiteGormDB(":memory:")
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		_ = sqlDB.Close()
+	})
+
+	files, err := migrations.For(gormConn.DriverSQLite)
+	assert.NoError(t, err)
+	list, err := migration.Load(files)
+	assert.NoError(t, err)
+	_, err = migration.NewMigrator(db, list).Up(context.Background(), 0)
+	assert.NoError(t, err)
+
+	return db
+}
+
 func TestFindAll(t *testing.T) {
 	db, mock, err := setupMockDB()
 	assert.NoError(t, err)
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `transactions` ORDER BY created_at desc")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `transactions` WHERE `transactions`.`deleted_at` IS NULL ORDER BY created_at desc")).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "contract_number", "consumer_id", "tenor", "otr", "admin_fee", "installment", "interest", "asset_name"}).
 			AddRow(1, "CN123", 1, 3, 100000, 6000, 45000, 4000, "Smartphone").
 			AddRow(2, "CN124", 2, 6, 200000, 12000, 90000, 8000, "Laptop"))
 
 	repo := repository.NewTransactionRepository(db)
 
-	result, err := repo.FindAll(context.Background(), nil)
+	result, err := repo.FindAll(context.Background(), nil, nil)
 
 	assert.NoError(t, err)
 	assert.Len(t, result, 2)
@@ -60,7 +86,7 @@ func TestFindById(t *testing.T) {
 	db, mock, err := setupMockDB()
 	assert.NoError(t, err)
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `transactions` WHERE id = ? ORDER BY `transactions`.`id` LIMIT ?")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `transactions` WHERE id = ? AND `transactions`.`deleted_at` IS NULL ORDER BY `transactions`.`id` LIMIT ?")).
 		WithArgs(1, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "contract_number", "consumer_id", "tenor", "otr", "admin_fee", "installment", "interest", "asset_name"}).
 			AddRow(1, "CN124", 2, 6, 200000, 12000, 90000, 8000, "Laptop"))
@@ -91,8 +117,8 @@ func TestCreate(t *testing.T) {
 
 	mock.ExpectBegin()
 
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `transactions` (`contract_number`,`consumer_id`,`tenor`,`otr`,`admin_fee`,`installment`,`interest`,`asset_name`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?)")).
-		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `transactions` (`contract_number`,`consumer_id`,`tenor`,`otr`,`admin_fee`,`installment`,`interest`,`asset_name`,`status`,`product_code`,`product_version`,`created_at`,`updated_at`,`deleted_at`,`deleted_reason`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)")).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	mock.ExpectCommit()
@@ -108,6 +134,7 @@ func TestCreate(t *testing.T) {
 		Installment:    135000,
 		Interest:       12000,
 		AssetName:      "Smartwatch",
+		Status:         entity.TransactionStatusPending,
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
 	}
@@ -128,3 +155,151 @@ func TestCreate(t *testing.T) {
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
 }
+
+func TestCreateDuplicateContractNumber(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `transactions`")).
+		WillReturnError(&mysqlDriver.MySQLError{Number: 1062, Message: "Duplicate entry 'CN123' for key 'uk_transactions_contract_number'"})
+	mock.ExpectRollback()
+
+	repo := repository.NewTransactionRepository(db)
+
+	_, err = repo.Create(context.Background(), entity.NewTransactionEntity("CN123", 3, 12, 300000, 18000, 135000, 12000, "Motorcycle"))
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateDuplicateContractNumberSQLite(t *testing.T) {
+	repo := repository.NewTransactionRepository(setupSQLiteDB(t))
+
+	_, err := repo.Create(context.Background(), entity.NewTransactionEntity("CN123", 3, 12, 300000, 18000, 135000, 12000, "Motorcycle"))
+	assert.NoError(t, err)
+
+	_, err = repo.Create(context.Background(), entity.NewTransactionEntity("CN123", 4, 6, 100000, 6000, 45000, 4000, "Smartphone"))
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	found, err := repo.FindByContractNumber(context.Background(), "CN123")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), found.ConsumerId)
+}
+
+func TestFindAllFilteredByStatus(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `transactions` WHERE status = ? AND `transactions`.`deleted_at` IS NULL ORDER BY created_at desc")).
+		WithArgs(entity.TransactionStatusActive).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "contract_number", "status"}).
+			AddRow(1, "CN123", entity.TransactionStatusActive))
+
+	repo := repository.NewTransactionRepository(db)
+
+	result, err := repo.FindAll(context.Background(), &entity.TransactionFilter{Status: entity.TransactionStatusActive}, nil)
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, entity.TransactionStatusActive, result[0].Status)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestFindAllKeysetPage(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+
+	after := &entity.TransactionCursor{CreatedAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), Id: 29}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `transactions` WHERE tenor = ? AND otr >= ? AND asset_name LIKE ? AND (created_at < ? OR (created_at = ? AND id < ?)) AND `transactions`.`deleted_at` IS NULL ORDER BY created_at desc,id desc LIMIT ?")).
+		WithArgs(6, 1000, "%Laptop%", after.CreatedAt, after.CreatedAt, after.Id, 21).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "contract_number", "tenor"}).
+			AddRow(28, "CN128", 6))
+
+	repo := repository.NewTransactionRepository(db)
+
+	filter := &entity.TransactionFilter{Tenor: 6, OtrMin: 1000, AssetName: "Laptop"}
+	result, err := repo.FindAll(context.Background(), filter, &entity.TransactionPage{Size: 21, After: after})
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestDeleteIsSoft(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `transactions` SET `deleted_at`=?,`deleted_reason`=?,`updated_at`=? WHERE id = ? AND `transactions`.`deleted_at` IS NULL")).
+		WithArgs(sqlmock.AnyArg(), "limit debit failed", sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	repo := repository.NewTransactionRepository(db)
+
+	err = repo.Delete(context.Background(), 1, "limit debit failed")
+	assert.NoError(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestDeleteMissingRow(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `transactions` SET `deleted_at`=?,`deleted_reason`=?,`updated_at`=? WHERE id = ? AND `transactions`.`deleted_at` IS NULL")).
+		WithArgs(sqlmock.AnyArg(), "limit debit failed", sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	repo := repository.NewTransactionRepository(db)
+
+	err = repo.Delete(context.Background(), 1, "limit debit failed")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteKeepsContractNumberBurnedSQLite(t *testing.T) {
+	repo := repository.NewTransactionRepository(setupSQLiteDB(t))
+
+	created, err := repo.Create(context.Background(), entity.NewTransactionEntity("CN123", 3, 12, 300000, 18000, 135000, 12000, "Motorcycle"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.Delete(context.Background(), created.Id, "limit debit failed"))
+	assert.Equal(t, codes.NotFound, status.Code(repo.Delete(context.Background(), created.Id, "limit debit failed")))
+
+	_, err = repo.FindByContractNumber(context.Background(), "CN123")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// the number of the compensated contract is never issued again
+	_, err = repo.Create(context.Background(), entity.NewTransactionEntity("CN123", 4, 6, 100000, 6000, 45000, 4000, "Smartphone"))
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+}
+
+func TestUpdateStatusConcurrentChange(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `transactions` SET `status`=?,`updated_at`=? WHERE (id = ? AND status = ?) AND `transactions`.`deleted_at` IS NULL")).
+		WithArgs(entity.TransactionStatusActive, sqlmock.AnyArg(), 1, entity.TransactionStatusPending).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	repo := repository.NewTransactionRepository(db)
+
+	err = repo.UpdateStatus(context.Background(), 1, entity.TransactionStatusPending, entity.TransactionStatusActive)
+	assert.Error(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,219 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"xyz-transaction-service/common/validation"
+	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/modules/transaction/internal/pricing"
+	"xyz-transaction-service/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// sizes of the columns the values are stored in
+	maxAssetNameLength        = 255
+	maxPaymentReferenceLength = 128
+	maxProductCodeLength      = 64
+	maxProductNameLength      = 255
+
+	maxBps = 10000
+)
+
+type ContractNumberValidator interface {
+	ValidateContractNumber(contractNumber string) error
+}
+
+// RequestValidator checks the request messages of TransactionService before
+// any lookup, limit check or write happens. Every problem of a request is
+// reported at once, see validation.Violations.
+type RequestValidator struct {
+	contractNumberValidator ContractNumberValidator
+}
+
+func NewRequestValidator(contractNumberValidator ContractNumberValidator) *RequestValidator {
+	return &RequestValidator{
+		contractNumberValidator: contractNumberValidator,
+	}
+}
+
+type RequestValidatorUseCase interface {
+	Validate(req proto.Message) error
+}
+
+// Validate returns nil for a valid request and otherwise an InvalidArgument
+// status with a google.rpc.BadRequest detail listing the field violations.
+func (rv *RequestValidator) Validate(req proto.Message) error {
+	v := validation.NewViolations()
+
+	switch r := req.(type) {
+	case *pb.Transaction:
+		v.Check(r.ConsumerId > 0, "consumer_id", "must be greater than zero")
+		rv.contract(v, r.Tenor, r.Otr, r.AdminFee, r.Installment, r.Interest, r.AssetName)
+		v.MaxLength(r.ProductCode, maxProductCodeLength, "product_code")
+	case *pb.CreateMyTransactionRequest:
+		rv.contract(v, r.Tenor, r.Otr, r.AdminFee, r.Installment, r.Interest, r.AssetName)
+		v.MaxLength(r.ProductCode, maxProductCodeLength, "product_code")
+	case *pb.TransactionConsumerIdRequest:
+		v.Check(r.ConsumerId > 0, "consumer_id", "must be greater than zero")
+		rv.status(v, r.Status, "status")
+	case *pb.GetAllTransactionsRequest:
+		rv.filter(v.Nested("filter"), r.Filter)
+		if r.PageToken != "" {
+			_, err := entity.DecodeTransactionCursor(r.PageToken)
+			v.Check(err == nil, "page_token", "is not a token returned by this service")
+		}
+	case *pb.StreamTransactionsRequest:
+		rv.filter(v.Nested("filter"), r.Filter)
+	case *pb.ListMyTransactionsRequest:
+		rv.status(v, r.Status, "status")
+	case *pb.UpdateTransactionStatusRequest:
+		rv.contractNumber(v, r.ContractNumber)
+		v.Required(r.Status, "status")
+		rv.status(v, r.Status, "status")
+	case *pb.TransactionContractNumberRequest:
+		rv.contractNumber(v, r.ContractNumber)
+	case *pb.RecordPaymentRequest:
+		rv.contractNumber(v, r.ContractNumber)
+		v.Check(r.Amount > 0, "amount", "must be greater than zero")
+		v.Required(r.PaymentReference, "payment_reference")
+		v.MaxLength(r.PaymentReference, maxPaymentReferenceLength, "payment_reference")
+		v.Timestamp(r.PaidAt, "paid_at")
+	case *pb.Product:
+		rv.product(v, r)
+	case *pb.GetProductRequest:
+		v.Required(r.Code, "code")
+	case *pb.ListProductsRequest:
+		v.Timestamp(r.EffectiveAt, "effective_at")
+	case *pb.RetireProductRequest:
+		v.Required(r.Code, "code")
+		v.Timestamp(r.EffectiveTo, "effective_to")
+	default:
+		return status.Errorf(codes.Internal, "no validation rules for %T", req)
+	}
+
+	return v.Err()
+}
+
+// contract checks the terms shared by CreateTransaction and
+// CreateMyTransaction. Installment and interest are optional, but when the
+// client sends both they must reconcile with the principal and admin fee the
+// way server-side pricing does: installment * tenor = otr + admin_fee + interest.
+func (rv *RequestValidator) contract(v *validation.Violations, tenor uint32, otr, adminFee, installment, interest uint64, assetName string) {
+	v.Check(tenor > 0, "tenor", "must be greater than zero")
+	v.Check(otr > 0, "otr", "must be greater than zero")
+	if otr > pricing.MaxOtr {
+		v.Add("otr", "must be at most %d", uint64(pricing.MaxOtr))
+	}
+	v.Required(assetName, "asset_name")
+	v.MaxLength(assetName, maxAssetNameLength, "asset_name")
+
+	if tenor == 0 || installment == 0 || interest == 0 {
+		return
+	}
+	if installment > math.MaxUint64/uint64(tenor) || otr > math.MaxUint64-adminFee || otr+adminFee > math.MaxUint64-interest {
+		v.Add("installment", "is out of range")
+		return
+	}
+	total := installment * uint64(tenor)
+	if total != otr+adminFee+interest {
+		v.Add("installment", "times tenor must equal otr + admin_fee + interest (%d), got %d", otr+adminFee+interest, total)
+	}
+}
+
+// product checks a product about to be published. The version is assigned by
+// the service, and the admin fee fields must match the fee type.
+func (rv *RequestValidator) product(v *validation.Violations, product *pb.Product) {
+	v.Required(product.Code, "code")
+	v.MaxLength(product.Code, maxProductCodeLength, "code")
+	v.Check(isProductCode(product.Code), "code", "may only contain letters, digits, '-' and '_'")
+	v.Check(product.Version == 0, "version", "is assigned by the service")
+	v.Required(product.Name, "name")
+	v.MaxLength(product.Name, maxProductNameLength, "name")
+
+	switch pricing.Method(product.PricingMethod) {
+	case pricing.MethodFlat, pricing.MethodAnnuity:
+	default:
+		v.Add("pricing_method", "must be one of %s, %s", pricing.MethodFlat, pricing.MethodAnnuity)
+	}
+
+	switch product.AdminFeeType {
+	case entity.AdminFeeTypeFlat:
+		v.Check(product.AdminFeeBps == 0, "admin_fee_bps", "must be zero for a FLAT admin fee")
+		v.Check(product.AdminFeeMin == 0, "admin_fee_min", "must be zero for a FLAT admin fee")
+		v.Check(product.AdminFeeMax == 0, "admin_fee_max", "must be zero for a FLAT admin fee")
+	case entity.AdminFeeTypePercentage:
+		v.Check(product.AdminFeeAmount == 0, "admin_fee_amount", "must be zero for a PERCENTAGE admin fee")
+		v.Check(product.AdminFeeBps <= maxBps, "admin_fee_bps", "must be at most 10000")
+		if product.AdminFeeMax != 0 {
+			v.Check(product.AdminFeeMin <= product.AdminFeeMax, "admin_fee_max", "must not be less than admin_fee_min")
+		}
+	default:
+		v.Add("admin_fee_type", "must be one of %s, %s", entity.AdminFeeTypeFlat, entity.AdminFeeTypePercentage)
+	}
+
+	v.Check(len(product.Tenors) > 0, "tenors", "must offer at least one tenor")
+	seen := make(map[uint32]bool)
+	for i, tenor := range product.Tenors {
+		field := fmt.Sprintf("tenors[%d].tenor", i)
+		v.Check(tenor.GetTenor() > 0, field, "must be greater than zero")
+		v.Check(!seen[tenor.GetTenor()], field, "is listed more than once")
+		seen[tenor.GetTenor()] = true
+	}
+
+	effectiveFrom := v.Timestamp(product.EffectiveFrom, "effective_from")
+	effectiveTo := v.Timestamp(product.EffectiveTo, "effective_to")
+	if !effectiveTo.IsZero() {
+		if effectiveFrom.IsZero() && product.EffectiveFrom == "" {
+			effectiveFrom = time.Now()
+		}
+		v.Check(effectiveTo.After(effectiveFrom), "effective_to", "must be after effective_from")
+	}
+}
+
+func isProductCode(code string) bool {
+	for _, r := range code {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+func (rv *RequestValidator) filter(v *validation.Violations, filter *pb.TransactionFilter) {
+	if filter == nil {
+		return
+	}
+
+	rv.status(v, filter.Status, "status")
+	createdFrom := v.Timestamp(filter.CreatedFrom, "created_from")
+	createdTo := v.Timestamp(filter.CreatedTo, "created_to")
+	if !createdFrom.IsZero() && !createdTo.IsZero() {
+		v.Check(createdFrom.Before(createdTo), "created_to", "must be after created_from")
+	}
+	if filter.OtrMax != 0 {
+		v.Check(filter.OtrMin <= filter.OtrMax, "otr_max", "must not be less than otr_min")
+	}
+	v.MaxLength(filter.AssetName, maxAssetNameLength, "asset_name")
+}
+
+func (rv *RequestValidator) status(v *validation.Violations, value, field string) {
+	if value != "" && !entity.IsValidTransactionStatus(value) {
+		v.Add(field, "must be one of %s", strings.Join(entity.TransactionStatuses, ", "))
+	}
+}
+
+func (rv *RequestValidator) contractNumber(v *validation.Violations, contractNumber string) {
+	if contractNumber == "" {
+		v.Add("contract_number", "is required")
+		return
+	}
+	if err := rv.contractNumberValidator.ValidateContractNumber(contractNumber); err != nil {
+		v.Add("contract_number", "is not a well-formed contract number")
+	}
+}
@@ -0,0 +1,100 @@
+package validator_test
+
+import (
+	"testing"
+	"xyz-transaction-service/common/validation"
+	"xyz-transaction-service/modules/transaction/internal/validator"
+	"xyz-transaction-service/pb"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type fakeContractNumberValidator struct{}
+
+func (fakeContractNumberValidator) ValidateContractNumber(contractNumber string) error {
+	if contractNumber != "CN123" {
+		return status.Errorf(codes.InvalidArgument, "malformed contract number")
+	}
+	return nil
+}
+
+func violatedFields(err error) []string {
+	var fields []string
+	for _, violation := range validation.FieldViolations(err) {
+		fields = append(fields, violation.Field)
+	}
+	return fields
+}
+
+func TestValidateRequests(t *testing.T) {
+	rv := validator.NewRequestValidator(fakeContractNumberValidator{})
+
+	tests := []struct {
+		name   string
+		req    proto.Message
+		fields []string
+	}{
+		{"create valid", &pb.Transaction{ConsumerId: 1, Tenor: 12, Otr: 1000000, AssetName: "Laptop"}, nil},
+		{"create empty", &pb.Transaction{AssetName: "  "}, []string{"consumer_id", "tenor", "otr", "asset_name"}},
+		{"create reconciled", &pb.Transaction{ConsumerId: 1, Tenor: 10, Otr: 1000, AdminFee: 50, Installment: 120, Interest: 150, AssetName: "Laptop"}, nil},
+		{"create not adding up", &pb.Transaction{ConsumerId: 1, Tenor: 10, Otr: 1000, AdminFee: 50, Installment: 100, Interest: 150, AssetName: "Laptop"}, []string{"installment"}},
+		{"create overflowing", &pb.Transaction{ConsumerId: 1, Tenor: 10, Otr: 1000, Installment: 1 << 62, Interest: 150, AssetName: "Laptop"}, []string{"installment"}},
+		{"create otr too large", &pb.Transaction{ConsumerId: 1, Tenor: 12, Otr: 1 << 62, AssetName: "Laptop"}, []string{"otr"}},
+		{"create mine", &pb.CreateMyTransactionRequest{Tenor: 12, AssetName: "Laptop"}, []string{"otr"}},
+		{"by consumer", &pb.TransactionConsumerIdRequest{Status: "LOST"}, []string{"consumer_id", "status"}},
+		{"get all valid", &pb.GetAllTransactionsRequest{Filter: &pb.TransactionFilter{Status: "ACTIVE", CreatedFrom: "2024-01-01T00:00:00Z"}}, nil},
+		{"get all", &pb.GetAllTransactionsRequest{
+			Filter:    &pb.TransactionFilter{CreatedFrom: "2024-02-01T00:00:00Z", CreatedTo: "2024-01-01T00:00:00Z", OtrMin: 10, OtrMax: 5},
+			PageToken: "not-a-token",
+		}, []string{"filter.created_to", "filter.otr_max", "page_token"}},
+		{"stream", &pb.StreamTransactionsRequest{Filter: &pb.TransactionFilter{CreatedTo: "tomorrow"}}, []string{"filter.created_to"}},
+		{"list mine", &pb.ListMyTransactionsRequest{Status: "active"}, []string{"status"}},
+		{"update status", &pb.UpdateTransactionStatusRequest{ContractNumber: "CN999"}, []string{"contract_number", "status"}},
+		{"by contract number", &pb.TransactionContractNumberRequest{}, []string{"contract_number"}},
+		{"by contract number valid", &pb.TransactionContractNumberRequest{ContractNumber: "CN123"}, nil},
+		{"record payment", &pb.RecordPaymentRequest{ContractNumber: "CN123", PaidAt: "today"}, []string{"amount", "payment_reference", "paid_at"}},
+		{"create product valid", &pb.Product{
+			Code: "MOTOR-2024", Name: "Motorcycle", PricingMethod: "annuity",
+			AdminFeeType: "PERCENTAGE", AdminFeeBps: 150, AdminFeeMin: 100000, AdminFeeMax: 500000,
+			Tenors:        []*pb.ProductTenor{{Tenor: 6, AnnualRateBps: 1200}, {Tenor: 12, AnnualRateBps: 1400}},
+			EffectiveFrom: "2024-01-01T00:00:00Z",
+		}, nil},
+		{"create product", &pb.Product{
+			Code: "motor 2024", Version: 2, PricingMethod: "balloon",
+			AdminFeeType: "FLAT", AdminFeeAmount: 50000, AdminFeeBps: 100,
+			Tenors:        []*pb.ProductTenor{{Tenor: 6}, {Tenor: 0}, {Tenor: 6}},
+			EffectiveFrom: "2024-02-01T00:00:00Z", EffectiveTo: "2024-01-01T00:00:00Z",
+		}, []string{"code", "version", "name", "pricing_method", "admin_fee_bps", "tenors[1].tenor", "tenors[2].tenor", "effective_to"}},
+		{"create product fee range", &pb.Product{
+			Code: "MOTOR", Name: "Motorcycle", PricingMethod: "flat",
+			AdminFeeType: "PERCENTAGE", AdminFeeBps: 20000, AdminFeeMin: 500, AdminFeeMax: 100,
+		}, []string{"admin_fee_bps", "admin_fee_max", "tenors"}},
+		{"get product", &pb.GetProductRequest{}, []string{"code"}},
+		{"list products", &pb.ListProductsRequest{EffectiveAt: "now"}, []string{"effective_at"}},
+		{"retire product", &pb.RetireProductRequest{Code: "MOTOR", EffectiveTo: "2024-13-01"}, []string{"effective_to"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := rv.Validate(tt.req)
+
+			if tt.fields == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			assert.Equal(t, tt.fields, violatedFields(err))
+		})
+	}
+}
+
+func TestValidateUnknownRequest(t *testing.T) {
+	rv := validator.NewRequestValidator(fakeContractNumberValidator{})
+
+	err := rv.Validate(&pb.TransactionResponse{})
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"xyz-transaction-service/common/authorization"
+	commonJwt "xyz-transaction-service/common/jwt"
+	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/modules/transaction/internal/repository"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ConsumerAccessGuard enforces row-level ownership: a token bound to a
+// consumer only reaches that consumer's contracts, while roles granted
+// authorization.PermissionAnyConsumer reach every consumer. Denials are
+// written to transaction_events.
+type ConsumerAccessGuard struct {
+	policy  *authorization.Policy
+	auditor *TransactionAuditor
+}
+
+func NewConsumerAccessGuard(policy *authorization.Policy, transactionEventRepository repository.TransactionEventRepositoryUseCase) *ConsumerAccessGuard {
+	return &ConsumerAccessGuard{
+		policy:  policy,
+		auditor: NewTransactionAuditor(transactionEventRepository),
+	}
+}
+
+type ConsumerAccessGuardUseCase interface {
+	AuthorizeConsumer(ctx context.Context, method string, consumerId uint64) error
+	AuthorizeTransaction(ctx context.Context, method string, transaction *entity.Transaction) error
+}
+
+// AuthorizeConsumer checks access to the contracts of a consumer.
+func (g *ConsumerAccessGuard) AuthorizeConsumer(ctx context.Context, method string, consumerId uint64) error {
+	return g.authorize(ctx, method, consumerId, nil)
+}
+
+// AuthorizeTransaction checks access to a single contract.
+func (g *ConsumerAccessGuard) AuthorizeTransaction(ctx context.Context, method string, transaction *entity.Transaction) error {
+	return g.authorize(ctx, method, transaction.ConsumerId, transaction)
+}
+
+func (g *ConsumerAccessGuard) authorize(ctx context.Context, method string, consumerId uint64, transaction *entity.Transaction) error {
+	claims, ok := commonJwt.FromContext(ctx)
+	if ok && g.policy != nil && g.policy.HasPermission(claims.Role, authorization.PermissionAnyConsumer) {
+		return nil
+	}
+	if ok && claims.ConsumerId != 0 && claims.ConsumerId == consumerId {
+		return nil
+	}
+
+	reason := fmt.Sprintf("%s denied: consumer %d is not accessible to the caller", method, consumerId)
+	if ok {
+		reason = fmt.Sprintf("%s denied: token of consumer %d requested consumer %d", method, claims.ConsumerId, consumerId)
+	}
+	log.Println("WARNING: [ConsumerAccessGuard - Authorize]", reason)
+	g.auditor.RecordDenial(ctx, transaction, reason)
+
+	return status.Errorf(codes.PermissionDenied, "no permission to access transactions of consumer %d", consumerId)
+}
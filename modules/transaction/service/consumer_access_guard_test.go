@@ -0,0 +1,71 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"xyz-transaction-service/common/authorization"
+	commonJwt "xyz-transaction-service/common/jwt"
+	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/modules/transaction/service"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const guardPolicy = `
+roles:
+  staff:
+    id: 2
+    permissions: [transactions:any_consumer]
+  consumer:
+    id: 3
+`
+
+func newConsumerAccessGuard(t *testing.T) (*service.ConsumerAccessGuard, *MockTransactionEventRepository) {
+	policy, err := authorization.ParsePolicy([]byte(guardPolicy), ".yaml")
+	assert.NoError(t, err)
+
+	mockEventRepo := newMockTransactionEventRepository()
+	return service.NewConsumerAccessGuard(policy, mockEventRepo), mockEventRepo
+}
+
+func TestConsumerAccessGuardAllowsOwner(t *testing.T) {
+	guard, mockEventRepo := newConsumerAccessGuard(t)
+
+	ctx := commonJwt.NewContext(context.Background(), &commonJwt.CustomClaims{Cred: "budi", Role: 3, ConsumerId: 7})
+
+	assert.NoError(t, guard.AuthorizeConsumer(ctx, "/xyz_grpc.TransactionService/GetTransactionsByConsumerId", 7))
+	assert.NoError(t, guard.AuthorizeTransaction(ctx, "/xyz_grpc.TransactionService/GetTransactionByContractNumber", &entity.Transaction{ConsumerId: 7}))
+	mockEventRepo.AssertNotCalled(t, "Create")
+}
+
+func TestConsumerAccessGuardDeniesOtherConsumer(t *testing.T) {
+	guard, mockEventRepo := newConsumerAccessGuard(t)
+
+	ctx := commonJwt.NewContext(context.Background(), &commonJwt.CustomClaims{Cred: "budi", Role: 3, ConsumerId: 7})
+
+	err := guard.AuthorizeTransaction(ctx, "/xyz_grpc.TransactionService/GetTransactionByContractNumber", &entity.Transaction{Id: 4, ContractNumber: "CN123", ConsumerId: 8})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	event := mockEventRepo.Calls[0].Arguments.Get(1).(*entity.TransactionEvent)
+	assert.Equal(t, entity.TransactionEventAccessDenied, event.EventType)
+	assert.Equal(t, "CN123", event.ContractNumber)
+	assert.Equal(t, "budi", event.Actor)
+	assert.Contains(t, event.Reason, "token of consumer 7 requested consumer 8")
+}
+
+func TestConsumerAccessGuardStaffCrossConsumer(t *testing.T) {
+	guard, _ := newConsumerAccessGuard(t)
+
+	ctx := commonJwt.NewContext(context.Background(), &commonJwt.CustomClaims{Cred: "ops", Role: 2})
+
+	assert.NoError(t, guard.AuthorizeConsumer(ctx, "/xyz_grpc.TransactionService/CreateTransaction", 8))
+}
+
+func TestConsumerAccessGuardDeniesWithoutClaims(t *testing.T) {
+	guard, _ := newConsumerAccessGuard(t)
+
+	err := guard.AuthorizeConsumer(context.Background(), "/xyz_grpc.TransactionService/CreateTransaction", 8)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
@@ -0,0 +1,342 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"xyz-transaction-service/common/config"
+	commonErr "xyz-transaction-service/common/error"
+	"xyz-transaction-service/common/metrics"
+	"xyz-transaction-service/common/tracing"
+	"xyz-transaction-service/modules/transaction/client"
+	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/modules/transaction/internal/numbering"
+	"xyz-transaction-service/modules/transaction/internal/repository"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateTransactionSaga books a contract across this service and the consumer
+// limit service. Every step is persisted before and after it runs, so a saga
+// interrupted by a crash can be finished or compensated by the recovery worker:
+//
+//	CREATE_TRANSACTION   -> compensated by deleting the pending transaction
+//	DEBIT_LIMIT          -> compensated by RestoreAvailableLimit
+//	ACTIVATE_TRANSACTION -> last step, nothing to compensate
+//
+// The saga reference is sent with both limit calls, which the limit service
+// must treat idempotently, so replaying or compensating a step whose outcome is
+// unknown is always safe.
+//
+// Once the saga is persisted its outcome belongs to the saga: a failure to
+// save its progress is reported as ErrSagaPending, never as a failed request.
+type CreateTransactionSaga struct {
+	cfg              config.Config
+	sagaRepository   repository.SagaRepositoryUseCase
+	transactionSvc   TransactionServiceUseCase
+	consumerLimitSvc client.ConsumerLimitServiceClient
+}
+
+const (
+	checkpointAttempts = 3
+	checkpointBackoff  = 50 * time.Millisecond
+)
+
+// ErrSagaPending is returned together with the saga, and the transaction when
+// it was booked, when the saga could not be driven to a terminal state but
+// will be by the recovery worker. Callers must report the contract as pending.
+var ErrSagaPending = errors.New("saga pending")
+
+func NewCreateTransactionSaga(cfg config.Config, sagaRepository repository.SagaRepositoryUseCase, transactionSvc TransactionServiceUseCase, consumerLimitSvc client.ConsumerLimitServiceClient) *CreateTransactionSaga {
+	return &CreateTransactionSaga{
+		cfg:              cfg,
+		sagaRepository:   sagaRepository,
+		transactionSvc:   transactionSvc,
+		consumerLimitSvc: consumerLimitSvc,
+	}
+}
+
+type CreateTransactionSagaUseCase interface {
+	FindByReference(ctx context.Context, reference string) (*entity.Saga, error)
+	Execute(ctx context.Context, reference string, consumerId uint64, productCode string, productVersion uint32, tenor uint32, otr, adminFee, installment, interest uint64, assetName string) (*entity.Saga, *entity.Transaction, error)
+	Resume(ctx context.Context, saga *entity.Saga) error
+}
+
+// FindByReference returns the saga started under reference.
+func (s *CreateTransactionSaga) FindByReference(ctx context.Context, reference string) (*entity.Saga, error) {
+	return s.sagaRepository.FindByReference(ctx, reference)
+}
+
+// Execute runs a new saga under reference, which the limit service sees on
+// both limit calls. The saga is nil when it could not be persisted, so nothing
+// was booked.
+func (s *CreateTransactionSaga) Execute(ctx context.Context, reference string, consumerId uint64, productCode string, productVersion uint32, tenor uint32, otr, adminFee, installment, interest uint64, assetName string) (*entity.Saga, *entity.Transaction, error) {
+	// a caller hanging up must not leave the saga half-way through its steps
+	ctx = context.WithoutCancel(ctx)
+	ctx, span := tracing.StartSpan(ctx, "CreateTransactionSaga - Execute")
+	defer span.End()
+
+	contractNumber, err := s.transactionSvc.GenerateContractNumber(ctx, consumerId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	saga := &entity.Saga{
+		SagaType:       entity.SagaTypeCreateTransaction,
+		Reference:      reference,
+		ContractNumber: contractNumber,
+		ConsumerId:     consumerId,
+		Tenor:          tenor,
+		Otr:            otr,
+		AdminFee:       adminFee,
+		Installment:    installment,
+		Interest:       interest,
+		AssetName:      assetName,
+		ProductCode:    productCode,
+		ProductVersion: productVersion,
+		Status:         entity.SagaStatusStarted,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	for i, name := range []string{entity.SagaStepCreateTransaction, entity.SagaStepDebitLimit, entity.SagaStepActivateTransaction} {
+		saga.Steps = append(saga.Steps, &entity.SagaStep{
+			Name:      name,
+			Sequence:  uint32(i + 1),
+			Status:    entity.SagaStepStatusPending,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+
+	if _, err := s.sagaRepository.Create(ctx, saga); err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [CreateTransactionSaga - Execute] Error while create saga:", parseError.Message)
+		return nil, nil, err
+	}
+
+	transaction, err := s.run(ctx, saga)
+	return saga, transaction, err
+}
+
+// Resume drives a saga loaded from storage to a terminal state.
+func (s *CreateTransactionSaga) Resume(ctx context.Context, saga *entity.Saga) error {
+	ctx, span := tracing.StartSpan(ctx, "CreateTransactionSaga - Resume")
+	defer span.End()
+
+	if saga.Status == entity.SagaStatusCompensating {
+		return s.compensate(ctx, saga, nil)
+	}
+
+	_, err := s.run(ctx, saga)
+	return err
+}
+
+func (s *CreateTransactionSaga) run(ctx context.Context, saga *entity.Saga) (*entity.Transaction, error) {
+	var transaction *entity.Transaction
+
+	for _, step := range saga.Steps {
+		if step.Status == entity.SagaStepStatusDone {
+			continue
+		}
+
+		step.Status = entity.SagaStepStatusInProgress
+		step.Error = ""
+		if err := s.checkpoint(ctx, saga, func() error { return s.sagaRepository.UpdateStep(ctx, step) }); err != nil {
+			return transaction, err
+		}
+
+		stepCtx, span := tracing.StartSpan(ctx, "CreateTransactionSaga - "+step.Name)
+		res, err := s.execute(stepCtx, saga, step.Name)
+		tracing.RecordError(span, err)
+		span.End()
+		if err != nil {
+			parseError := commonErr.ParseError(err)
+			log.Printf("ERROR: [CreateTransactionSaga - run] Step %s failed for saga %s: %s\n", step.Name, saga.Reference, parseError.Message)
+
+			step.Status = entity.SagaStepStatusFailed
+			step.Error = err.Error()
+			_ = s.sagaRepository.UpdateStep(ctx, step)
+
+			if compErr := s.compensate(ctx, saga, err); compErr != nil {
+				log.Printf("ERROR: [CreateTransactionSaga - run] Compensation postponed for saga %s: %v\n", saga.Reference, compErr)
+			}
+			return nil, err
+		}
+		if res != nil {
+			transaction = res
+		}
+
+		// the step has run and cannot be taken back by a failed write, so
+		// from here on bookkeeping errors leave the saga pending
+		if step.Name == entity.SagaStepCreateTransaction {
+			saga.TransactionId = transaction.Id
+			if err := s.checkpoint(ctx, saga, func() error { return s.sagaRepository.Update(ctx, saga) }); err != nil {
+				return transaction, err
+			}
+		}
+		step.Status = entity.SagaStepStatusDone
+		if err := s.checkpoint(ctx, saga, func() error { return s.sagaRepository.UpdateStep(ctx, step) }); err != nil {
+			return transaction, err
+		}
+	}
+
+	saga.Status = entity.SagaStatusCompleted
+	saga.LastError = ""
+	if err := s.checkpoint(ctx, saga, func() error { return s.sagaRepository.Update(ctx, saga) }); err != nil {
+		return transaction, err
+	}
+	metrics.ContractCreated(saga.Otr)
+
+	return transaction, nil
+}
+
+// checkpoint saves the progress of a persisted saga, retrying briefly. A save
+// that keeps failing does not fail the saga: the recovery worker resumes it
+// from the last saved step, so ErrSagaPending is returned instead.
+func (s *CreateTransactionSaga) checkpoint(ctx context.Context, saga *entity.Saga, save func() error) error {
+	var err error
+	for attempt := 1; attempt <= checkpointAttempts; attempt++ {
+		if err = save(); err == nil {
+			return nil
+		}
+		if attempt < checkpointAttempts {
+			time.Sleep(time.Duration(attempt) * checkpointBackoff)
+		}
+	}
+
+	log.Printf("ERROR: [CreateTransactionSaga - checkpoint] Progress of saga %s not saved, leaving it to recovery: %v\n", saga.Reference, err)
+	return fmt.Errorf("%w: %v", ErrSagaPending, err)
+}
+
+func (s *CreateTransactionSaga) execute(ctx context.Context, saga *entity.Saga, name string) (*entity.Transaction, error) {
+	switch name {
+	case entity.SagaStepCreateTransaction:
+		return s.createTransaction(ctx, saga)
+
+	case entity.SagaStepDebitLimit:
+		_, err := s.consumerLimitSvc.UpdateAvailableLimit(ctx, saga.ConsumerId, saga.Tenor, saga.Otr, saga.Reference)
+		return nil, err
+
+	case entity.SagaStepActivateTransaction:
+		transaction, err := s.findTransaction(ctx, saga)
+		if err != nil {
+			return nil, err
+		}
+		if transaction.Status == entity.TransactionStatusActive {
+			return transaction, nil
+		}
+		return s.transactionSvc.UpdateStatus(ctx, saga.ContractNumber, entity.TransactionStatusActive)
+	}
+
+	return nil, status.Errorf(codes.Internal, "unknown saga step: %s", name)
+}
+
+// createTransaction books the pending transaction under the reserved contract
+// number. A number taken by another contract is replaced, and the saga saved,
+// before the next attempt, so a replay always looks for the right number.
+func (s *CreateTransactionSaga) createTransaction(ctx context.Context, saga *entity.Saga) (*entity.Transaction, error) {
+	attempts := numbering.MaxAttempts(s.cfg.ContractNumber)
+	for attempt := 1; ; attempt++ {
+		transaction, err := s.findTransaction(ctx, saga)
+		if status.Code(err) == codes.NotFound {
+			transaction, err = s.transactionSvc.Create(ctx, saga.ContractNumber, saga.ConsumerId, saga.ProductCode, saga.ProductVersion, saga.Tenor, saga.Otr, saga.AdminFee, saga.Installment, saga.Interest, saga.AssetName)
+		}
+		if status.Code(err) != codes.AlreadyExists {
+			return transaction, err
+		}
+		if attempt >= attempts {
+			return nil, status.Errorf(codes.Aborted, "could not allocate a unique contract number, please retry")
+		}
+
+		log.Printf("WARNING: [CreateTransactionSaga - createTransaction] Contract number %s of saga %s collided, attempt %d of %d\n", saga.ContractNumber, saga.Reference, attempt, attempts)
+		if saga.ContractNumber, err = s.transactionSvc.GenerateContractNumber(ctx, saga.ConsumerId); err != nil {
+			return nil, err
+		}
+		if err := s.sagaRepository.Update(ctx, saga); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// findTransaction returns the transaction booked by the saga. Until its id is
+// saved the reserved contract number is looked up, and a contract under that
+// number the saga did not book is reported as AlreadyExists.
+func (s *CreateTransactionSaga) findTransaction(ctx context.Context, saga *entity.Saga) (*entity.Transaction, error) {
+	if saga.TransactionId != 0 {
+		return s.transactionSvc.FindById(ctx, saga.TransactionId)
+	}
+
+	transaction, err := s.transactionSvc.FindByContractNumber(ctx, saga.ContractNumber)
+	if err != nil {
+		return nil, err
+	}
+	if !saga.Booked(transaction) {
+		return nil, status.Errorf(codes.AlreadyExists, "contract number %s belongs to another contract", saga.ContractNumber)
+	}
+
+	return transaction, nil
+}
+
+func (s *CreateTransactionSaga) compensate(ctx context.Context, saga *entity.Saga, cause error) error {
+	saga.Status = entity.SagaStatusCompensating
+	if cause != nil {
+		saga.LastError = cause.Error()
+	}
+	if err := s.sagaRepository.Update(ctx, saga); err != nil {
+		return err
+	}
+
+	for i := len(saga.Steps) - 1; i >= 0; i-- {
+		step := saga.Steps[i]
+		if step.Status == entity.SagaStepStatusPending || step.Status == entity.SagaStepStatusCompensated {
+			continue
+		}
+
+		undoCtx, span := tracing.StartSpan(ctx, "CreateTransactionSaga - Compensate "+step.Name)
+		err := s.undo(undoCtx, saga, step.Name)
+		tracing.RecordError(span, err)
+		span.End()
+		if err != nil {
+			saga.LastError = err.Error()
+			_ = s.sagaRepository.Update(ctx, saga)
+			return err
+		}
+
+		step.Status = entity.SagaStepStatusCompensated
+		if err := s.sagaRepository.UpdateStep(ctx, step); err != nil {
+			return err
+		}
+	}
+
+	saga.Status = entity.SagaStatusCompensated
+	return s.sagaRepository.Update(ctx, saga)
+}
+
+func (s *CreateTransactionSaga) undo(ctx context.Context, saga *entity.Saga, name string) error {
+	switch name {
+	case entity.SagaStepCreateTransaction:
+		transaction, err := s.findTransaction(ctx, saga)
+		if status.Code(err) == codes.NotFound || status.Code(err) == codes.AlreadyExists {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		err = s.transactionSvc.Rollback(ctx, transaction.Id, "create transaction saga "+saga.Reference+" compensated: "+saga.LastError)
+		if status.Code(err) == codes.NotFound {
+			// rolled back by an earlier run of the compensation
+			return nil
+		}
+		return err
+
+	case entity.SagaStepDebitLimit:
+		_, err := s.consumerLimitSvc.RestoreAvailableLimit(ctx, saga.ConsumerId, saga.Tenor, saga.Otr, saga.Reference)
+		return err
+	}
+
+	return nil
+}
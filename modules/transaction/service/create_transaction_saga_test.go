@@ -0,0 +1,392 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"xyz-transaction-service/common/config"
+	gormConn "xyz-transaction-service/common/gorm"
+	"xyz-transaction-service/common/migration"
+	"xyz-transaction-service/migrations"
+	"xyz-transaction-service/modules/transaction/client"
+	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/modules/transaction/internal/pricing"
+	"xyz-transaction-service/modules/transaction/internal/repository"
+	"xyz-transaction-service/modules/transaction/service"
+	"xyz-transaction-service/pb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Mock for SagaRepositoryUseCase
+type MockSagaRepository struct {
+	mock.Mock
+}
+
+func (m *MockSagaRepository) FindPending(ctx context.Context, updatedBefore time.Time, limit int) ([]*entity.Saga, error) {
+	args := m.Called(ctx, updatedBefore, limit)
+	return args.Get(0).([]*entity.Saga), args.Error(1)
+}
+
+func (m *MockSagaRepository) FindByReference(ctx context.Context, reference string) (*entity.Saga, error) {
+	args := m.Called(ctx, reference)
+	saga, _ := args.Get(0).(*entity.Saga)
+	return saga, args.Error(1)
+}
+
+func (m *MockSagaRepository) Create(ctx context.Context, saga *entity.Saga) (*entity.Saga, error) {
+	args := m.Called(ctx, saga)
+	return saga, args.Error(0)
+}
+
+func (m *MockSagaRepository) Update(ctx context.Context, saga *entity.Saga) error {
+	return m.Called(ctx, saga).Error(0)
+}
+
+func (m *MockSagaRepository) UpdateStep(ctx context.Context, step *entity.SagaStep) error {
+	return m.Called(ctx, step).Error(0)
+}
+
+func (m *MockSagaRepository) Claim(ctx context.Context, saga *entity.Saga) (bool, error) {
+	args := m.Called(ctx, saga)
+	return args.Bool(0), args.Error(1)
+}
+
+// Mock for TransactionServiceUseCase
+type MockTransactionService struct {
+	mock.Mock
+}
+
+func (m *MockTransactionService) FindAll(ctx context.Context, filter *entity.TransactionFilter, pageSize uint32, pageToken string) ([]*entity.Transaction, string, error) {
+	args := m.Called(ctx, filter, pageSize, pageToken)
+	return args.Get(0).([]*entity.Transaction), args.String(1), args.Error(2)
+}
+
+func (m *MockTransactionService) Stream(ctx context.Context, filter *entity.TransactionFilter, batchSize uint32, send func(*entity.Transaction) error) error {
+	return m.Called(ctx, filter, batchSize, send).Error(0)
+}
+
+func (m *MockTransactionService) FindByConsumerId(ctx context.Context, consumerId uint64, filter *entity.TransactionFilter) ([]*entity.Transaction, error) {
+	args := m.Called(ctx, consumerId, filter)
+	return args.Get(0).([]*entity.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionService) FindById(ctx context.Context, id uint64) (*entity.Transaction, error) {
+	args := m.Called(ctx, id)
+	transaction, _ := args.Get(0).(*entity.Transaction)
+	return transaction, args.Error(1)
+}
+
+func (m *MockTransactionService) FindByContractNumber(ctx context.Context, contractNumber string) (*entity.Transaction, error) {
+	args := m.Called(ctx, contractNumber)
+	transaction, _ := args.Get(0).(*entity.Transaction)
+	return transaction, args.Error(1)
+}
+
+func (m *MockTransactionService) Quote(ctx context.Context, productCode string, productVersion uint32, otr uint64, tenor uint32, adminFee, installment, interest uint64) (*pricing.Quote, error) {
+	args := m.Called(ctx, productCode, productVersion, otr, tenor, adminFee, installment, interest)
+	quote, _ := args.Get(0).(*pricing.Quote)
+	return quote, args.Error(1)
+}
+
+func (m *MockTransactionService) GenerateContractNumber(ctx context.Context, consumerId uint64) (string, error) {
+	args := m.Called(ctx, consumerId)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTransactionService) ValidateContractNumber(contractNumber string) error {
+	args := m.Called(contractNumber)
+	return args.Error(0)
+}
+
+func (m *MockTransactionService) Create(ctx context.Context, contractNumber string, consumerId uint64, productCode string, productVersion uint32, tenor uint32, otr, adminFee, installment, interest uint64, assetName string) (*entity.Transaction, error) {
+	args := m.Called(ctx, contractNumber, consumerId, productCode, productVersion, tenor, otr, adminFee, installment, interest, assetName)
+	transaction, _ := args.Get(0).(*entity.Transaction)
+	return transaction, args.Error(1)
+}
+
+func (m *MockTransactionService) UpdateStatus(ctx context.Context, contractNumber string, newStatus string) (*entity.Transaction, error) {
+	args := m.Called(ctx, contractNumber, newStatus)
+	transaction, _ := args.Get(0).(*entity.Transaction)
+	return transaction, args.Error(1)
+}
+
+func (m *MockTransactionService) Rollback(ctx context.Context, id uint64, reason string) error {
+	return m.Called(ctx, id, reason).Error(0)
+}
+
+func (m *MockTransactionService) FindHistoryByContractNumber(ctx context.Context, contractNumber string) ([]*entity.TransactionEvent, error) {
+	args := m.Called(ctx, contractNumber)
+	return args.Get(0).([]*entity.TransactionEvent), args.Error(1)
+}
+
+func (m *MockTransactionService) FindInstallmentsByTransactionId(ctx context.Context, transactionId uint64) ([]*entity.Installment, error) {
+	args := m.Called(ctx, transactionId)
+	return args.Get(0).([]*entity.Installment), args.Error(1)
+}
+
+// Mock for pb.ConsumerLimitServiceClient
+type MockConsumerLimitClient struct {
+	mock.Mock
+}
+
+func (m *MockConsumerLimitClient) GetConsumerLimitsByConsumerId(ctx context.Context, in *pb.ConsumerRequest, opts ...grpc.CallOption) (*pb.ConsumerLimitListResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*pb.ConsumerLimitListResponse), args.Error(1)
+}
+
+func (m *MockConsumerLimitClient) CreateConsumerLimit(ctx context.Context, in *pb.ConsumerLimit, opts ...grpc.CallOption) (*pb.ConsumerLimitResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*pb.ConsumerLimitResponse), args.Error(1)
+}
+
+func (m *MockConsumerLimitClient) UpdateAvailableLimit(ctx context.Context, in *pb.UpdateAvailableLimitRequest, opts ...grpc.CallOption) (*pb.ConsumerLimitResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*pb.ConsumerLimitResponse), args.Error(1)
+}
+
+func (m *MockConsumerLimitClient) RestoreAvailableLimit(ctx context.Context, in *pb.UpdateAvailableLimitRequest, opts ...grpc.CallOption) (*pb.ConsumerLimitResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*pb.ConsumerLimitResponse), args.Error(1)
+}
+
+func (m *MockConsumerLimitClient) GetConsumerLimitByConsumerIdAndTenor(ctx context.Context, in *pb.ConsumerIdAndTenorRequest, opts ...grpc.CallOption) (*pb.ConsumerLimitResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*pb.ConsumerLimitResponse), args.Error(1)
+}
+
+func newSagaMocks() (*MockSagaRepository, *MockTransactionService, *MockConsumerLimitClient) {
+	sagaRepo := new(MockSagaRepository)
+	sagaRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	sagaRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	sagaRepo.On("UpdateStep", mock.Anything, mock.Anything).Return(nil)
+
+	return sagaRepo, new(MockTransactionService), new(MockConsumerLimitClient)
+}
+
+func TestCreateTransactionSagaExecute(t *testing.T) {
+	sagaRepo, transactionSvc, limitClient := newSagaMocks()
+
+	pending := &entity.Transaction{Id: 9, ContractNumber: "CN123", Status: entity.TransactionStatusPending}
+	active := &entity.Transaction{Id: 9, ContractNumber: "CN123", Status: entity.TransactionStatusActive}
+
+	transactionSvc.On("GenerateContractNumber", mock.Anything, uint64(3)).Return("CN123", nil)
+	transactionSvc.On("FindByContractNumber", mock.Anything, "CN123").Return(nil, status.Error(codes.NotFound, "not found")).Once()
+	transactionSvc.On("Create", mock.Anything, "CN123", uint64(3), "", uint32(0), uint32(12), uint64(1000000), uint64(0), uint64(0), uint64(0), "Laptop").Return(pending, nil)
+	limitClient.On("UpdateAvailableLimit", mock.Anything, mock.Anything).Return(&pb.ConsumerLimitResponse{}, nil)
+	transactionSvc.On("FindById", mock.Anything, uint64(9)).Return(pending, nil).Once()
+	transactionSvc.On("UpdateStatus", mock.Anything, mock.Anything, entity.TransactionStatusActive).Return(active, nil)
+
+	saga := service.NewCreateTransactionSaga(config.Config{}, sagaRepo, transactionSvc, client.ConsumerLimitServiceClient{Client: limitClient})
+
+	_, result, err := saga.Execute(context.Background(), "ref-1", 3, "", 0, 12, 1000000, 0, 0, 0, "Laptop")
+
+	assert.NoError(t, err)
+	assert.Equal(t, entity.TransactionStatusActive, result.Status)
+
+	created := sagaRepo.Calls[0].Arguments.Get(1).(*entity.Saga)
+	assert.Equal(t, entity.SagaStatusCompleted, created.Status)
+	assert.Equal(t, uint64(9), created.TransactionId)
+	for _, step := range created.Steps {
+		assert.Equal(t, entity.SagaStepStatusDone, step.Status)
+	}
+
+	debit := limitClient.Calls[0].Arguments.Get(1).(*pb.UpdateAvailableLimitRequest)
+	assert.Equal(t, "ref-1", created.Reference)
+	assert.Equal(t, created.Reference, debit.Reference)
+
+	transactionSvc.AssertExpectations(t)
+	limitClient.AssertExpectations(t)
+}
+
+func TestCreateTransactionSagaCompensatesFailedDebit(t *testing.T) {
+	sagaRepo, transactionSvc, limitClient := newSagaMocks()
+
+	pending := &entity.Transaction{Id: 9, ContractNumber: "CN123", Status: entity.TransactionStatusPending}
+
+	transactionSvc.On("GenerateContractNumber", mock.Anything, uint64(3)).Return("CN123", nil)
+	transactionSvc.On("FindByContractNumber", mock.Anything, "CN123").Return(nil, status.Error(codes.NotFound, "not found")).Once()
+	transactionSvc.On("Create", mock.Anything, "CN123", uint64(3), "", uint32(0), uint32(12), uint64(1000000), uint64(0), uint64(0), uint64(0), "Laptop").Return(pending, nil)
+	limitClient.On("UpdateAvailableLimit", mock.Anything, mock.Anything).Return((*pb.ConsumerLimitResponse)(nil), status.Error(codes.Unavailable, "limit service down"))
+	limitClient.On("RestoreAvailableLimit", mock.Anything, mock.Anything).Return(&pb.ConsumerLimitResponse{}, nil)
+	transactionSvc.On("FindById", mock.Anything, uint64(9)).Return(pending, nil).Once()
+	transactionSvc.On("Rollback", mock.Anything, uint64(9), mock.Anything).Return(nil)
+
+	saga := service.NewCreateTransactionSaga(config.Config{}, sagaRepo, transactionSvc, client.ConsumerLimitServiceClient{Client: limitClient})
+
+	_, _, err := saga.Execute(context.Background(), "ref-1", 3, "", 0, 12, 1000000, 0, 0, 0, "Laptop")
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	created := sagaRepo.Calls[0].Arguments.Get(1).(*entity.Saga)
+	assert.Equal(t, entity.SagaStatusCompensated, created.Status)
+	assert.Equal(t, entity.SagaStepStatusCompensated, created.Steps[0].Status)
+	assert.Equal(t, entity.SagaStepStatusCompensated, created.Steps[1].Status)
+	assert.Equal(t, entity.SagaStepStatusPending, created.Steps[2].Status)
+
+	transactionSvc.AssertExpectations(t)
+	limitClient.AssertExpectations(t)
+}
+
+func TestCreateTransactionSagaReplacesTakenContractNumber(t *testing.T) {
+	sagaRepo, transactionSvc, limitClient := newSagaMocks()
+
+	other := &entity.Transaction{Id: 4, ContractNumber: "CN123", ConsumerId: 8, Status: entity.TransactionStatusActive}
+	pending := &entity.Transaction{Id: 9, ContractNumber: "CN456", Status: entity.TransactionStatusPending}
+	active := &entity.Transaction{Id: 9, ContractNumber: "CN456", Status: entity.TransactionStatusActive}
+
+	transactionSvc.On("GenerateContractNumber", mock.Anything, uint64(3)).Return("CN123", nil).Once()
+	transactionSvc.On("FindByContractNumber", mock.Anything, "CN123").Return(other, nil).Once()
+	transactionSvc.On("GenerateContractNumber", mock.Anything, uint64(3)).Return("CN456", nil).Once()
+	transactionSvc.On("FindByContractNumber", mock.Anything, "CN456").Return(nil, status.Error(codes.NotFound, "not found")).Once()
+	transactionSvc.On("Create", mock.Anything, "CN456", uint64(3), "", uint32(0), uint32(12), uint64(1000000), uint64(0), uint64(0), uint64(0), "Laptop").Return(pending, nil)
+	limitClient.On("UpdateAvailableLimit", mock.Anything, mock.Anything).Return(&pb.ConsumerLimitResponse{}, nil)
+	transactionSvc.On("FindById", mock.Anything, uint64(9)).Return(pending, nil).Once()
+	transactionSvc.On("UpdateStatus", mock.Anything, "CN456", entity.TransactionStatusActive).Return(active, nil)
+
+	cfg := config.Config{ContractNumber: config.ContractNumber{MaxAttempts: 3}}
+	saga := service.NewCreateTransactionSaga(cfg, sagaRepo, transactionSvc, client.ConsumerLimitServiceClient{Client: limitClient})
+
+	_, result, err := saga.Execute(context.Background(), "ref-1", 3, "", 0, 12, 1000000, 0, 0, 0, "Laptop")
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(9), result.Id)
+
+	created := sagaRepo.Calls[0].Arguments.Get(1).(*entity.Saga)
+	assert.Equal(t, "CN456", created.ContractNumber)
+	assert.Equal(t, entity.SagaStatusCompleted, created.Status)
+
+	transactionSvc.AssertExpectations(t)
+}
+
+func TestCreateTransactionSagaPendingWhenProgressNotSaved(t *testing.T) {
+	sagaRepo := new(MockSagaRepository)
+	transactionSvc, limitClient := new(MockTransactionService), new(MockConsumerLimitClient)
+
+	pending := &entity.Transaction{Id: 9, ContractNumber: "CN123", Status: entity.TransactionStatusPending}
+
+	sagaRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	sagaRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	sagaRepo.On("UpdateStep", mock.Anything, mock.MatchedBy(func(step *entity.SagaStep) bool {
+		return step.Name == entity.SagaStepDebitLimit && step.Status == entity.SagaStepStatusDone
+	})).Return(status.Error(codes.Unavailable, "database down"))
+	sagaRepo.On("UpdateStep", mock.Anything, mock.Anything).Return(nil)
+	transactionSvc.On("GenerateContractNumber", mock.Anything, uint64(3)).Return("CN123", nil)
+	transactionSvc.On("FindByContractNumber", mock.Anything, "CN123").Return(nil, status.Error(codes.NotFound, "not found")).Once()
+	transactionSvc.On("Create", mock.Anything, "CN123", uint64(3), "", uint32(0), uint32(12), uint64(1000000), uint64(0), uint64(0), uint64(0), "Laptop").Return(pending, nil)
+	limitClient.On("UpdateAvailableLimit", mock.Anything, mock.Anything).Return(&pb.ConsumerLimitResponse{}, nil)
+
+	saga := service.NewCreateTransactionSaga(config.Config{}, sagaRepo, transactionSvc, client.ConsumerLimitServiceClient{Client: limitClient})
+
+	created, result, err := saga.Execute(context.Background(), "ref-1", 3, "", 0, 12, 1000000, 0, 0, 0, "Laptop")
+
+	// the debit stands and nothing is compensated, the worker activates later
+	assert.ErrorIs(t, err, service.ErrSagaPending)
+	assert.Equal(t, uint64(9), result.Id)
+	assert.Equal(t, entity.SagaStatusStarted, created.Status)
+	assert.Equal(t, uint64(9), created.TransactionId)
+	limitClient.AssertNotCalled(t, "RestoreAvailableLimit", mock.Anything, mock.Anything)
+	transactionSvc.AssertNotCalled(t, "Rollback", mock.Anything, mock.Anything, mock.Anything)
+	transactionSvc.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSagaRecoveryWorkerResumesInterruptedSaga(t *testing.T) {
+	sagaRepo, transactionSvc, limitClient := newSagaMocks()
+
+	interrupted := &entity.Saga{
+		Id:             1,
+		Reference:      "ref-1",
+		ContractNumber: "CN123",
+		Status:         entity.SagaStatusStarted,
+		Steps: []*entity.SagaStep{
+			{Name: entity.SagaStepCreateTransaction, Status: entity.SagaStepStatusDone},
+			{Name: entity.SagaStepDebitLimit, Status: entity.SagaStepStatusInProgress},
+			{Name: entity.SagaStepActivateTransaction, Status: entity.SagaStepStatusPending},
+		},
+	}
+	active := &entity.Transaction{Id: 9, ContractNumber: "CN123", Status: entity.TransactionStatusActive}
+
+	sagaRepo.On("FindPending", mock.Anything, mock.Anything, 50).Return([]*entity.Saga{interrupted}, nil)
+	sagaRepo.On("Claim", mock.Anything, interrupted).Return(true, nil)
+	limitClient.On("UpdateAvailableLimit", mock.Anything, mock.MatchedBy(func(req *pb.UpdateAvailableLimitRequest) bool {
+		return req.Reference == "ref-1"
+	})).Return(&pb.ConsumerLimitResponse{}, nil)
+	transactionSvc.On("FindByContractNumber", mock.Anything, "CN123").Return(active, nil)
+
+	cfg := config.Config{Saga: config.Saga{RecoveryBatchSize: 50}}
+	saga := service.NewCreateTransactionSaga(cfg, sagaRepo, transactionSvc, client.ConsumerLimitServiceClient{Client: limitClient})
+	worker := service.NewSagaRecoveryWorker(cfg, sagaRepo, saga)
+
+	assert.Equal(t, 1, worker.RecoverOnce(context.Background()))
+	assert.Equal(t, entity.SagaStatusCompleted, interrupted.Status)
+
+	limitClient.AssertExpectations(t)
+}
+
+// runSagaOnSQLite runs the saga against the real repositories on an in-memory
+// SQLite database, failing instead of hanging should a step wait for a second
+// connection while a database transaction holds the pool.
+func runSagaOnSQLite(t *testing.T, limitClient *MockConsumerLimitClient) (*entity.Saga, *entity.Transaction, error) {
+	db, err := gormConn.NewSQLiteGormDB(":memory:")
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		_ = sqlDB.Close()
+	})
+	files, err := migrations.For(gormConn.DriverSQLite)
+	assert.NoError(t, err)
+	list, err := migration.Load(files)
+	assert.NoError(t, err)
+	_, err = migration.NewMigrator(db, list).Up(context.Background(), 0)
+	assert.NoError(t, err)
+
+	cfg := config.Config{Pricing: config.Pricing{Method: "flat", AnnualRateBps: 2400, RoundingUnit: 100}}
+	transactionEventRepository := repository.NewTransactionEventRepository(db)
+	transactionSvc := service.NewTransactionService(cfg, gormConn.NewTransactor(db), repository.NewTransactionRepository(db), repository.NewInstallmentRepository(db), transactionEventRepository, newContractNumberGenerator(), nil)
+	saga := service.NewCreateTransactionSaga(cfg, repository.NewSagaRepository(db), transactionSvc, client.ConsumerLimitServiceClient{Client: limitClient})
+
+	type result struct {
+		saga        *entity.Saga
+		transaction *entity.Transaction
+		err         error
+	}
+	done := make(chan result, 1)
+	go func() {
+		s, transaction, err := saga.Execute(context.Background(), "ref-1", 3, "", 0, 12, 1000000, 0, 0, 0, "Laptop")
+		done <- result{s, transaction, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.saga, r.transaction, r.err
+	case <-time.After(10 * time.Second):
+		t.Fatal("saga is blocked on the single in-memory connection")
+		return nil, nil, nil
+	}
+}
+
+func TestCreateTransactionSagaOnInMemorySQLite(t *testing.T) {
+	limitClient := new(MockConsumerLimitClient)
+	limitClient.On("UpdateAvailableLimit", mock.Anything, mock.Anything).Return(&pb.ConsumerLimitResponse{}, nil)
+
+	saga, transaction, err := runSagaOnSQLite(t, limitClient)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entity.SagaStatusCompleted, saga.Status)
+	assert.Equal(t, entity.TransactionStatusActive, transaction.Status)
+}
+
+func TestCreateTransactionSagaCompensatesOnInMemorySQLite(t *testing.T) {
+	limitClient := new(MockConsumerLimitClient)
+	limitClient.On("UpdateAvailableLimit", mock.Anything, mock.Anything).Return((*pb.ConsumerLimitResponse)(nil), status.Error(codes.FailedPrecondition, "limit exceeded"))
+	limitClient.On("RestoreAvailableLimit", mock.Anything, mock.Anything).Return(&pb.ConsumerLimitResponse{}, nil)
+
+	saga, _, err := runSagaOnSQLite(t, limitClient)
+
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Equal(t, entity.SagaStatusCompensated, saga.Status)
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+	"xyz-transaction-service/common/config"
+)
+
+// IdempotencyKeySweeper periodically removes expired idempotency keys, which
+// are otherwise only removed when the same key is sent again.
+type IdempotencyKeySweeper struct {
+	cfg            config.Config
+	idempotencySvc IdempotencyServiceUseCase
+}
+
+func NewIdempotencyKeySweeper(cfg config.Config, idempotencySvc IdempotencyServiceUseCase) *IdempotencyKeySweeper {
+	return &IdempotencyKeySweeper{
+		cfg:            cfg,
+		idempotencySvc: idempotencySvc,
+	}
+}
+
+func (w *IdempotencyKeySweeper) Run(ctx context.Context) {
+	interval := w.cfg.Idempotency.SweepInterval
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if removed := w.idempotencySvc.SweepExpired(ctx); removed > 0 {
+			log.Println("INFO: [IdempotencyKeySweeper - Run] Removed expired idempotency keys:", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
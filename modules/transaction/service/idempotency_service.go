@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"time"
+	"xyz-transaction-service/common/config"
+	commonErr "xyz-transaction-service/common/error"
+	"xyz-transaction-service/common/tracing"
+	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/modules/transaction/internal/repository"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type IdempotencyService struct {
+	cfg                      config.Config
+	idempotencyKeyRepository repository.IdempotencyKeyRepositoryUseCase
+	sagaRepository           repository.SagaRepositoryUseCase
+}
+
+func NewIdempotencyService(cfg config.Config, idempotencyKeyRepository repository.IdempotencyKeyRepositoryUseCase, sagaRepository repository.SagaRepositoryUseCase) *IdempotencyService {
+	return &IdempotencyService{
+		cfg:                      cfg,
+		idempotencyKeyRepository: idempotencyKeyRepository,
+		sagaRepository:           sagaRepository,
+	}
+}
+
+type IdempotencyServiceUseCase interface {
+	Begin(ctx context.Context, method, key string, req proto.Message) (*entity.IdempotencyKey, error)
+	Complete(ctx context.Context, idempotencyKey *entity.IdempotencyKey, res proto.Message) error
+	Release(ctx context.Context, idempotencyKey *entity.IdempotencyKey)
+	SweepExpired(ctx context.Context) int
+}
+
+// Begin reserves the key for the request. When the key was already used for
+// the same payload the stored record is returned in COMPLETED status and its
+// response must be replayed instead of running the request again. A key still
+// IN_PROGRESS is refused until its lock lapses, then taken over. An expired
+// key starts over, unless it is IN_PROGRESS with its saga still running.
+func (svc *IdempotencyService) Begin(ctx context.Context, method, key string, req proto.Message) (*entity.IdempotencyKey, error) {
+	ctx, span := tracing.StartSpan(ctx, "IdempotencyService - Begin")
+	defer span.End()
+
+	requestHash, err := HashRequest(req)
+	if err != nil {
+		log.Println("ERROR: [IdempotencyService - Begin] Error while hash request:", err)
+		return nil, status.Errorf(codes.Internal, "failed to hash request")
+	}
+
+	// a second round is only needed when an expired key was just removed or a
+	// concurrent request inserted the same key first
+	for attempt := 0; attempt < 2; attempt++ {
+		existing, err := svc.idempotencyKeyRepository.FindByKey(ctx, method, key)
+		if status.Code(err) == codes.NotFound {
+			now := time.Now()
+			lockedUntil := now.Add(svc.cfg.Idempotency.LockTTL)
+			created, err := svc.idempotencyKeyRepository.Create(ctx, &entity.IdempotencyKey{
+				Key:           key,
+				Method:        method,
+				RequestHash:   requestHash,
+				Status:        entity.IdempotencyKeyStatusInProgress,
+				SagaReference: uuid.NewString(),
+				ExpiresAt:     now.Add(svc.cfg.Idempotency.KeyTTL),
+				LockedUntil:   &lockedUntil,
+				CreatedAt:     now,
+				UpdatedAt:     now,
+			})
+			if status.Code(err) == codes.AlreadyExists {
+				continue
+			}
+			return created, err
+		}
+		if err != nil {
+			parseError := commonErr.ParseError(err)
+			log.Println("ERROR: [IdempotencyService - Begin] Error while find idempotency key:", parseError.Message)
+			return nil, err
+		}
+
+		if existing.IsExpired(time.Now()) {
+			removable, err := svc.removable(ctx, existing)
+			if err != nil {
+				return nil, err
+			}
+			if removable {
+				if err := svc.idempotencyKeyRepository.Delete(ctx, existing.Id); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+
+		if existing.RequestHash != requestHash {
+			log.Println("WARNING: [IdempotencyService - Begin] Idempotency key reused with a different payload:", key)
+			return nil, status.Errorf(codes.AlreadyExists, "idempotency key %s was already used for a different request", key)
+		}
+
+		if existing.Status != entity.IdempotencyKeyStatusCompleted {
+			return svc.takeOver(ctx, existing)
+		}
+
+		log.Println("INFO: [IdempotencyService - Begin] Replaying stored response for idempotency key:", key)
+		return existing, nil
+	}
+
+	return nil, status.Errorf(codes.Aborted, "a request with idempotency key %s is still in progress", key)
+}
+
+// takeOver hands an IN_PROGRESS key to the current request once the request
+// holding it has let its lock lapse, e.g. because it crashed or failed to
+// release the key. The key keeps its saga reference, the caller must look for
+// a saga started under it before starting one.
+func (svc *IdempotencyService) takeOver(ctx context.Context, existing *entity.IdempotencyKey) (*entity.IdempotencyKey, error) {
+	if existing.IsLocked(time.Now()) {
+		log.Println("WARNING: [IdempotencyService - Begin] Request still in progress for idempotency key:", existing.Key)
+		return nil, status.Errorf(codes.Aborted, "a request with idempotency key %s is still in progress", existing.Key)
+	}
+
+	// keys reserved before sagas were bound to them get a reference now
+	if existing.SagaReference == "" {
+		existing.SagaReference = uuid.NewString()
+	}
+	ok, err := svc.idempotencyKeyRepository.TakeOver(ctx, existing, time.Now().Add(svc.cfg.Idempotency.LockTTL))
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [IdempotencyService - Begin] Error while take over idempotency key:", parseError.Message)
+		return nil, err
+	}
+	if !ok {
+		log.Println("WARNING: [IdempotencyService - Begin] Stale idempotency key taken over by another request:", existing.Key)
+		return nil, status.Errorf(codes.Aborted, "a request with idempotency key %s is still in progress", existing.Key)
+	}
+
+	log.Println("WARNING: [IdempotencyService - Begin] Taking over stale idempotency key:", existing.Key)
+	return existing, nil
+}
+
+// Complete stores the response so later replays of the key receive it as is.
+func (svc *IdempotencyService) Complete(ctx context.Context, idempotencyKey *entity.IdempotencyKey, res proto.Message) error {
+	ctx, span := tracing.StartSpan(ctx, "IdempotencyService - Complete")
+	defer span.End()
+
+	response, err := proto.Marshal(res)
+	if err != nil {
+		log.Println("ERROR: [IdempotencyService - Complete] Error while marshal response:", err)
+		return err
+	}
+
+	idempotencyKey.Status = entity.IdempotencyKeyStatusCompleted
+	idempotencyKey.Response = response
+	idempotencyKey.UpdatedAt = time.Now()
+
+	return svc.idempotencyKeyRepository.Complete(ctx, idempotencyKey)
+}
+
+// Release frees the key after a failed request so the client can retry it.
+func (svc *IdempotencyService) Release(ctx context.Context, idempotencyKey *entity.IdempotencyKey) {
+	if err := svc.idempotencyKeyRepository.Delete(ctx, idempotencyKey.Id); err != nil {
+		log.Println("ERROR: [IdempotencyService - Release] Error while release idempotency key:", idempotencyKey.Key)
+	}
+}
+
+// SweepExpired removes expired keys that are no longer needed and returns how
+// many were removed.
+func (svc *IdempotencyService) SweepExpired(ctx context.Context) int {
+	ctx, span := tracing.StartSpan(ctx, "IdempotencyService - SweepExpired")
+	defer span.End()
+
+	expired, err := svc.idempotencyKeyRepository.FindExpired(ctx, time.Now(), max(svc.cfg.Idempotency.SweepBatchSize, 1))
+	if err != nil {
+		log.Println("ERROR: [IdempotencyService - SweepExpired] Error while find expired idempotency keys:", err)
+		return 0
+	}
+
+	removed := 0
+	for _, idempotencyKey := range expired {
+		removable, err := svc.removable(ctx, idempotencyKey)
+		if err != nil || !removable {
+			continue
+		}
+		if err := svc.idempotencyKeyRepository.Delete(ctx, idempotencyKey.Id); err != nil {
+			continue
+		}
+		removed++
+	}
+
+	return removed
+}
+
+// removable reports whether an expired key can go. An IN_PROGRESS key must
+// stay while a saga started under it is running, otherwise a retry would
+// reserve the key again and book the contract a second time.
+func (svc *IdempotencyService) removable(ctx context.Context, idempotencyKey *entity.IdempotencyKey) (bool, error) {
+	if idempotencyKey.Status != entity.IdempotencyKeyStatusInProgress || idempotencyKey.SagaReference == "" {
+		return true, nil
+	}
+
+	saga, err := svc.sagaRepository.FindByReference(ctx, idempotencyKey.SagaReference)
+	if status.Code(err) == codes.NotFound {
+		return true, nil
+	}
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [IdempotencyService - removable] Error while find saga of idempotency key:", parseError.Message)
+		return false, err
+	}
+
+	if !saga.IsTerminal() {
+		log.Println("WARNING: [IdempotencyService - removable] Keeping expired idempotency key of a running saga:", idempotencyKey.Key)
+		return false, nil
+	}
+	return true, nil
+}
+
+// HashRequest fingerprints a request message independently of field order.
+func HashRequest(req proto.Message) (string, error) {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,201 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"xyz-transaction-service/common/config"
+	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/modules/transaction/service"
+	"xyz-transaction-service/pb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Mock for IdempotencyKeyRepositoryUseCase
+type MockIdempotencyKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyKeyRepository) FindByKey(ctx context.Context, method, key string) (*entity.IdempotencyKey, error) {
+	args := m.Called(ctx, method, key)
+	idempotencyKey, _ := args.Get(0).(*entity.IdempotencyKey)
+	return idempotencyKey, args.Error(1)
+}
+
+func (m *MockIdempotencyKeyRepository) FindExpired(ctx context.Context, now time.Time, limit int) ([]*entity.IdempotencyKey, error) {
+	args := m.Called(ctx, now, limit)
+	idempotencyKeys, _ := args.Get(0).([]*entity.IdempotencyKey)
+	return idempotencyKeys, args.Error(1)
+}
+
+func (m *MockIdempotencyKeyRepository) Create(ctx context.Context, idempotencyKey *entity.IdempotencyKey) (*entity.IdempotencyKey, error) {
+	args := m.Called(ctx, idempotencyKey)
+	return idempotencyKey, args.Error(0)
+}
+
+func (m *MockIdempotencyKeyRepository) Complete(ctx context.Context, idempotencyKey *entity.IdempotencyKey) error {
+	return m.Called(ctx, idempotencyKey).Error(0)
+}
+
+func (m *MockIdempotencyKeyRepository) TakeOver(ctx context.Context, idempotencyKey *entity.IdempotencyKey, lockedUntil time.Time) (bool, error) {
+	args := m.Called(ctx, idempotencyKey, lockedUntil)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockIdempotencyKeyRepository) Delete(ctx context.Context, id uint64) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+var idempotencyConfig = config.Config{Idempotency: config.Idempotency{KeyTTL: time.Hour, LockTTL: time.Minute}}
+
+func TestIdempotencyBeginNewKey(t *testing.T) {
+	mockRepo := new(MockIdempotencyKeyRepository)
+	mockRepo.On("FindByKey", mock.Anything, "CreateTransaction", "key-1").Return(nil, status.Error(codes.NotFound, "not found"))
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.IdempotencyKey")).Return(nil)
+
+	svc := service.NewIdempotencyService(idempotencyConfig, mockRepo, new(MockSagaRepository))
+
+	idempotencyKey, err := svc.Begin(context.Background(), "CreateTransaction", "key-1", &pb.Transaction{ConsumerId: 1, Otr: 1000})
+
+	assert.NoError(t, err)
+	assert.Equal(t, entity.IdempotencyKeyStatusInProgress, idempotencyKey.Status)
+	assert.True(t, idempotencyKey.ExpiresAt.After(time.Now().Add(59*time.Minute)))
+	assert.NotEmpty(t, idempotencyKey.SagaReference)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestIdempotencyBeginReplaysCompletedKey(t *testing.T) {
+	req := &pb.Transaction{ConsumerId: 1, Otr: 1000}
+	hash, _ := service.HashRequest(req)
+
+	stored := &entity.IdempotencyKey{Id: 1, Key: "key-1", RequestHash: hash, Status: entity.IdempotencyKeyStatusCompleted, ExpiresAt: time.Now().Add(time.Hour)}
+	mockRepo := new(MockIdempotencyKeyRepository)
+	mockRepo.On("FindByKey", mock.Anything, "CreateTransaction", "key-1").Return(stored, nil)
+
+	svc := service.NewIdempotencyService(idempotencyConfig, mockRepo, new(MockSagaRepository))
+
+	idempotencyKey, err := svc.Begin(context.Background(), "CreateTransaction", "key-1", req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, stored, idempotencyKey)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestIdempotencyBeginRejectsDifferentPayload(t *testing.T) {
+	stored := &entity.IdempotencyKey{Id: 1, Key: "key-1", RequestHash: "other", Status: entity.IdempotencyKeyStatusCompleted, ExpiresAt: time.Now().Add(time.Hour)}
+	mockRepo := new(MockIdempotencyKeyRepository)
+	mockRepo.On("FindByKey", mock.Anything, "CreateTransaction", "key-1").Return(stored, nil)
+
+	svc := service.NewIdempotencyService(idempotencyConfig, mockRepo, new(MockSagaRepository))
+
+	_, err := svc.Begin(context.Background(), "CreateTransaction", "key-1", &pb.Transaction{ConsumerId: 1, Otr: 1000})
+
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+}
+
+func TestIdempotencyBeginReusesExpiredKey(t *testing.T) {
+	expired := &entity.IdempotencyKey{Id: 1, Key: "key-1", RequestHash: "other", Status: entity.IdempotencyKeyStatusCompleted, ExpiresAt: time.Now().Add(-time.Minute)}
+	mockRepo := new(MockIdempotencyKeyRepository)
+	mockRepo.On("FindByKey", mock.Anything, "CreateTransaction", "key-1").Return(expired, nil).Once()
+	mockRepo.On("Delete", mock.Anything, uint64(1)).Return(nil)
+	mockRepo.On("FindByKey", mock.Anything, "CreateTransaction", "key-1").Return(nil, status.Error(codes.NotFound, "not found")).Once()
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.IdempotencyKey")).Return(nil)
+
+	svc := service.NewIdempotencyService(idempotencyConfig, mockRepo, new(MockSagaRepository))
+
+	idempotencyKey, err := svc.Begin(context.Background(), "CreateTransaction", "key-1", &pb.Transaction{ConsumerId: 1, Otr: 1000})
+
+	assert.NoError(t, err)
+	assert.Equal(t, entity.IdempotencyKeyStatusInProgress, idempotencyKey.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestIdempotencyBeginKeepsExpiredKeyOfRunningSaga(t *testing.T) {
+	req := &pb.Transaction{ConsumerId: 1, Otr: 1000}
+	hash, _ := service.HashRequest(req)
+
+	lockedUntil := time.Now().Add(-time.Hour)
+	expired := &entity.IdempotencyKey{Id: 1, Key: "key-1", RequestHash: hash, Status: entity.IdempotencyKeyStatusInProgress, SagaReference: "ref-1", ExpiresAt: time.Now().Add(-time.Minute), LockedUntil: &lockedUntil}
+	mockRepo := new(MockIdempotencyKeyRepository)
+	mockRepo.On("FindByKey", mock.Anything, "CreateTransaction", "key-1").Return(expired, nil)
+	mockRepo.On("TakeOver", mock.Anything, expired, mock.Anything).Return(true, nil)
+	mockSagaRepo := new(MockSagaRepository)
+	mockSagaRepo.On("FindByReference", mock.Anything, "ref-1").Return(&entity.Saga{Reference: "ref-1", Status: entity.SagaStatusStarted}, nil)
+
+	svc := service.NewIdempotencyService(idempotencyConfig, mockRepo, mockSagaRepo)
+
+	idempotencyKey, err := svc.Begin(context.Background(), "CreateTransaction", "key-1", req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ref-1", idempotencyKey.SagaReference)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestIdempotencySweepExpired(t *testing.T) {
+	completed := &entity.IdempotencyKey{Id: 1, Key: "key-1", Status: entity.IdempotencyKeyStatusCompleted, SagaReference: "ref-1"}
+	running := &entity.IdempotencyKey{Id: 2, Key: "key-2", Status: entity.IdempotencyKeyStatusInProgress, SagaReference: "ref-2"}
+	compensated := &entity.IdempotencyKey{Id: 3, Key: "key-3", Status: entity.IdempotencyKeyStatusInProgress, SagaReference: "ref-3"}
+	unused := &entity.IdempotencyKey{Id: 4, Key: "key-4", Status: entity.IdempotencyKeyStatusInProgress, SagaReference: "ref-4"}
+
+	mockRepo := new(MockIdempotencyKeyRepository)
+	mockRepo.On("FindExpired", mock.Anything, mock.Anything, 1).Return([]*entity.IdempotencyKey{completed, running, compensated, unused}, nil)
+	mockRepo.On("Delete", mock.Anything, uint64(1)).Return(nil)
+	mockRepo.On("Delete", mock.Anything, uint64(3)).Return(nil)
+	mockRepo.On("Delete", mock.Anything, uint64(4)).Return(nil)
+	mockSagaRepo := new(MockSagaRepository)
+	mockSagaRepo.On("FindByReference", mock.Anything, "ref-2").Return(&entity.Saga{Reference: "ref-2", Status: entity.SagaStatusCompensating}, nil)
+	mockSagaRepo.On("FindByReference", mock.Anything, "ref-3").Return(&entity.Saga{Reference: "ref-3", Status: entity.SagaStatusCompensated}, nil)
+	mockSagaRepo.On("FindByReference", mock.Anything, "ref-4").Return(nil, status.Error(codes.NotFound, "not found"))
+
+	svc := service.NewIdempotencyService(idempotencyConfig, mockRepo, mockSagaRepo)
+
+	assert.Equal(t, 3, svc.SweepExpired(context.Background()))
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, uint64(2))
+}
+
+func TestIdempotencyBeginRefusesLockedKey(t *testing.T) {
+	req := &pb.Transaction{ConsumerId: 1, Otr: 1000}
+	hash, _ := service.HashRequest(req)
+
+	lockedUntil := time.Now().Add(time.Minute)
+	held := &entity.IdempotencyKey{Id: 1, Key: "key-1", RequestHash: hash, Status: entity.IdempotencyKeyStatusInProgress, ExpiresAt: time.Now().Add(time.Hour), LockedUntil: &lockedUntil}
+	mockRepo := new(MockIdempotencyKeyRepository)
+	mockRepo.On("FindByKey", mock.Anything, "CreateTransaction", "key-1").Return(held, nil)
+
+	svc := service.NewIdempotencyService(idempotencyConfig, mockRepo, new(MockSagaRepository))
+
+	_, err := svc.Begin(context.Background(), "CreateTransaction", "key-1", req)
+
+	assert.Equal(t, codes.Aborted, status.Code(err))
+	mockRepo.AssertNotCalled(t, "TakeOver", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestIdempotencyBeginTakesOverStaleKey(t *testing.T) {
+	req := &pb.Transaction{ConsumerId: 1, Otr: 1000}
+	hash, _ := service.HashRequest(req)
+
+	lockedUntil := time.Now().Add(-time.Second)
+	stale := &entity.IdempotencyKey{Id: 1, Key: "key-1", RequestHash: hash, Status: entity.IdempotencyKeyStatusInProgress, SagaReference: "ref-1", ExpiresAt: time.Now().Add(time.Hour), LockedUntil: &lockedUntil}
+	mockRepo := new(MockIdempotencyKeyRepository)
+	mockRepo.On("FindByKey", mock.Anything, "CreateTransaction", "key-1").Return(stale, nil)
+	mockRepo.On("TakeOver", mock.Anything, stale, mock.Anything).Return(true, nil).Once()
+	mockRepo.On("TakeOver", mock.Anything, stale, mock.Anything).Return(false, nil).Once()
+
+	svc := service.NewIdempotencyService(idempotencyConfig, mockRepo, new(MockSagaRepository))
+
+	idempotencyKey, err := svc.Begin(context.Background(), "CreateTransaction", "key-1", req)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.IdempotencyKeyStatusInProgress, idempotencyKey.Status)
+	assert.Equal(t, "ref-1", idempotencyKey.SagaReference)
+
+	// a concurrent retry that lost the compare-and-set keeps waiting
+	_, err = svc.Begin(context.Background(), "CreateTransaction", "key-1", req)
+	assert.Equal(t, codes.Aborted, status.Code(err))
+	mockRepo.AssertExpectations(t)
+}
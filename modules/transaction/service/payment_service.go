@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+	"xyz-transaction-service/common/config"
+	commonErr "xyz-transaction-service/common/error"
+	gormConn "xyz-transaction-service/common/gorm"
+	"xyz-transaction-service/common/tracing"
+	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/modules/transaction/internal/repayment"
+	"xyz-transaction-service/modules/transaction/internal/repository"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type PaymentService struct {
+	cfg                   config.Config
+	transactor            gormConn.Transactor
+	transactionRepository repository.TransactionRepositoryUseCase
+	installmentRepository repository.InstallmentRepositoryUseCase
+	paymentRepository     repository.PaymentRepositoryUseCase
+	auditor               *TransactionAuditor
+	stateMachine          *TransactionStateMachine
+	waterfall             []repayment.Component
+}
+
+func NewPaymentService(cfg config.Config, transactor gormConn.Transactor, transactionRepository repository.TransactionRepositoryUseCase, installmentRepository repository.InstallmentRepositoryUseCase, paymentRepository repository.PaymentRepositoryUseCase, transactionEventRepository repository.TransactionEventRepositoryUseCase) *PaymentService {
+	waterfall, err := repayment.ParseWaterfall(cfg.Payment.Waterfall)
+	if err != nil {
+		log.Println("ERROR: [PaymentService - NewPaymentService] Invalid payment waterfall, falling back to default:", err)
+		waterfall = repayment.DefaultWaterfall
+	}
+
+	return &PaymentService{
+		cfg:                   cfg,
+		transactor:            transactor,
+		transactionRepository: transactionRepository,
+		installmentRepository: installmentRepository,
+		paymentRepository:     paymentRepository,
+		auditor:               NewTransactionAuditor(transactionEventRepository),
+		stateMachine:          NewTransactionStateMachine(),
+		waterfall:             waterfall,
+	}
+}
+
+type PaymentServiceUseCase interface {
+	RecordPayment(ctx context.Context, contractNumber string, amount uint64, paymentReference string, paidAt time.Time) (*entity.Payment, bool, error)
+}
+
+// RecordPayment applies a repayment to the contract schedule. The boolean
+// result reports a replay of an already recorded payment reference, which is
+// returned as is instead of being credited a second time.
+func (svc *PaymentService) RecordPayment(ctx context.Context, contractNumber string, amount uint64, paymentReference string, paidAt time.Time) (*entity.Payment, bool, error) {
+	ctx, span := tracing.StartSpan(ctx, "PaymentService - RecordPayment")
+	defer span.End()
+
+	if amount == 0 {
+		return nil, false, status.Errorf(codes.InvalidArgument, "amount must be greater than zero")
+	}
+	if paymentReference == "" {
+		return nil, false, status.Errorf(codes.InvalidArgument, "payment reference is required")
+	}
+
+	if existing, err := svc.findReplay(ctx, contractNumber, amount, paymentReference); existing != nil || err != nil {
+		return existing, existing != nil, err
+	}
+
+	transaction, err := svc.transactionRepository.FindByContractNumber(ctx, contractNumber)
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [PaymentService - RecordPayment] Error while find transaction by contract number:", parseError.Message)
+		return nil, false, err
+	}
+
+	if !svc.stateMachine.AcceptsPayment(transaction.Status) {
+		log.Println("WARNING: [PaymentService - RecordPayment] Payment rejected for transaction status:", transaction.Status)
+		return nil, false, status.Errorf(codes.FailedPrecondition, "cannot record payment for transaction with status %s", transaction.Status)
+	}
+
+	var payment *entity.Payment
+	err = svc.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		installments, err := svc.installmentRepository.FindByTransactionIdForUpdate(ctx, transaction.Id)
+		if err != nil {
+			return err
+		}
+
+		charged := repayment.AccruePenalty(installments, svc.cfg.Payment.LatePenaltyBps, paidAt)
+		allocations, remainder := repayment.Allocate(installments, amount, svc.waterfall, paidAt)
+		if remainder > 0 {
+			log.Println("WARNING: [PaymentService - RecordPayment] Payment exceeds outstanding balance for contract number:", contractNumber)
+			return status.Errorf(codes.InvalidArgument, "payment amount exceeds outstanding balance by %d", remainder)
+		}
+
+		now := time.Now()
+		payment = &entity.Payment{
+			TransactionId:    transaction.Id,
+			ContractNumber:   transaction.ContractNumber,
+			PaymentReference: paymentReference,
+			Amount:           amount,
+			PaidAt:           paidAt,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		}
+
+		touched := make(map[uint64]*entity.Installment)
+		for _, installment := range charged {
+			touched[installment.Id] = installment
+		}
+		for _, a := range allocations {
+			touched[a.Installment.Id] = a.Installment
+			payment.Allocations = append(payment.Allocations, &entity.PaymentAllocation{
+				InstallmentId:     a.Installment.Id,
+				InstallmentNumber: a.Installment.InstallmentNumber,
+				Component:         string(a.Component),
+				Amount:            a.Amount,
+				CreatedAt:         now,
+			})
+		}
+
+		if _, err := svc.paymentRepository.Create(ctx, payment); err != nil {
+			return err
+		}
+
+		for _, installment := range installments {
+			if _, ok := touched[installment.Id]; !ok {
+				continue
+			}
+			installment.UpdatedAt = now
+			if err := svc.installmentRepository.UpdatePayment(ctx, installment); err != nil {
+				return err
+			}
+		}
+
+		for _, installment := range installments {
+			if installment.Status != entity.InstallmentStatusPaid {
+				return nil
+			}
+		}
+
+		if err := svc.stateMachine.Validate(transaction.Status, entity.TransactionStatusPaidOff); err != nil {
+			return err
+		}
+		if err := svc.transactionRepository.UpdateStatus(ctx, transaction.Id, transaction.Status, entity.TransactionStatusPaidOff); err != nil {
+			return err
+		}
+
+		paidOff := *transaction
+		paidOff.Status = entity.TransactionStatusPaidOff
+		return svc.auditor.Record(ctx, entity.TransactionEventStatusChanged, transaction, &paidOff, "paid off by payment "+paymentReference)
+	})
+	if err != nil {
+		if status.Code(err) == codes.AlreadyExists {
+			// lost the race against a concurrent delivery of the same callback
+			if existing, replayErr := svc.findReplay(ctx, contractNumber, amount, paymentReference); existing != nil || replayErr != nil {
+				return existing, existing != nil, replayErr
+			}
+		}
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [PaymentService - RecordPayment] Error while record payment:", parseError.Message)
+		return nil, false, err
+	}
+
+	return payment, false, nil
+}
+
+func (svc *PaymentService) findReplay(ctx context.Context, contractNumber string, amount uint64, paymentReference string) (*entity.Payment, error) {
+	existing, err := svc.paymentRepository.FindByPaymentReference(ctx, paymentReference)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if existing.ContractNumber != contractNumber || existing.Amount != amount {
+		log.Println("WARNING: [PaymentService - RecordPayment] Payment reference reused with a different payload:", paymentReference)
+		return nil, status.Errorf(codes.AlreadyExists, "payment reference %s is already used by another payment", paymentReference)
+	}
+
+	log.Println("INFO: [PaymentService - RecordPayment] Duplicate payment callback ignored for payment reference:", paymentReference)
+	return existing, nil
+}
@@ -0,0 +1,129 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"xyz-transaction-service/common/config"
+	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/modules/transaction/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Mock for PaymentRepositoryUseCase
+type MockPaymentRepository struct {
+	mock.Mock
+}
+
+func (m *MockPaymentRepository) FindByPaymentReference(ctx context.Context, paymentReference string) (*entity.Payment, error) {
+	args := m.Called(ctx, paymentReference)
+	payment, _ := args.Get(0).(*entity.Payment)
+	return payment, args.Error(1)
+}
+
+func (m *MockPaymentRepository) Create(ctx context.Context, payment *entity.Payment) (*entity.Payment, error) {
+	args := m.Called(ctx, payment)
+	return args.Get(0).(*entity.Payment), args.Error(1)
+}
+
+// MockTransactor runs the callback without opening a database transaction
+type MockTransactor struct{}
+
+func (MockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestRecordPayment(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	mockInstallmentRepo := new(MockInstallmentRepository)
+	mockPaymentRepo := new(MockPaymentRepository)
+
+	installment := &entity.Installment{Id: 7, TransactionId: 1, InstallmentNumber: 1, Principal: 800, Interest: 150, Fee: 50, Amount: 1000}
+
+	mockPaymentRepo.On("FindByPaymentReference", mock.Anything, "PAY-1").Return(nil, status.Error(codes.NotFound, "not found"))
+	mockRepo.On("FindByContractNumber", mock.Anything, "CN123").Return(&entity.Transaction{Id: 1, ContractNumber: "CN123", Status: entity.TransactionStatusActive}, nil)
+	mockRepo.On("UpdateStatus", mock.Anything, uint64(1), entity.TransactionStatusActive, entity.TransactionStatusPaidOff).Return(nil)
+	mockInstallmentRepo.On("FindByTransactionIdForUpdate", mock.Anything, uint64(1)).Return([]*entity.Installment{installment}, nil)
+	mockPaymentRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Payment")).Return(&entity.Payment{}, nil)
+	mockInstallmentRepo.On("UpdatePayment", mock.Anything, installment).Return(nil)
+
+	svc := service.NewPaymentService(config.Config{}, MockTransactor{}, mockRepo, mockInstallmentRepo, mockPaymentRepo, newMockTransactionEventRepository())
+
+	payment, duplicate, err := svc.RecordPayment(context.Background(), "CN123", 1000, "PAY-1", time.Now())
+
+	assert.NoError(t, err)
+	assert.False(t, duplicate)
+	assert.Len(t, payment.Allocations, 3)
+	assert.Equal(t, entity.InstallmentStatusPaid, installment.Status)
+
+	mockRepo.AssertExpectations(t)
+	mockInstallmentRepo.AssertExpectations(t)
+	mockPaymentRepo.AssertExpectations(t)
+}
+
+func TestRecordPaymentChargesLatePenalty(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	mockInstallmentRepo := new(MockInstallmentRepository)
+	mockPaymentRepo := new(MockPaymentRepository)
+
+	paidAt := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+	overdue := &entity.Installment{Id: 7, TransactionId: 1, InstallmentNumber: 1, DueDate: paidAt.AddDate(0, -1, 0), Principal: 800, Interest: 150, Fee: 50, Amount: 1000}
+	upcoming := &entity.Installment{Id: 8, TransactionId: 1, InstallmentNumber: 2, DueDate: paidAt.AddDate(0, 1, 0), Principal: 800, Interest: 150, Fee: 50, Amount: 1000}
+
+	mockPaymentRepo.On("FindByPaymentReference", mock.Anything, "PAY-1").Return(nil, status.Error(codes.NotFound, "not found"))
+	mockRepo.On("FindByContractNumber", mock.Anything, "CN123").Return(&entity.Transaction{Id: 1, ContractNumber: "CN123", Status: entity.TransactionStatusActive}, nil)
+	mockInstallmentRepo.On("FindByTransactionIdForUpdate", mock.Anything, uint64(1)).Return([]*entity.Installment{overdue, upcoming}, nil)
+	mockPaymentRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Payment")).Return(&entity.Payment{}, nil)
+	mockInstallmentRepo.On("UpdatePayment", mock.Anything, overdue).Return(nil)
+
+	cfg := config.Config{Payment: config.Payment{LatePenaltyBps: 500}}
+	svc := service.NewPaymentService(cfg, MockTransactor{}, mockRepo, mockInstallmentRepo, mockPaymentRepo, newMockTransactionEventRepository())
+
+	payment, _, err := svc.RecordPayment(context.Background(), "CN123", 1000, "PAY-1", paidAt)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(50), overdue.Penalty)
+	assert.Equal(t, uint64(0), upcoming.Penalty)
+	assert.Equal(t, "penalty", payment.Allocations[0].Component)
+	assert.Equal(t, uint64(50), overdue.Outstanding())
+	assert.Equal(t, entity.InstallmentStatusPartiallyPaid, overdue.Status)
+
+	mockInstallmentRepo.AssertExpectations(t)
+	mockInstallmentRepo.AssertNotCalled(t, "UpdatePayment", mock.Anything, upcoming)
+}
+
+func TestRecordPaymentDuplicateReference(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+
+	existing := &entity.Payment{Id: 3, ContractNumber: "CN123", PaymentReference: "PAY-1", Amount: 1000}
+	mockPaymentRepo.On("FindByPaymentReference", mock.Anything, "PAY-1").Return(existing, nil)
+
+	svc := service.NewPaymentService(config.Config{}, MockTransactor{}, new(MockTransactionRepository), new(MockInstallmentRepository), mockPaymentRepo, newMockTransactionEventRepository())
+
+	payment, duplicate, err := svc.RecordPayment(context.Background(), "CN123", 1000, "PAY-1", time.Now())
+	assert.NoError(t, err)
+	assert.True(t, duplicate)
+	assert.Equal(t, existing, payment)
+
+	_, _, err = svc.RecordPayment(context.Background(), "CN123", 2000, "PAY-1", time.Now())
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	mockPaymentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestRecordPaymentRejectsInactiveTransaction(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	mockPaymentRepo := new(MockPaymentRepository)
+
+	mockPaymentRepo.On("FindByPaymentReference", mock.Anything, "PAY-1").Return(nil, status.Error(codes.NotFound, "not found"))
+	mockRepo.On("FindByContractNumber", mock.Anything, "CN123").Return(&entity.Transaction{Id: 1, ContractNumber: "CN123", Status: entity.TransactionStatusCancelled}, nil)
+
+	svc := service.NewPaymentService(config.Config{}, MockTransactor{}, mockRepo, new(MockInstallmentRepository), mockPaymentRepo, newMockTransactionEventRepository())
+
+	_, _, err := svc.RecordPayment(context.Background(), "CN123", 1000, "PAY-1", time.Now())
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
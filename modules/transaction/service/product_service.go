@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"xyz-transaction-service/common/config"
+	commonErr "xyz-transaction-service/common/error"
+	"xyz-transaction-service/common/tracing"
+	"xyz-transaction-service/common/validation"
+	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/modules/transaction/internal/repository"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ProductService keeps the catalog of financing products. Terms are changed
+// by creating the next version of a code, never by editing one, so every
+// contract can be traced to the exact terms it was priced with.
+type ProductService struct {
+	cfg               config.Config
+	productRepository repository.ProductRepositoryUseCase
+}
+
+func NewProductService(cfg config.Config, productRepository repository.ProductRepositoryUseCase) *ProductService {
+	return &ProductService{
+		cfg:               cfg,
+		productRepository: productRepository,
+	}
+}
+
+type ProductServiceUseCase interface {
+	FindAll(ctx context.Context, at time.Time) ([]*entity.Product, error)
+	FindByCode(ctx context.Context, code string, version uint32) (*entity.Product, error)
+	Create(ctx context.Context, product *entity.Product) (*entity.Product, error)
+	Retire(ctx context.Context, code string, effectiveTo time.Time) (*entity.Product, error)
+	Resolve(ctx context.Context, code string, version uint32, tenor uint32) (*entity.Product, *entity.ProductTenor, error)
+}
+
+// FindAll returns the version of every product effective at at.
+func (svc *ProductService) FindAll(ctx context.Context, at time.Time) ([]*entity.Product, error) {
+	ctx, span := tracing.StartSpan(ctx, "ProductService - FindAll")
+	defer span.End()
+
+	res, err := svc.productRepository.FindAllEffective(ctx, at)
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [ProductService - FindAll] Error while find products:", parseError.Message)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// FindByCode returns one version of a product, or the version effective now
+// when version is 0.
+func (svc *ProductService) FindByCode(ctx context.Context, code string, version uint32) (*entity.Product, error) {
+	ctx, span := tracing.StartSpan(ctx, "ProductService - FindByCode")
+	defer span.End()
+
+	var res *entity.Product
+	var err error
+	if version == 0 {
+		res, err = svc.productRepository.FindEffective(ctx, code, time.Now())
+	} else {
+		res, err = svc.productRepository.FindByCodeAndVersion(ctx, code, version)
+	}
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [ProductService - FindByCode] Error while find product:", parseError.Message)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Create publishes product as the next version of its code. The new version
+// takes over from its EffectiveFrom, which defaults to now.
+func (svc *ProductService) Create(ctx context.Context, product *entity.Product) (*entity.Product, error) {
+	ctx, span := tracing.StartSpan(ctx, "ProductService - Create")
+	defer span.End()
+
+	latest, err := svc.productRepository.FindLatest(ctx, product.Code)
+	switch {
+	case err == nil:
+		product.Version = latest.Version + 1
+	case status.Code(err) == codes.NotFound:
+		product.Version = 1
+	default:
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [ProductService - Create] Error while find latest product version:", parseError.Message)
+		return nil, err
+	}
+
+	now := time.Now()
+	if product.EffectiveFrom.IsZero() {
+		product.EffectiveFrom = now
+	}
+	if product.EffectiveTo != nil && !product.EffectiveTo.After(product.EffectiveFrom) {
+		return nil, status.Errorf(codes.InvalidArgument, "effective_to must be after effective_from")
+	}
+	product.CreatedAt = now
+	product.UpdatedAt = now
+
+	// a concurrent publish of the same code takes the version first and is
+	// reported as AlreadyExists, the caller may simply retry
+	res, err := svc.productRepository.Create(ctx, product)
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [ProductService - Create] Error while create product:", parseError.Message)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Retire ends every version of code at effectiveTo, scheduled versions
+// included, and returns the latest version.
+func (svc *ProductService) Retire(ctx context.Context, code string, effectiveTo time.Time) (*entity.Product, error) {
+	ctx, span := tracing.StartSpan(ctx, "ProductService - Retire")
+	defer span.End()
+
+	latest, err := svc.productRepository.FindLatest(ctx, code)
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [ProductService - Retire] Error while find product:", parseError.Message)
+		return nil, err
+	}
+
+	if err := svc.productRepository.UpdateEffectiveTo(ctx, code, effectiveTo); err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [ProductService - Retire] Error while retire product:", parseError.Message)
+		return nil, err
+	}
+
+	return svc.productRepository.FindByCodeAndVersion(ctx, code, latest.Version)
+}
+
+// Resolve returns the product version a contract of tenor is priced with:
+// the pinned version, or the one effective now when version is 0. An unknown
+// product or a tenor the product does not offer is a field violation.
+func (svc *ProductService) Resolve(ctx context.Context, code string, version uint32, tenor uint32) (*entity.Product, *entity.ProductTenor, error) {
+	ctx, span := tracing.StartSpan(ctx, "ProductService - Resolve")
+	defer span.End()
+
+	product, err := svc.FindByCode(ctx, code, version)
+	if status.Code(err) == codes.NotFound {
+		v := validation.NewViolations()
+		v.Add("product_code", "is not a product on offer")
+		return nil, nil, v.Err()
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	productTenor := product.Tenor(tenor)
+	if productTenor == nil {
+		var offered []uint32
+		for _, t := range product.Tenors {
+			offered = append(offered, t.Tenor)
+		}
+		v := validation.NewViolations()
+		v.Add("tenor", "must be one of %s offered by %s", fmt.Sprint(offered), code)
+		return nil, nil, v.Err()
+	}
+
+	return product, productTenor, nil
+}
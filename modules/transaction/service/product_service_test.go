@@ -0,0 +1,115 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"xyz-transaction-service/common/config"
+	"xyz-transaction-service/common/validation"
+	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/modules/transaction/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Mock for ProductRepositoryUseCase
+type MockProductRepository struct {
+	mock.Mock
+}
+
+func (m *MockProductRepository) FindAllEffective(ctx context.Context, at time.Time) ([]*entity.Product, error) {
+	args := m.Called(ctx, at)
+	products, _ := args.Get(0).([]*entity.Product)
+	return products, args.Error(1)
+}
+
+func (m *MockProductRepository) FindEffective(ctx context.Context, code string, at time.Time) (*entity.Product, error) {
+	args := m.Called(ctx, code, at)
+	product, _ := args.Get(0).(*entity.Product)
+	return product, args.Error(1)
+}
+
+func (m *MockProductRepository) FindByCodeAndVersion(ctx context.Context, code string, version uint32) (*entity.Product, error) {
+	args := m.Called(ctx, code, version)
+	product, _ := args.Get(0).(*entity.Product)
+	return product, args.Error(1)
+}
+
+func (m *MockProductRepository) FindLatest(ctx context.Context, code string) (*entity.Product, error) {
+	args := m.Called(ctx, code)
+	product, _ := args.Get(0).(*entity.Product)
+	return product, args.Error(1)
+}
+
+// Create hands req back like the repository does
+func (m *MockProductRepository) Create(ctx context.Context, req *entity.Product) (*entity.Product, error) {
+	if err := m.Called(ctx, req).Error(0); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func (m *MockProductRepository) UpdateEffectiveTo(ctx context.Context, code string, effectiveTo time.Time) error {
+	return m.Called(ctx, code, effectiveTo).Error(0)
+}
+
+func motorProduct() *entity.Product {
+	return &entity.Product{
+		Code:           "MOTOR",
+		Version:        3,
+		PricingMethod:  "flat",
+		AdminFeeType:   entity.AdminFeeTypeFlat,
+		AdminFeeAmount: 50000,
+		EffectiveFrom:  time.Now().Add(-time.Hour),
+		Tenors: []*entity.ProductTenor{
+			{Tenor: 6, AnnualRateBps: 1800},
+			{Tenor: 12, AnnualRateBps: 2400},
+		},
+	}
+}
+
+func TestProductCreateAssignsNextVersion(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockRepo.On("FindLatest", mock.Anything, "MOTOR").Return(motorProduct(), nil)
+	mockRepo.On("FindLatest", mock.Anything, "CAR").Return(nil, status.Error(codes.NotFound, "Product not found: CAR"))
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Product")).Return(nil)
+
+	svc := service.NewProductService(config.Config{}, mockRepo)
+
+	motor, err := svc.Create(context.Background(), &entity.Product{Code: "MOTOR"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(4), motor.Version)
+	assert.False(t, motor.EffectiveFrom.IsZero())
+
+	car, err := svc.Create(context.Background(), &entity.Product{Code: "CAR"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1), car.Version)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProductResolveRejectsUnofferedTenor(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockRepo.On("FindByCodeAndVersion", mock.Anything, "MOTOR", uint32(3)).Return(motorProduct(), nil)
+	mockRepo.On("FindEffective", mock.Anything, "BOAT", mock.Anything).Return(nil, status.Error(codes.NotFound, "No version of product BOAT is effective"))
+
+	svc := service.NewProductService(config.Config{}, mockRepo)
+
+	_, productTenor, err := svc.Resolve(context.Background(), "MOTOR", 3, 12)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2400), productTenor.AnnualRateBps)
+
+	_, _, err = svc.Resolve(context.Background(), "MOTOR", 3, 24)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	if violations := validation.FieldViolations(err); assert.Len(t, violations, 1) {
+		assert.Equal(t, "tenor", violations[0].Field)
+	}
+
+	_, _, err = svc.Resolve(context.Background(), "BOAT", 0, 12)
+	if violations := validation.FieldViolations(err); assert.Len(t, violations, 1) {
+		assert.Equal(t, "product_code", violations[0].Field)
+	}
+}
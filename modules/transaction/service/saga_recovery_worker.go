@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+	"xyz-transaction-service/common/config"
+	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/modules/transaction/internal/repository"
+)
+
+// SagaRecoveryWorker periodically resumes sagas that were left half-done, for
+// example by a restart between two steps.
+type SagaRecoveryWorker struct {
+	cfg            config.Config
+	sagaRepository repository.SagaRepositoryUseCase
+	saga           CreateTransactionSagaUseCase
+}
+
+func NewSagaRecoveryWorker(cfg config.Config, sagaRepository repository.SagaRepositoryUseCase, saga CreateTransactionSagaUseCase) *SagaRecoveryWorker {
+	return &SagaRecoveryWorker{
+		cfg:            cfg,
+		sagaRepository: sagaRepository,
+		saga:           saga,
+	}
+}
+
+func (w *SagaRecoveryWorker) Run(ctx context.Context) {
+	interval := w.cfg.Saga.RecoveryInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		w.RecoverOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RecoverOnce resumes every saga idle for longer than the grace period and
+// returns how many of them were picked up.
+func (w *SagaRecoveryWorker) RecoverOnce(ctx context.Context) int {
+	sagas, err := w.sagaRepository.FindPending(ctx, time.Now().Add(-w.cfg.Saga.RecoveryGracePeriod), max(w.cfg.Saga.RecoveryBatchSize, 1))
+	if err != nil {
+		log.Println("ERROR: [SagaRecoveryWorker - RecoverOnce] Error while find pending sagas:", err)
+		return 0
+	}
+
+	recovered := 0
+	for _, saga := range sagas {
+		claimed, err := w.sagaRepository.Claim(ctx, saga)
+		if err != nil || !claimed {
+			continue
+		}
+		recovered++
+
+		if w.cfg.Saga.MaxAttempts > 0 && saga.Attempts > w.cfg.Saga.MaxAttempts {
+			log.Printf("ERROR: [SagaRecoveryWorker - RecoverOnce] Saga %s exceeded %d attempts, manual intervention required\n", saga.Reference, w.cfg.Saga.MaxAttempts)
+			saga.Status = entity.SagaStatusFailed
+			_ = w.sagaRepository.Update(ctx, saga)
+			continue
+		}
+
+		log.Printf("INFO: [SagaRecoveryWorker - RecoverOnce] Resuming saga %s in status %s\n", saga.Reference, saga.Status)
+		if err := w.saga.Resume(ctx, saga); err != nil {
+			log.Printf("ERROR: [SagaRecoveryWorker - RecoverOnce] Error while resume saga %s: %v\n", saga.Reference, err)
+		}
+	}
+
+	return recovered
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+	commonJwt "xyz-transaction-service/common/jwt"
+	"xyz-transaction-service/common/utils"
+	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/modules/transaction/internal/repository"
+)
+
+// TransactionAuditor appends transaction_events rows. Callers record inside
+// the database transaction of the change so an audited change is never lost.
+type TransactionAuditor struct {
+	transactionEventRepository repository.TransactionEventRepositoryUseCase
+}
+
+func NewTransactionAuditor(transactionEventRepository repository.TransactionEventRepositoryUseCase) *TransactionAuditor {
+	return &TransactionAuditor{
+		transactionEventRepository: transactionEventRepository,
+	}
+}
+
+// Record stores one event. The actor comes from the JWT claims of the caller
+// and the request id from the x-request-id metadata.
+func (a *TransactionAuditor) Record(ctx context.Context, eventType string, before, after *entity.Transaction, reason string) error {
+	subject := after
+	if subject == nil {
+		subject = before
+	}
+
+	beforeSnapshot, err := entity.TransactionSnapshot(before)
+	if err != nil {
+		return err
+	}
+	afterSnapshot, err := entity.TransactionSnapshot(after)
+	if err != nil {
+		return err
+	}
+
+	event := &entity.TransactionEvent{
+		TransactionId:  subject.Id,
+		ContractNumber: subject.ContractNumber,
+		EventType:      eventType,
+		Actor:          entity.TransactionEventSystemActor,
+		RequestId:      utils.GetMetadataRequestId(ctx),
+		Before:         beforeSnapshot,
+		After:          afterSnapshot,
+		Reason:         reason,
+		CreatedAt:      time.Now(),
+	}
+	if claims, ok := commonJwt.FromContext(ctx); ok {
+		event.Actor = claims.Cred
+		event.ActorRole = claims.Role
+	}
+
+	if err := a.transactionEventRepository.Create(ctx, event); err != nil {
+		log.Println("ERROR: [TransactionAuditor - Record] Error while record transaction event:", err)
+		return err
+	}
+
+	return nil
+}
+
+// RecordDenial stores a refused access attempt. Failures are only logged, the
+// caller is denied either way.
+func (a *TransactionAuditor) RecordDenial(ctx context.Context, transaction *entity.Transaction, reason string) {
+	event := &entity.TransactionEvent{
+		EventType: entity.TransactionEventAccessDenied,
+		Actor:     entity.TransactionEventSystemActor,
+		RequestId: utils.GetMetadataRequestId(ctx),
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+	if transaction != nil {
+		event.TransactionId = transaction.Id
+		event.ContractNumber = transaction.ContractNumber
+	}
+	if claims, ok := commonJwt.FromContext(ctx); ok {
+		event.Actor = claims.Cred
+		event.ActorRole = claims.Role
+	}
+
+	if err := a.transactionEventRepository.Create(ctx, event); err != nil {
+		log.Println("ERROR: [TransactionAuditor - RecordDenial] Error while record access denial:", err)
+	}
+}
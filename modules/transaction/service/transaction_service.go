@@ -6,45 +6,192 @@ import (
 	"time"
 	"xyz-transaction-service/common/config"
 	commonErr "xyz-transaction-service/common/error"
-	"xyz-transaction-service/common/utils"
+	gormConn "xyz-transaction-service/common/gorm"
+	"xyz-transaction-service/common/tracing"
+	"xyz-transaction-service/common/validation"
 	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/modules/transaction/internal/numbering"
+	"xyz-transaction-service/modules/transaction/internal/pricing"
 	"xyz-transaction-service/modules/transaction/internal/repository"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type TransactionService struct {
-	cfg                   config.Config
-	transactionRepository repository.TransactionRepositoryUseCase
+	cfg                        config.Config
+	transactor                 gormConn.Transactor
+	transactionRepository      repository.TransactionRepositoryUseCase
+	installmentRepository      repository.InstallmentRepositoryUseCase
+	transactionEventRepository repository.TransactionEventRepositoryUseCase
+	auditor                    *TransactionAuditor
+	pricingEngine              pricing.EngineUseCase
+	stateMachine               *TransactionStateMachine
+	contractNumberGenerator    numbering.ContractNumberGenerator
+	productService             ProductServiceUseCase
 }
 
-func NewTransactionService(cfg config.Config, transactionRepository repository.TransactionRepositoryUseCase) *TransactionService {
+func NewTransactionService(cfg config.Config, transactor gormConn.Transactor, transactionRepository repository.TransactionRepositoryUseCase, installmentRepository repository.InstallmentRepositoryUseCase, transactionEventRepository repository.TransactionEventRepositoryUseCase, contractNumberGenerator numbering.ContractNumberGenerator, productService ProductServiceUseCase) *TransactionService {
 	return &TransactionService{
-		cfg:                   cfg,
-		transactionRepository: transactionRepository,
+		cfg:                        cfg,
+		transactor:                 transactor,
+		transactionRepository:      transactionRepository,
+		installmentRepository:      installmentRepository,
+		transactionEventRepository: transactionEventRepository,
+		auditor:                    NewTransactionAuditor(transactionEventRepository),
+		pricingEngine:              pricing.NewEngine(cfg.Pricing),
+		stateMachine:               NewTransactionStateMachine(),
+		contractNumberGenerator:    contractNumberGenerator,
+		productService:             productService,
 	}
 }
 
 type TransactionServiceUseCase interface {
-	FindAll(ctx context.Context, req any) ([]*entity.Transaction, error)
-	FindByConsumerId(ctx context.Context, consumerId uint64) ([]*entity.Transaction, error)
+	FindAll(ctx context.Context, filter *entity.TransactionFilter, pageSize uint32, pageToken string) ([]*entity.Transaction, string, error)
+	Stream(ctx context.Context, filter *entity.TransactionFilter, batchSize uint32, send func(*entity.Transaction) error) error
+	FindByConsumerId(ctx context.Context, consumerId uint64, filter *entity.TransactionFilter) ([]*entity.Transaction, error)
 	FindById(ctx context.Context, id uint64) (*entity.Transaction, error)
 	FindByContractNumber(ctx context.Context, contractNumber string) (*entity.Transaction, error)
-	Create(ctx context.Context, consumerId uint64, tenor uint32, otr, adminFee, installment, interest uint64, assetName string) (*entity.Transaction, error)
-	Rollback(ctx context.Context, id uint64) error
+	Quote(ctx context.Context, productCode string, productVersion uint32, otr uint64, tenor uint32, adminFee, installment, interest uint64) (*pricing.Quote, error)
+	GenerateContractNumber(ctx context.Context, consumerId uint64) (string, error)
+	ValidateContractNumber(contractNumber string) error
+	Create(ctx context.Context, contractNumber string, consumerId uint64, productCode string, productVersion uint32, tenor uint32, otr, adminFee, installment, interest uint64, assetName string) (*entity.Transaction, error)
+	UpdateStatus(ctx context.Context, contractNumber string, newStatus string) (*entity.Transaction, error)
+	Rollback(ctx context.Context, id uint64, reason string) error
+	FindInstallmentsByTransactionId(ctx context.Context, transactionId uint64) ([]*entity.Installment, error)
+	FindHistoryByContractNumber(ctx context.Context, contractNumber string) ([]*entity.TransactionEvent, error)
+}
+
+func validateTransactionFilter(filter *entity.TransactionFilter) error {
+	if filter == nil {
+		return nil
+	}
+	if filter.Status != "" && !entity.IsValidTransactionStatus(filter.Status) {
+		return status.Errorf(codes.InvalidArgument, "unknown transaction status: %s", filter.Status)
+	}
+	if filter.OtrMax != 0 && filter.OtrMin > filter.OtrMax {
+		return status.Errorf(codes.InvalidArgument, "otr_min must not be greater than otr_max")
+	}
+	if !filter.CreatedFrom.IsZero() && !filter.CreatedTo.IsZero() && !filter.CreatedFrom.Before(filter.CreatedTo) {
+		return status.Errorf(codes.InvalidArgument, "created_from must be before created_to")
+	}
+	return nil
+}
+
+// transactionPage resolves the requested page size against the configured
+// default and cap, and decodes the page token into a cursor.
+func (svc *TransactionService) transactionPage(pageSize uint32, pageToken string) (*entity.TransactionPage, error) {
+	size := pageSize
+	if size == 0 {
+		size = svc.cfg.Pagination.DefaultPageSize
+	}
+	if svc.cfg.Pagination.MaxPageSize > 0 && size > svc.cfg.Pagination.MaxPageSize {
+		size = svc.cfg.Pagination.MaxPageSize
+	}
+	if size == 0 {
+		size = 1
+	}
+
+	page := &entity.TransactionPage{Size: int(size)}
+	if pageToken != "" {
+		cursor, err := entity.DecodeTransactionCursor(pageToken)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid page token")
+		}
+		page.After = cursor
+	}
+
+	return page, nil
 }
 
-func (svc *TransactionService) FindAll(ctx context.Context, req any) ([]*entity.Transaction, error) {
-	res, err := svc.transactionRepository.FindAll(ctx, req)
+// FindAll returns one page of transactions, newest first, and the token of
+// the next page, which is empty on the last page.
+func (svc *TransactionService) FindAll(ctx context.Context, filter *entity.TransactionFilter, pageSize uint32, pageToken string) ([]*entity.Transaction, string, error) {
+	ctx, span := tracing.StartSpan(ctx, "TransactionService - FindAll")
+	defer span.End()
+
+	if err := validateTransactionFilter(filter); err != nil {
+		return nil, "", err
+	}
+
+	page, err := svc.transactionPage(pageSize, pageToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// one extra row tells whether another page follows
+	size := page.Size
+	page.Size++
+	res, err := svc.transactionRepository.FindAll(ctx, filter, page)
 	if err != nil {
 		parseError := commonErr.ParseError(err)
 		log.Println("ERROR: [TransactionService - FindAll] Error while find all transaction:", parseError.Message)
-		return nil, err
+		return nil, "", err
 	}
 
-	return res, nil
+	nextPageToken := ""
+	if len(res) > size {
+		res = res[:size]
+		nextPageToken = entity.NewTransactionCursor(res[len(res)-1]).Encode()
+	}
+
+	return res, nextPageToken, nil
 }
 
-func (svc *TransactionService) FindByConsumerId(ctx context.Context, consumerId uint64) ([]*entity.Transaction, error) {
-	res, err := svc.transactionRepository.FindByConsumerId(ctx, consumerId)
+// Stream walks every transaction matching the filter, newest first, one
+// keyset page at a time so memory stays bounded by the batch size. It stops
+// at the first send error or when the context is cancelled.
+func (svc *TransactionService) Stream(ctx context.Context, filter *entity.TransactionFilter, batchSize uint32, send func(*entity.Transaction) error) error {
+	ctx, span := tracing.StartSpan(ctx, "TransactionService - Stream")
+	defer span.End()
+
+	if err := validateTransactionFilter(filter); err != nil {
+		return err
+	}
+
+	// the client may ask for smaller batches, never for larger ones
+	if batchSize == 0 || (svc.cfg.Pagination.StreamBatchSize > 0 && batchSize > svc.cfg.Pagination.StreamBatchSize) {
+		batchSize = svc.cfg.Pagination.StreamBatchSize
+	}
+	page := &entity.TransactionPage{Size: int(max(batchSize, 1))}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		batch, err := svc.transactionRepository.FindAll(ctx, filter, page)
+		if err != nil {
+			if ctx.Err() != nil {
+				return status.FromContextError(ctx.Err()).Err()
+			}
+			parseError := commonErr.ParseError(err)
+			log.Println("ERROR: [TransactionService - Stream] Error while find transaction batch:", parseError.Message)
+			return err
+		}
+
+		for _, transaction := range batch {
+			if err := send(transaction); err != nil {
+				return err
+			}
+		}
+
+		if len(batch) < page.Size {
+			return nil
+		}
+		page.After = entity.NewTransactionCursor(batch[len(batch)-1])
+	}
+}
+
+func (svc *TransactionService) FindByConsumerId(ctx context.Context, consumerId uint64, filter *entity.TransactionFilter) ([]*entity.Transaction, error) {
+	ctx, span := tracing.StartSpan(ctx, "TransactionService - FindByConsumerId")
+	defer span.End()
+
+	if err := validateTransactionFilter(filter); err != nil {
+		return nil, err
+	}
+
+	res, err := svc.transactionRepository.FindByConsumerId(ctx, consumerId, filter)
 	if err != nil {
 		parseError := commonErr.ParseError(err)
 		log.Println("ERROR: [TransactionService - FindByConsumerId] Error while find transaction by consumer id:", parseError.Message)
@@ -55,6 +202,9 @@ func (svc *TransactionService) FindByConsumerId(ctx context.Context, consumerId
 }
 
 func (svc *TransactionService) FindById(ctx context.Context, id uint64) (*entity.Transaction, error) {
+	ctx, span := tracing.StartSpan(ctx, "TransactionService - FindById")
+	defer span.End()
+
 	res, err := svc.transactionRepository.FindById(ctx, id)
 	if err != nil {
 		parseError := commonErr.ParseError(err)
@@ -66,6 +216,9 @@ func (svc *TransactionService) FindById(ctx context.Context, id uint64) (*entity
 }
 
 func (svc *TransactionService) FindByContractNumber(ctx context.Context, contractNumber string) (*entity.Transaction, error) {
+	ctx, span := tracing.StartSpan(ctx, "TransactionService - FindByContractNumber")
+	defer span.End()
+
 	res, err := svc.transactionRepository.FindByContractNumber(ctx, contractNumber)
 	if err != nil {
 		parseError := commonErr.ParseError(err)
@@ -76,21 +229,154 @@ func (svc *TransactionService) FindByContractNumber(ctx context.Context, contrac
 	return res, nil
 }
 
-func (svc *TransactionService) Create(ctx context.Context, consumerId uint64, tenor uint32, otr, adminFee, installment, interest uint64, assetName string) (*entity.Transaction, error) {
+// Quote prices a contract with a catalog product, pinned to productVersion or
+// the version effective now when that is 0, or with the configured pricing
+// when productCode is empty and PRICING_REQUIRE_PRODUCT is off.
+func (svc *TransactionService) Quote(ctx context.Context, productCode string, productVersion uint32, otr uint64, tenor uint32, adminFee, installment, interest uint64) (*pricing.Quote, error) {
+	var quote *pricing.Quote
+	var err error
+	if productCode == "" {
+		if svc.cfg.Pricing.RequireProduct {
+			v := validation.NewViolations()
+			v.Add("product_code", "is required")
+			return nil, v.Err()
+		}
+		quote, err = svc.pricingEngine.Quote(otr, tenor)
+	} else {
+		quote, err = svc.quoteProduct(ctx, productCode, productVersion, otr, tenor)
+	}
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [TransactionService - Quote] Error while calculate pricing:", parseError.Message)
+		return nil, err
+	}
+
+	if err := svc.pricingEngine.Reconcile(quote, adminFee, installment, interest); err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [TransactionService - Quote] Client pricing rejected:", parseError.Message)
+		return nil, err
+	}
+
+	return quote, nil
+}
+
+func (svc *TransactionService) quoteProduct(ctx context.Context, productCode string, productVersion uint32, otr uint64, tenor uint32) (*pricing.Quote, error) {
+	product, productTenor, err := svc.productService.Resolve(ctx, productCode, productVersion, tenor)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := svc.pricingEngine.QuoteTerms(otr, tenor, pricing.Terms{
+		Method:        pricing.ParseMethod(product.PricingMethod),
+		AnnualRateBps: productTenor.AnnualRateBps,
+		AdminFee:      product.AdminFee(otr),
+	})
+	if err != nil {
+		return nil, err
+	}
+	quote.ProductCode = product.Code
+	quote.ProductVersion = product.Version
+
+	return quote, nil
+}
+
+// GenerateContractNumber issues a contract number with the configured
+// strategy. Only the unique key of transactions guarantees it is free.
+func (svc *TransactionService) GenerateContractNumber(ctx context.Context, consumerId uint64) (string, error) {
+	contractNumber, err := svc.contractNumberGenerator.Generate(ctx, consumerId)
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [TransactionService - GenerateContractNumber] Error while generate contract number:", parseError.Message)
+		return "", status.Errorf(codes.Unavailable, "could not generate a contract number")
+	}
+
+	return contractNumber, nil
+}
+
+// ValidateContractNumber rejects, as InvalidArgument, a contract number of a
+// shape no layout can produce, before it reaches the database.
+func (svc *TransactionService) ValidateContractNumber(contractNumber string) error {
+	return svc.contractNumberGenerator.Validate(contractNumber)
+}
+
+// Create prices and persists a contract with its schedule. An empty contract
+// number is generated, and regenerated when it collides with an existing
+// contract; callers such as the create saga pass a reserved one and get
+// AlreadyExists back on a collision.
+func (svc *TransactionService) Create(ctx context.Context, contractNumber string, consumerId uint64, productCode string, productVersion uint32, tenor uint32, otr, adminFee, installment, interest uint64, assetName string) (*entity.Transaction, error) {
+	ctx, span := tracing.StartSpan(ctx, "TransactionService - Create")
+	defer span.End()
+
+	quote, err := svc.Quote(ctx, productCode, productVersion, otr, tenor, adminFee, installment, interest)
+	if err != nil {
+		return nil, err
+	}
+
+	if contractNumber != "" {
+		return svc.create(ctx, contractNumber, consumerId, quote, assetName)
+	}
+
+	attempts := numbering.MaxAttempts(svc.cfg.ContractNumber)
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if contractNumber, err = svc.GenerateContractNumber(ctx, consumerId); err != nil {
+			return nil, err
+		}
+
+		res, err := svc.create(ctx, contractNumber, consumerId, quote, assetName)
+		if status.Code(err) != codes.AlreadyExists {
+			return res, err
+		}
+		log.Printf("WARNING: [TransactionService - Create] Contract number %s collided, attempt %d of %d\n", contractNumber, attempt, attempts)
+	}
+
+	return nil, status.Errorf(codes.Aborted, "could not allocate a unique contract number, please retry")
+}
+
+func (svc *TransactionService) create(ctx context.Context, contractNumber string, consumerId uint64, quote *pricing.Quote, assetName string) (*entity.Transaction, error) {
+	now := time.Now()
+
+	var installments []*entity.Installment
+	for _, item := range pricing.Schedule(quote, now) {
+		installments = append(installments, &entity.Installment{
+			ContractNumber:     contractNumber,
+			InstallmentNumber:  item.Number,
+			DueDate:            item.DueDate,
+			Principal:          item.Principal,
+			Interest:           item.Interest,
+			Fee:                item.Fee,
+			Amount:             item.Amount,
+			OutstandingBalance: item.OutstandingBalance,
+			Status:             entity.InstallmentStatusUnpaid,
+			CreatedAt:          now,
+			UpdatedAt:          now,
+		})
+	}
+
 	transaction := &entity.Transaction{
-		ContractNumber: utils.GenerateContractNumber(consumerId),
+		ContractNumber: contractNumber,
 		ConsumerId:     consumerId,
-		Tenor:          tenor,
-		Otr:            otr,
-		AdminFee:       adminFee,
-		Installment:    installment,
-		Interest:       interest,
+		Tenor:          quote.Tenor,
+		Otr:            quote.Otr,
+		AdminFee:       quote.AdminFee,
+		Installment:    quote.Installment,
+		Interest:       quote.Interest,
 		AssetName:      assetName,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
+		Status:         entity.TransactionStatusPending,
+		ProductCode:    quote.ProductCode,
+		ProductVersion: quote.ProductVersion,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		Installments:   installments,
 	}
 
-	res, err := svc.transactionRepository.Create(ctx, transaction)
+	var res *entity.Transaction
+	err := svc.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if res, err = svc.transactionRepository.Create(ctx, transaction); err != nil {
+			return err
+		}
+		return svc.auditor.Record(ctx, entity.TransactionEventCreated, nil, res, "")
+	})
 	if err != nil {
 		parseError := commonErr.ParseError(err)
 		log.Println("ERROR: [TransactionService - Create] Error while create transaction:", parseError.Message)
@@ -100,8 +386,55 @@ func (svc *TransactionService) Create(ctx context.Context, consumerId uint64, te
 	return res, nil
 }
 
-func (svc *TransactionService) Rollback(ctx context.Context, id uint64) error {
-	err := svc.transactionRepository.Delete(ctx, id)
+func (svc *TransactionService) UpdateStatus(ctx context.Context, contractNumber string, newStatus string) (*entity.Transaction, error) {
+	ctx, span := tracing.StartSpan(ctx, "TransactionService - UpdateStatus")
+	defer span.End()
+
+	transaction, err := svc.transactionRepository.FindByContractNumber(ctx, contractNumber)
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [TransactionService - UpdateStatus] Error while find transaction by contract number:", parseError.Message)
+		return nil, err
+	}
+
+	if err := svc.stateMachine.Validate(transaction.Status, newStatus); err != nil {
+		return nil, err
+	}
+
+	before := *transaction
+	transaction.Status = newStatus
+	err = svc.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.transactionRepository.UpdateStatus(ctx, transaction.Id, before.Status, newStatus); err != nil {
+			return err
+		}
+		return svc.auditor.Record(ctx, entity.TransactionEventStatusChanged, &before, transaction, "")
+	})
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [TransactionService - UpdateStatus] Error while update transaction status:", parseError.Message)
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// Rollback soft-deletes a contract that could not be completed and records
+// the reason in its history. A contract that is missing or already rolled
+// back is reported as NotFound. Its contract number is not released.
+func (svc *TransactionService) Rollback(ctx context.Context, id uint64, reason string) error {
+	ctx, span := tracing.StartSpan(ctx, "TransactionService - Rollback")
+	defer span.End()
+
+	err := svc.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		transaction, err := svc.transactionRepository.FindById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := svc.transactionRepository.Delete(ctx, id, reason); err != nil {
+			return err
+		}
+		return svc.auditor.Record(ctx, entity.TransactionEventRolledBack, transaction, nil, reason)
+	})
 	if err != nil {
 		parseError := commonErr.ParseError(err)
 		log.Println("ERROR: [TransactionService - Rollback] Error while rollback transaction:", parseError.Message)
@@ -110,3 +443,40 @@ func (svc *TransactionService) Rollback(ctx context.Context, id uint64) error {
 
 	return nil
 }
+
+// FindInstallmentsByTransactionId returns the schedule of a transaction the
+// caller has already loaded, oldest installment first.
+func (svc *TransactionService) FindInstallmentsByTransactionId(ctx context.Context, transactionId uint64) ([]*entity.Installment, error) {
+	ctx, span := tracing.StartSpan(ctx, "TransactionService - FindInstallmentsByTransactionId")
+	defer span.End()
+
+	res, err := svc.installmentRepository.FindByTransactionId(ctx, transactionId)
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [TransactionService - FindInstallmentsByTransactionId] Error while find installments by transaction id:", parseError.Message)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// FindHistoryByContractNumber returns the audit trail of a contract, oldest
+// first. It also covers rolled back contracts, which can no longer be read.
+func (svc *TransactionService) FindHistoryByContractNumber(ctx context.Context, contractNumber string) ([]*entity.TransactionEvent, error) {
+	ctx, span := tracing.StartSpan(ctx, "TransactionService - FindHistoryByContractNumber")
+	defer span.End()
+
+	res, err := svc.transactionEventRepository.FindByContractNumber(ctx, contractNumber)
+	if err != nil {
+		parseError := commonErr.ParseError(err)
+		log.Println("ERROR: [TransactionService - FindHistoryByContractNumber] Error while find transaction events:", parseError.Message)
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		log.Println("WARNING: [TransactionService - FindHistoryByContractNumber] No history for contract number:", contractNumber)
+		return nil, status.Errorf(codes.NotFound, "Transaction history not found for contract number: %v", contractNumber)
+	}
+
+	return res, nil
+}
@@ -2,29 +2,60 @@ package service_test
 
 import (
 	"context"
+	"math"
 	// "strings"
 	"testing"
 	"time"
 	"xyz-transaction-service/common/config"
+	commonJwt "xyz-transaction-service/common/jwt"
 	"xyz-transaction-service/modules/transaction/entity"
+	"xyz-transaction-service/modules/transaction/internal/numbering"
 	"xyz-transaction-service/modules/transaction/service"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
+// Mock for TransactionEventRepositoryUseCase
+type MockTransactionEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransactionEventRepository) FindByContractNumber(ctx context.Context, contractNumber string) ([]*entity.TransactionEvent, error) {
+	args := m.Called(ctx, contractNumber)
+	return args.Get(0).([]*entity.TransactionEvent), args.Error(1)
+}
+
+func (m *MockTransactionEventRepository) Create(ctx context.Context, event *entity.TransactionEvent) error {
+	return m.Called(ctx, event).Error(0)
+}
+
+// newMockTransactionEventRepository accepts every audit event
+func newMockTransactionEventRepository() *MockTransactionEventRepository {
+	m := new(MockTransactionEventRepository)
+	m.On("Create", mock.Anything, mock.Anything).Return(nil)
+	return m
+}
+
+func newContractNumberGenerator() numbering.ContractNumberGenerator {
+	return numbering.NewContractNumberGenerator(config.ContractNumber{}, nil)
+}
+
 // Mock for TransactionRepositoryUseCase
 type MockTransactionRepository struct {
 	mock.Mock
 }
 
-func (m *MockTransactionRepository) FindAll(ctx context.Context, req any) ([]*entity.Transaction, error) {
-	args := m.Called(ctx, req)
+func (m *MockTransactionRepository) FindAll(ctx context.Context, filter *entity.TransactionFilter, page *entity.TransactionPage) ([]*entity.Transaction, error) {
+	args := m.Called(ctx, filter, page)
 	return args.Get(0).([]*entity.Transaction), args.Error(1)
 }
 
-func (m *MockTransactionRepository) FindByConsumerId(ctx context.Context, consumerId uint64) ([]*entity.Transaction, error) {
-	args := m.Called(ctx, consumerId)
+func (m *MockTransactionRepository) FindByConsumerId(ctx context.Context, consumerId uint64, filter *entity.TransactionFilter) ([]*entity.Transaction, error) {
+	args := m.Called(ctx, consumerId, filter)
 	return args.Get(0).([]*entity.Transaction), args.Error(1)
 }
 
@@ -43,8 +74,33 @@ func (m *MockTransactionRepository) Create(ctx context.Context, transaction *ent
 	return args.Get(0).(*entity.Transaction), args.Error(1)
 }
 
-func (m *MockTransactionRepository) Delete(ctx context.Context, id uint64) error {
-	args := m.Called(ctx, id)
+func (m *MockTransactionRepository) UpdateStatus(ctx context.Context, id uint64, from, to string) error {
+	args := m.Called(ctx, id, from, to)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepository) Delete(ctx context.Context, id uint64, reason string) error {
+	args := m.Called(ctx, id, reason)
+	return args.Error(0)
+}
+
+// Mock for InstallmentRepositoryUseCase
+type MockInstallmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockInstallmentRepository) FindByTransactionId(ctx context.Context, transactionId uint64) ([]*entity.Installment, error) {
+	args := m.Called(ctx, transactionId)
+	return args.Get(0).([]*entity.Installment), args.Error(1)
+}
+
+func (m *MockInstallmentRepository) FindByTransactionIdForUpdate(ctx context.Context, transactionId uint64) ([]*entity.Installment, error) {
+	args := m.Called(ctx, transactionId)
+	return args.Get(0).([]*entity.Installment), args.Error(1)
+}
+
+func (m *MockInstallmentRepository) UpdatePayment(ctx context.Context, installment *entity.Installment) error {
+	args := m.Called(ctx, installment)
 	return args.Error(0)
 }
 
@@ -66,7 +122,7 @@ func TestFindById(t *testing.T) {
 
 	mockRepo.On("FindById", mock.Anything, uint64(1)).Return(mockTransaction, nil)
 
-	svc := service.NewTransactionService(config.Config{}, mockRepo)
+	svc := service.NewTransactionService(config.Config{}, MockTransactor{}, mockRepo, new(MockInstallmentRepository), newMockTransactionEventRepository(), newContractNumberGenerator(), nil)
 
 	result, err := svc.FindById(context.Background(), 1)
 
@@ -98,7 +154,7 @@ func TestFindById(t *testing.T) {
 
 // 	mockRepo.On("Create", mock.Anything, mockTransaction).Return(mockTransaction, nil)
 
-// 	svc := service.NewTransactionService(config.Config{}, mockRepo)
+// 	svc := service.NewTransactionService(config.Config{}, mockRepo, new(MockInstallmentRepository))
 
 // 	result, err := svc.Create(context.Background(), 3, 12, 300000, 18000, 135000, 12000, "Smartwatch")
 
@@ -120,14 +176,311 @@ func TestFindById(t *testing.T) {
 
 func TestRollback(t *testing.T) {
 	mockRepo := new(MockTransactionRepository)
+	mockEventRepo := newMockTransactionEventRepository()
 
-	mockRepo.On("Delete", mock.Anything, uint64(1)).Return(nil)
+	mockRepo.On("FindById", mock.Anything, uint64(1)).Return(&entity.Transaction{Id: 1, ContractNumber: "CN123", Status: entity.TransactionStatusPending}, nil)
+	mockRepo.On("Delete", mock.Anything, uint64(1), "limit debit failed").Return(nil)
 
-	svc := service.NewTransactionService(config.Config{}, mockRepo)
+	svc := service.NewTransactionService(config.Config{}, MockTransactor{}, mockRepo, new(MockInstallmentRepository), mockEventRepo, newContractNumberGenerator(), nil)
 
-	err := svc.Rollback(context.Background(), 1)
+	ctx := commonJwt.NewContext(context.Background(), &commonJwt.CustomClaims{Cred: "admin@xyz", Role: 1})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-request-id", "req-1"))
+	err := svc.Rollback(ctx, 1, "limit debit failed")
 
 	assert.NoError(t, err)
 
+	event := mockEventRepo.Calls[0].Arguments.Get(1).(*entity.TransactionEvent)
+	assert.Equal(t, entity.TransactionEventRolledBack, event.EventType)
+	assert.Equal(t, "CN123", event.ContractNumber)
+	assert.Equal(t, "admin@xyz", event.Actor)
+	assert.Equal(t, uint32(1), event.ActorRole)
+	assert.Equal(t, "req-1", event.RequestId)
+	assert.Equal(t, "limit debit failed", event.Reason)
+	assert.Contains(t, event.Before, `"contract_number":"CN123"`)
+	assert.Empty(t, event.After)
+
 	mockRepo.AssertExpectations(t)
 }
+
+func TestCreateDerivesPricing(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+
+	var created *entity.Transaction
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Transaction")).
+		Run(func(args mock.Arguments) { created = args.Get(1).(*entity.Transaction) }).
+		Return(&entity.Transaction{Id: 1}, nil)
+
+	cfg := config.Config{Pricing: config.Pricing{Method: "flat", AnnualRateBps: 2400}}
+	svc := service.NewTransactionService(cfg, MockTransactor{}, mockRepo, new(MockInstallmentRepository), newMockTransactionEventRepository(), newContractNumberGenerator(), nil)
+
+	// client-supplied installment and interest are overridden by the engine
+	_, err := svc.Create(context.Background(), "", 3, "", 0, 12, 1000000, 0, 1, 1, "Smartwatch")
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(103334), created.Installment)
+	assert.Equal(t, uint64(240008), created.Interest)
+	assert.Equal(t, uint64(0), created.AdminFee)
+	assert.Equal(t, entity.TransactionStatusPending, created.Status)
+	assert.Len(t, created.Installments, 12)
+	assert.Equal(t, uint64(0), created.Installments[11].OutstandingBalance)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateWithProductStoresVersion(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+
+	var created *entity.Transaction
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Transaction")).
+		Run(func(args mock.Arguments) { created = args.Get(1).(*entity.Transaction) }).
+		Return(&entity.Transaction{Id: 1}, nil)
+
+	mockProductRepo := new(MockProductRepository)
+	mockProductRepo.On("FindEffective", mock.Anything, "MOTOR", mock.Anything).Return(motorProduct(), nil)
+
+	// the configured pricing is ignored in favour of the product terms
+	cfg := config.Config{Pricing: config.Pricing{Method: "annuity", AnnualRateBps: 1000, AdminFeeBps: 100}}
+	productSvc := service.NewProductService(cfg, mockProductRepo)
+	svc := service.NewTransactionService(cfg, MockTransactor{}, mockRepo, new(MockInstallmentRepository), newMockTransactionEventRepository(), newContractNumberGenerator(), productSvc)
+
+	_, err := svc.Create(context.Background(), "", 3, "MOTOR", 0, 12, 1000000, 0, 0, 0, "Motorcycle")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "MOTOR", created.ProductCode)
+	assert.Equal(t, uint32(3), created.ProductVersion)
+	assert.Equal(t, uint64(50000), created.AdminFee)
+	assert.Equal(t, uint64(240000), created.Interest)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestQuoteRequiresProduct(t *testing.T) {
+	cfg := config.Config{Pricing: config.Pricing{RequireProduct: true}}
+	svc := service.NewTransactionService(cfg, MockTransactor{}, new(MockTransactionRepository), new(MockInstallmentRepository), newMockTransactionEventRepository(), newContractNumberGenerator(), nil)
+
+	_, err := svc.Quote(context.Background(), "", 0, 1000000, 12, 0, 0, 0)
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestCreateRegeneratesCollidingContractNumber(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+
+	var contractNumbers []string
+	record := func(args mock.Arguments) {
+		contractNumbers = append(contractNumbers, args.Get(1).(*entity.Transaction).ContractNumber)
+	}
+	mockRepo.On("Create", mock.Anything, mock.Anything).Run(record).
+		Return((*entity.Transaction)(nil), status.Error(codes.AlreadyExists, "Contract number already exists")).Once()
+	mockRepo.On("Create", mock.Anything, mock.Anything).Run(record).
+		Return(&entity.Transaction{Id: 1}, nil).Once()
+
+	cfg := config.Config{ContractNumber: config.ContractNumber{MaxAttempts: 3}}
+	svc := service.NewTransactionService(cfg, MockTransactor{}, mockRepo, new(MockInstallmentRepository), newMockTransactionEventRepository(), newContractNumberGenerator(), nil)
+
+	result, err := svc.Create(context.Background(), "", 3, "", 0, 12, 1000000, 0, 0, 0, "Laptop")
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), result.Id)
+	assert.Len(t, contractNumbers, 2)
+	assert.NotEqual(t, contractNumbers[0], contractNumbers[1])
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateGivesUpOnRepeatedCollisions(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	mockRepo.On("Create", mock.Anything, mock.Anything).
+		Return((*entity.Transaction)(nil), status.Error(codes.AlreadyExists, "Contract number already exists")).Times(3)
+
+	cfg := config.Config{ContractNumber: config.ContractNumber{MaxAttempts: 3}}
+	svc := service.NewTransactionService(cfg, MockTransactor{}, mockRepo, new(MockInstallmentRepository), newMockTransactionEventRepository(), newContractNumberGenerator(), nil)
+
+	_, err := svc.Create(context.Background(), "", 3, "", 0, 12, 1000000, 0, 0, 0, "Laptop")
+
+	assert.Equal(t, codes.Aborted, status.Code(err))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateReservedContractNumberCollision(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	mockRepo.On("Create", mock.Anything, mock.Anything).
+		Return((*entity.Transaction)(nil), status.Error(codes.AlreadyExists, "Contract number already exists")).Once()
+
+	svc := service.NewTransactionService(config.Config{}, MockTransactor{}, mockRepo, new(MockInstallmentRepository), newMockTransactionEventRepository(), newContractNumberGenerator(), nil)
+
+	// a reserved number belongs to the caller, which picks the next one itself
+	_, err := svc.Create(context.Background(), "CNTR-20240101-00000003-0a1b2c3d", 3, "", 0, 12, 1000000, 0, 0, 0, "Laptop")
+
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFindInstallmentsByTransactionId(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	mockInstallmentRepo := new(MockInstallmentRepository)
+
+	mockInstallmentRepo.On("FindByTransactionId", mock.Anything, uint64(1)).Return([]*entity.Installment{
+		{Id: 1, TransactionId: 1, InstallmentNumber: 1},
+		{Id: 2, TransactionId: 1, InstallmentNumber: 2},
+	}, nil)
+
+	svc := service.NewTransactionService(config.Config{}, MockTransactor{}, mockRepo, mockInstallmentRepo, newMockTransactionEventRepository(), newContractNumberGenerator(), nil)
+
+	result, err := svc.FindInstallmentsByTransactionId(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+
+	mockRepo.AssertExpectations(t)
+	mockInstallmentRepo.AssertExpectations(t)
+}
+
+func TestUpdateStatus(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+
+	mockRepo.On("FindByContractNumber", mock.Anything, "CN123").Return(&entity.Transaction{Id: 1, ContractNumber: "CN123", Status: entity.TransactionStatusActive}, nil)
+	mockRepo.On("UpdateStatus", mock.Anything, uint64(1), entity.TransactionStatusActive, entity.TransactionStatusDefaulted).Return(nil)
+
+	svc := service.NewTransactionService(config.Config{}, MockTransactor{}, mockRepo, new(MockInstallmentRepository), newMockTransactionEventRepository(), newContractNumberGenerator(), nil)
+
+	result, err := svc.UpdateStatus(context.Background(), "CN123", entity.TransactionStatusDefaulted)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.TransactionStatusDefaulted, result.Status)
+
+	_, err = svc.UpdateStatus(context.Background(), "CN123", entity.TransactionStatusPending)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = svc.UpdateStatus(context.Background(), "CN123", "UNKNOWN")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFindAllRejectsUnknownStatus(t *testing.T) {
+	svc := service.NewTransactionService(config.Config{}, MockTransactor{}, new(MockTransactionRepository), new(MockInstallmentRepository), newMockTransactionEventRepository(), newContractNumberGenerator(), nil)
+
+	_, _, err := svc.FindAll(context.Background(), &entity.TransactionFilter{Status: "UNKNOWN"}, 0, "")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestFindAllPaginates(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	page := []*entity.Transaction{
+		{Id: 30, CreatedAt: createdAt},
+		{Id: 29, CreatedAt: createdAt},
+		{Id: 28, CreatedAt: createdAt.Add(-time.Hour)},
+	}
+	// the page size is capped at 2 and the service asks for one extra row
+	mockRepo.On("FindAll", mock.Anything, mock.Anything, &entity.TransactionPage{Size: 3}).Return(page, nil).Once()
+
+	cfg := config.Config{Pagination: config.Pagination{DefaultPageSize: 1, MaxPageSize: 2}}
+	svc := service.NewTransactionService(cfg, MockTransactor{}, mockRepo, new(MockInstallmentRepository), newMockTransactionEventRepository(), newContractNumberGenerator(), nil)
+
+	result, nextPageToken, err := svc.FindAll(context.Background(), &entity.TransactionFilter{}, 50, "")
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.NotEmpty(t, nextPageToken)
+
+	cursor, err := entity.DecodeTransactionCursor(nextPageToken)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(29), cursor.Id)
+	assert.True(t, createdAt.Equal(cursor.CreatedAt))
+
+	mockRepo.On("FindAll", mock.Anything, mock.Anything, &entity.TransactionPage{Size: 2, After: cursor}).Return(page[2:], nil).Once()
+
+	result, nextPageToken, err = svc.FindAll(context.Background(), &entity.TransactionFilter{}, 0, nextPageToken)
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Empty(t, nextPageToken)
+
+	_, _, err = svc.FindAll(context.Background(), &entity.TransactionFilter{}, 0, "not a token")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestStreamWalksAllBatches(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	first := []*entity.Transaction{{Id: 3, CreatedAt: createdAt}, {Id: 2, CreatedAt: createdAt}}
+	second := []*entity.Transaction{{Id: 1, CreatedAt: createdAt}}
+
+	mockRepo.On("FindAll", mock.Anything, mock.Anything, &entity.TransactionPage{Size: 2}).Return(first, nil).Once()
+	mockRepo.On("FindAll", mock.Anything, mock.Anything, &entity.TransactionPage{Size: 2, After: &entity.TransactionCursor{CreatedAt: createdAt, Id: 2}}).Return(second, nil).Once()
+
+	svc := service.NewTransactionService(config.Config{}, MockTransactor{}, mockRepo, new(MockInstallmentRepository), newMockTransactionEventRepository(), newContractNumberGenerator(), nil)
+
+	var ids []uint64
+	err := svc.Stream(context.Background(), &entity.TransactionFilter{}, 2, func(t *entity.Transaction) error {
+		ids = append(ids, t.Id)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{3, 2, 1}, ids)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestStreamCapsBatchSize(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	mockRepo.On("FindAll", mock.Anything, mock.Anything, &entity.TransactionPage{Size: 500}).Return([]*entity.Transaction{}, nil).Once()
+
+	cfg := config.Config{Pagination: config.Pagination{StreamBatchSize: 500}}
+	svc := service.NewTransactionService(cfg, MockTransactor{}, mockRepo, new(MockInstallmentRepository), newMockTransactionEventRepository(), newContractNumberGenerator(), nil)
+
+	err := svc.Stream(context.Background(), &entity.TransactionFilter{}, math.MaxUint32, func(t *entity.Transaction) error {
+		return nil
+	})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestStreamStopsOnCancellation(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	mockRepo.On("FindAll", mock.Anything, mock.Anything, mock.Anything).Return([]*entity.Transaction{{Id: 3, CreatedAt: createdAt}}, nil).Once()
+
+	svc := service.NewTransactionService(config.Config{}, MockTransactor{}, mockRepo, new(MockInstallmentRepository), newMockTransactionEventRepository(), newContractNumberGenerator(), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := svc.Stream(ctx, &entity.TransactionFilter{}, 1, func(t *entity.Transaction) error {
+		cancel()
+		return nil
+	})
+
+	assert.Equal(t, codes.Canceled, status.Code(err))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateStatusRecordsEvent(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	mockEventRepo := newMockTransactionEventRepository()
+
+	mockRepo.On("FindByContractNumber", mock.Anything, "CN123").Return(&entity.Transaction{Id: 1, ContractNumber: "CN123", Status: entity.TransactionStatusPending}, nil)
+	mockRepo.On("UpdateStatus", mock.Anything, uint64(1), entity.TransactionStatusPending, entity.TransactionStatusActive).Return(nil)
+
+	svc := service.NewTransactionService(config.Config{}, MockTransactor{}, mockRepo, new(MockInstallmentRepository), mockEventRepo, newContractNumberGenerator(), nil)
+
+	_, err := svc.UpdateStatus(context.Background(), "CN123", entity.TransactionStatusActive)
+	assert.NoError(t, err)
+
+	event := mockEventRepo.Calls[0].Arguments.Get(1).(*entity.TransactionEvent)
+	assert.Equal(t, entity.TransactionEventStatusChanged, event.EventType)
+	assert.Equal(t, entity.TransactionEventSystemActor, event.Actor)
+	assert.Contains(t, event.Before, `"status":"PENDING"`)
+	assert.Contains(t, event.After, `"status":"ACTIVE"`)
+}
+
+func TestFindHistoryByContractNumberNotFound(t *testing.T) {
+	mockEventRepo := new(MockTransactionEventRepository)
+	mockEventRepo.On("FindByContractNumber", mock.Anything, "CN404").Return([]*entity.TransactionEvent{}, nil)
+
+	svc := service.NewTransactionService(config.Config{}, MockTransactor{}, new(MockTransactionRepository), new(MockInstallmentRepository), mockEventRepo, newContractNumberGenerator(), nil)
+
+	_, err := svc.FindHistoryByContractNumber(context.Background(), "CN404")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
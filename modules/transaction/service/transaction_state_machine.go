@@ -0,0 +1,59 @@
+package service
+
+import (
+	"log"
+	"xyz-transaction-service/modules/transaction/entity"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TransactionStateMachine guards the contract lifecycle:
+//
+//	PENDING   -> ACTIVE, CANCELLED
+//	ACTIVE    -> PAID_OFF, DEFAULTED, CANCELLED
+//	DEFAULTED -> ACTIVE, PAID_OFF, WRITTEN_OFF
+//
+// PAID_OFF, CANCELLED and WRITTEN_OFF are terminal.
+type TransactionStateMachine struct {
+	transitions map[string][]string
+}
+
+func NewTransactionStateMachine() *TransactionStateMachine {
+	return &TransactionStateMachine{
+		transitions: map[string][]string{
+			entity.TransactionStatusPending:   {entity.TransactionStatusActive, entity.TransactionStatusCancelled},
+			entity.TransactionStatusActive:    {entity.TransactionStatusPaidOff, entity.TransactionStatusDefaulted, entity.TransactionStatusCancelled},
+			entity.TransactionStatusDefaulted: {entity.TransactionStatusActive, entity.TransactionStatusPaidOff, entity.TransactionStatusWrittenOff},
+		},
+	}
+}
+
+func (sm *TransactionStateMachine) CanTransition(from, to string) bool {
+	for _, next := range sm.transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns InvalidArgument for an unknown target status and
+// FailedPrecondition when the transition is not allowed from the current one.
+func (sm *TransactionStateMachine) Validate(from, to string) error {
+	if !entity.IsValidTransactionStatus(to) {
+		return status.Errorf(codes.InvalidArgument, "unknown transaction status: %s", to)
+	}
+
+	if !sm.CanTransition(from, to) {
+		log.Printf("WARNING: [TransactionStateMachine - Validate] Illegal transition from %s to %s\n", from, to)
+		return status.Errorf(codes.FailedPrecondition, "cannot change transaction status from %s to %s", from, to)
+	}
+
+	return nil
+}
+
+// AcceptsPayment reports whether money can still be applied to the contract.
+func (sm *TransactionStateMachine) AcceptsPayment(current string) bool {
+	return current == entity.TransactionStatusActive || current == entity.TransactionStatusDefaulted
+}
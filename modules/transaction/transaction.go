@@ -1,15 +1,32 @@
 package transaction
 
 import (
+	"context"
+	"xyz-transaction-service/common/authorization"
 	"xyz-transaction-service/common/config"
 	"xyz-transaction-service/modules/transaction/internal/builder"
 	"xyz-transaction-service/pb"
+	"xyz-transaction-service/server"
 
-	"google.golang.org/grpc"
 	"gorm.io/gorm"
 )
 
-func InitGrpc(server *grpc.Server, cfg config.Config, db *gorm.DB, grpcConn *grpc.ClientConn) {
-	transaction := builder.BuildTransactionHandler(cfg, db, grpcConn)
-	pb.RegisterTransactionServiceServer(server, transaction)
+// InitGrpc registers TransactionService on grpcServer. consumerLimitClient is
+// the in-process client of an embedded ConsumerLimitService, or nil to dial
+// the one at CLIENT_URL_CONSUMER.
+func InitGrpc(grpcServer *server.Grpc, cfg config.Config, db *gorm.DB, consumerLimitClient pb.ConsumerLimitServiceClient, policy *authorization.Policy) {
+	transaction, sagaRecoveryWorker, idempotencyKeySweeper, consumerLimitSvc := builder.BuildTransactionHandler(cfg, db, consumerLimitClient, policy)
+	pb.RegisterTransactionServiceServer(grpcServer.Server, transaction)
+	if consumerLimitClient == nil {
+		grpcServer.Health.AddCheck("consumer_limit", consumerLimitSvc.CheckConnection)
+	}
+
+	go sagaRecoveryWorker.Run(context.Background())
+	go idempotencyKeySweeper.Run(context.Background())
+}
+
+// InitGateway serves the REST bindings of TransactionService, see the
+// google.api.http options of transaction.proto.
+func InitGateway(ctx context.Context, gateway *server.Gateway) error {
+	return gateway.Register(ctx, pb.RegisterTransactionServiceHandler)
 }
@@ -22,6 +22,7 @@ const (
 	ConsumerLimitService_GetConsumerLimitsByConsumerId_FullMethodName        = "/xyz_grpc.ConsumerLimitService/GetConsumerLimitsByConsumerId"
 	ConsumerLimitService_CreateConsumerLimit_FullMethodName                  = "/xyz_grpc.ConsumerLimitService/CreateConsumerLimit"
 	ConsumerLimitService_UpdateAvailableLimit_FullMethodName                 = "/xyz_grpc.ConsumerLimitService/UpdateAvailableLimit"
+	ConsumerLimitService_RestoreAvailableLimit_FullMethodName                = "/xyz_grpc.ConsumerLimitService/RestoreAvailableLimit"
 	ConsumerLimitService_GetConsumerLimitByConsumerIdAndTenor_FullMethodName = "/xyz_grpc.ConsumerLimitService/GetConsumerLimitByConsumerIdAndTenor"
 )
 
@@ -32,6 +33,7 @@ type ConsumerLimitServiceClient interface {
 	GetConsumerLimitsByConsumerId(ctx context.Context, in *ConsumerRequest, opts ...grpc.CallOption) (*ConsumerLimitListResponse, error)
 	CreateConsumerLimit(ctx context.Context, in *ConsumerLimit, opts ...grpc.CallOption) (*ConsumerLimitResponse, error)
 	UpdateAvailableLimit(ctx context.Context, in *UpdateAvailableLimitRequest, opts ...grpc.CallOption) (*ConsumerLimitResponse, error)
+	RestoreAvailableLimit(ctx context.Context, in *UpdateAvailableLimitRequest, opts ...grpc.CallOption) (*ConsumerLimitResponse, error)
 	GetConsumerLimitByConsumerIdAndTenor(ctx context.Context, in *ConsumerIdAndTenorRequest, opts ...grpc.CallOption) (*ConsumerLimitResponse, error)
 }
 
@@ -70,6 +72,15 @@ func (c *consumerLimitServiceClient) UpdateAvailableLimit(ctx context.Context, i
 	return out, nil
 }
 
+func (c *consumerLimitServiceClient) RestoreAvailableLimit(ctx context.Context, in *UpdateAvailableLimitRequest, opts ...grpc.CallOption) (*ConsumerLimitResponse, error) {
+	out := new(ConsumerLimitResponse)
+	err := c.cc.Invoke(ctx, ConsumerLimitService_RestoreAvailableLimit_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *consumerLimitServiceClient) GetConsumerLimitByConsumerIdAndTenor(ctx context.Context, in *ConsumerIdAndTenorRequest, opts ...grpc.CallOption) (*ConsumerLimitResponse, error) {
 	out := new(ConsumerLimitResponse)
 	err := c.cc.Invoke(ctx, ConsumerLimitService_GetConsumerLimitByConsumerIdAndTenor_FullMethodName, in, out, opts...)
@@ -86,6 +97,7 @@ type ConsumerLimitServiceServer interface {
 	GetConsumerLimitsByConsumerId(context.Context, *ConsumerRequest) (*ConsumerLimitListResponse, error)
 	CreateConsumerLimit(context.Context, *ConsumerLimit) (*ConsumerLimitResponse, error)
 	UpdateAvailableLimit(context.Context, *UpdateAvailableLimitRequest) (*ConsumerLimitResponse, error)
+	RestoreAvailableLimit(context.Context, *UpdateAvailableLimitRequest) (*ConsumerLimitResponse, error)
 	GetConsumerLimitByConsumerIdAndTenor(context.Context, *ConsumerIdAndTenorRequest) (*ConsumerLimitResponse, error)
 	mustEmbedUnimplementedConsumerLimitServiceServer()
 }
@@ -103,6 +115,9 @@ func (UnimplementedConsumerLimitServiceServer) CreateConsumerLimit(context.Conte
 func (UnimplementedConsumerLimitServiceServer) UpdateAvailableLimit(context.Context, *UpdateAvailableLimitRequest) (*ConsumerLimitResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateAvailableLimit not implemented")
 }
+func (UnimplementedConsumerLimitServiceServer) RestoreAvailableLimit(context.Context, *UpdateAvailableLimitRequest) (*ConsumerLimitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreAvailableLimit not implemented")
+}
 func (UnimplementedConsumerLimitServiceServer) GetConsumerLimitByConsumerIdAndTenor(context.Context, *ConsumerIdAndTenorRequest) (*ConsumerLimitResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetConsumerLimitByConsumerIdAndTenor not implemented")
 }
@@ -173,6 +188,24 @@ func _ConsumerLimitService_UpdateAvailableLimit_Handler(srv interface{}, ctx con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ConsumerLimitService_RestoreAvailableLimit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateAvailableLimitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConsumerLimitServiceServer).RestoreAvailableLimit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ConsumerLimitService_RestoreAvailableLimit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConsumerLimitServiceServer).RestoreAvailableLimit(ctx, req.(*UpdateAvailableLimitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ConsumerLimitService_GetConsumerLimitByConsumerIdAndTenor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ConsumerIdAndTenorRequest)
 	if err := dec(in); err != nil {
@@ -210,6 +243,10 @@ var ConsumerLimitService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateAvailableLimit",
 			Handler:    _ConsumerLimitService_UpdateAvailableLimit_Handler,
 		},
+		{
+			MethodName: "RestoreAvailableLimit",
+			Handler:    _ConsumerLimitService_RestoreAvailableLimit_Handler,
+		},
 		{
 			MethodName: "GetConsumerLimitByConsumerIdAndTenor",
 			Handler:    _ConsumerLimitService_GetConsumerLimitByConsumerIdAndTenor_Handler,
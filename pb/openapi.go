@@ -0,0 +1,9 @@
+package pb
+
+import _ "embed"
+
+// TransactionOpenAPI is the OpenAPI v2 document of the REST gateway,
+// generated from the google.api.http annotations of transaction.proto.
+//
+//go:embed transaction.swagger.json
+var TransactionOpenAPI []byte
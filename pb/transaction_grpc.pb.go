@@ -11,7 +11,6 @@ import (
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
-	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 // This is a compile-time assertion to ensure that this generated file
@@ -21,19 +20,43 @@ const _ = grpc.SupportPackageIsVersion7
 
 const (
 	TransactionService_GetAllTransactions_FullMethodName             = "/xyz_grpc.TransactionService/GetAllTransactions"
+	TransactionService_StreamTransactions_FullMethodName             = "/xyz_grpc.TransactionService/StreamTransactions"
 	TransactionService_GetTransactionsByConsumerId_FullMethodName    = "/xyz_grpc.TransactionService/GetTransactionsByConsumerId"
 	TransactionService_GetTransactionByContractNumber_FullMethodName = "/xyz_grpc.TransactionService/GetTransactionByContractNumber"
 	TransactionService_CreateTransaction_FullMethodName              = "/xyz_grpc.TransactionService/CreateTransaction"
+	TransactionService_GetInstallmentSchedule_FullMethodName         = "/xyz_grpc.TransactionService/GetInstallmentSchedule"
+	TransactionService_RecordPayment_FullMethodName                  = "/xyz_grpc.TransactionService/RecordPayment"
+	TransactionService_UpdateTransactionStatus_FullMethodName        = "/xyz_grpc.TransactionService/UpdateTransactionStatus"
+	TransactionService_GetTransactionHistory_FullMethodName          = "/xyz_grpc.TransactionService/GetTransactionHistory"
+	TransactionService_ListMyTransactions_FullMethodName             = "/xyz_grpc.TransactionService/ListMyTransactions"
+	TransactionService_GetMyTransaction_FullMethodName               = "/xyz_grpc.TransactionService/GetMyTransaction"
+	TransactionService_CreateMyTransaction_FullMethodName            = "/xyz_grpc.TransactionService/CreateMyTransaction"
+	TransactionService_CreateProduct_FullMethodName                  = "/xyz_grpc.TransactionService/CreateProduct"
+	TransactionService_GetProduct_FullMethodName                     = "/xyz_grpc.TransactionService/GetProduct"
+	TransactionService_ListProducts_FullMethodName                   = "/xyz_grpc.TransactionService/ListProducts"
+	TransactionService_RetireProduct_FullMethodName                  = "/xyz_grpc.TransactionService/RetireProduct"
 )
 
 // TransactionServiceClient is the client API for TransactionService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type TransactionServiceClient interface {
-	GetAllTransactions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*TransactionListResponse, error)
+	GetAllTransactions(ctx context.Context, in *GetAllTransactionsRequest, opts ...grpc.CallOption) (*TransactionListResponse, error)
+	StreamTransactions(ctx context.Context, in *StreamTransactionsRequest, opts ...grpc.CallOption) (TransactionService_StreamTransactionsClient, error)
 	GetTransactionsByConsumerId(ctx context.Context, in *TransactionConsumerIdRequest, opts ...grpc.CallOption) (*TransactionListResponse, error)
 	GetTransactionByContractNumber(ctx context.Context, in *TransactionContractNumberRequest, opts ...grpc.CallOption) (*TransactionResponse, error)
 	CreateTransaction(ctx context.Context, in *Transaction, opts ...grpc.CallOption) (*TransactionResponse, error)
+	GetInstallmentSchedule(ctx context.Context, in *TransactionContractNumberRequest, opts ...grpc.CallOption) (*InstallmentScheduleResponse, error)
+	RecordPayment(ctx context.Context, in *RecordPaymentRequest, opts ...grpc.CallOption) (*PaymentResponse, error)
+	UpdateTransactionStatus(ctx context.Context, in *UpdateTransactionStatusRequest, opts ...grpc.CallOption) (*TransactionResponse, error)
+	GetTransactionHistory(ctx context.Context, in *TransactionContractNumberRequest, opts ...grpc.CallOption) (*TransactionHistoryResponse, error)
+	ListMyTransactions(ctx context.Context, in *ListMyTransactionsRequest, opts ...grpc.CallOption) (*TransactionListResponse, error)
+	GetMyTransaction(ctx context.Context, in *TransactionContractNumberRequest, opts ...grpc.CallOption) (*TransactionResponse, error)
+	CreateMyTransaction(ctx context.Context, in *CreateMyTransactionRequest, opts ...grpc.CallOption) (*TransactionResponse, error)
+	CreateProduct(ctx context.Context, in *Product, opts ...grpc.CallOption) (*ProductResponse, error)
+	GetProduct(ctx context.Context, in *GetProductRequest, opts ...grpc.CallOption) (*ProductResponse, error)
+	ListProducts(ctx context.Context, in *ListProductsRequest, opts ...grpc.CallOption) (*ProductListResponse, error)
+	RetireProduct(ctx context.Context, in *RetireProductRequest, opts ...grpc.CallOption) (*ProductResponse, error)
 }
 
 type transactionServiceClient struct {
@@ -44,7 +67,7 @@ func NewTransactionServiceClient(cc grpc.ClientConnInterface) TransactionService
 	return &transactionServiceClient{cc}
 }
 
-func (c *transactionServiceClient) GetAllTransactions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*TransactionListResponse, error) {
+func (c *transactionServiceClient) GetAllTransactions(ctx context.Context, in *GetAllTransactionsRequest, opts ...grpc.CallOption) (*TransactionListResponse, error) {
 	out := new(TransactionListResponse)
 	err := c.cc.Invoke(ctx, TransactionService_GetAllTransactions_FullMethodName, in, out, opts...)
 	if err != nil {
@@ -53,6 +76,38 @@ func (c *transactionServiceClient) GetAllTransactions(ctx context.Context, in *e
 	return out, nil
 }
 
+func (c *transactionServiceClient) StreamTransactions(ctx context.Context, in *StreamTransactionsRequest, opts ...grpc.CallOption) (TransactionService_StreamTransactionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TransactionService_ServiceDesc.Streams[0], TransactionService_StreamTransactions_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &transactionServiceStreamTransactionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TransactionService_StreamTransactionsClient interface {
+	Recv() (*Transaction, error)
+	grpc.ClientStream
+}
+
+type transactionServiceStreamTransactionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *transactionServiceStreamTransactionsClient) Recv() (*Transaction, error) {
+	m := new(Transaction)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *transactionServiceClient) GetTransactionsByConsumerId(ctx context.Context, in *TransactionConsumerIdRequest, opts ...grpc.CallOption) (*TransactionListResponse, error) {
 	out := new(TransactionListResponse)
 	err := c.cc.Invoke(ctx, TransactionService_GetTransactionsByConsumerId_FullMethodName, in, out, opts...)
@@ -80,14 +135,125 @@ func (c *transactionServiceClient) CreateTransaction(ctx context.Context, in *Tr
 	return out, nil
 }
 
+func (c *transactionServiceClient) GetInstallmentSchedule(ctx context.Context, in *TransactionContractNumberRequest, opts ...grpc.CallOption) (*InstallmentScheduleResponse, error) {
+	out := new(InstallmentScheduleResponse)
+	err := c.cc.Invoke(ctx, TransactionService_GetInstallmentSchedule_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) RecordPayment(ctx context.Context, in *RecordPaymentRequest, opts ...grpc.CallOption) (*PaymentResponse, error) {
+	out := new(PaymentResponse)
+	err := c.cc.Invoke(ctx, TransactionService_RecordPayment_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) UpdateTransactionStatus(ctx context.Context, in *UpdateTransactionStatusRequest, opts ...grpc.CallOption) (*TransactionResponse, error) {
+	out := new(TransactionResponse)
+	err := c.cc.Invoke(ctx, TransactionService_UpdateTransactionStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) GetTransactionHistory(ctx context.Context, in *TransactionContractNumberRequest, opts ...grpc.CallOption) (*TransactionHistoryResponse, error) {
+	out := new(TransactionHistoryResponse)
+	err := c.cc.Invoke(ctx, TransactionService_GetTransactionHistory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) ListMyTransactions(ctx context.Context, in *ListMyTransactionsRequest, opts ...grpc.CallOption) (*TransactionListResponse, error) {
+	out := new(TransactionListResponse)
+	err := c.cc.Invoke(ctx, TransactionService_ListMyTransactions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) GetMyTransaction(ctx context.Context, in *TransactionContractNumberRequest, opts ...grpc.CallOption) (*TransactionResponse, error) {
+	out := new(TransactionResponse)
+	err := c.cc.Invoke(ctx, TransactionService_GetMyTransaction_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) CreateMyTransaction(ctx context.Context, in *CreateMyTransactionRequest, opts ...grpc.CallOption) (*TransactionResponse, error) {
+	out := new(TransactionResponse)
+	err := c.cc.Invoke(ctx, TransactionService_CreateMyTransaction_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) CreateProduct(ctx context.Context, in *Product, opts ...grpc.CallOption) (*ProductResponse, error) {
+	out := new(ProductResponse)
+	err := c.cc.Invoke(ctx, TransactionService_CreateProduct_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) GetProduct(ctx context.Context, in *GetProductRequest, opts ...grpc.CallOption) (*ProductResponse, error) {
+	out := new(ProductResponse)
+	err := c.cc.Invoke(ctx, TransactionService_GetProduct_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) ListProducts(ctx context.Context, in *ListProductsRequest, opts ...grpc.CallOption) (*ProductListResponse, error) {
+	out := new(ProductListResponse)
+	err := c.cc.Invoke(ctx, TransactionService_ListProducts_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) RetireProduct(ctx context.Context, in *RetireProductRequest, opts ...grpc.CallOption) (*ProductResponse, error) {
+	out := new(ProductResponse)
+	err := c.cc.Invoke(ctx, TransactionService_RetireProduct_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TransactionServiceServer is the server API for TransactionService service.
 // All implementations must embed UnimplementedTransactionServiceServer
 // for forward compatibility
 type TransactionServiceServer interface {
-	GetAllTransactions(context.Context, *emptypb.Empty) (*TransactionListResponse, error)
+	GetAllTransactions(context.Context, *GetAllTransactionsRequest) (*TransactionListResponse, error)
+	StreamTransactions(*StreamTransactionsRequest, TransactionService_StreamTransactionsServer) error
 	GetTransactionsByConsumerId(context.Context, *TransactionConsumerIdRequest) (*TransactionListResponse, error)
 	GetTransactionByContractNumber(context.Context, *TransactionContractNumberRequest) (*TransactionResponse, error)
 	CreateTransaction(context.Context, *Transaction) (*TransactionResponse, error)
+	GetInstallmentSchedule(context.Context, *TransactionContractNumberRequest) (*InstallmentScheduleResponse, error)
+	RecordPayment(context.Context, *RecordPaymentRequest) (*PaymentResponse, error)
+	UpdateTransactionStatus(context.Context, *UpdateTransactionStatusRequest) (*TransactionResponse, error)
+	GetTransactionHistory(context.Context, *TransactionContractNumberRequest) (*TransactionHistoryResponse, error)
+	ListMyTransactions(context.Context, *ListMyTransactionsRequest) (*TransactionListResponse, error)
+	GetMyTransaction(context.Context, *TransactionContractNumberRequest) (*TransactionResponse, error)
+	CreateMyTransaction(context.Context, *CreateMyTransactionRequest) (*TransactionResponse, error)
+	CreateProduct(context.Context, *Product) (*ProductResponse, error)
+	GetProduct(context.Context, *GetProductRequest) (*ProductResponse, error)
+	ListProducts(context.Context, *ListProductsRequest) (*ProductListResponse, error)
+	RetireProduct(context.Context, *RetireProductRequest) (*ProductResponse, error)
 	mustEmbedUnimplementedTransactionServiceServer()
 }
 
@@ -95,9 +261,12 @@ type TransactionServiceServer interface {
 type UnimplementedTransactionServiceServer struct {
 }
 
-func (UnimplementedTransactionServiceServer) GetAllTransactions(context.Context, *emptypb.Empty) (*TransactionListResponse, error) {
+func (UnimplementedTransactionServiceServer) GetAllTransactions(context.Context, *GetAllTransactionsRequest) (*TransactionListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAllTransactions not implemented")
 }
+func (UnimplementedTransactionServiceServer) StreamTransactions(*StreamTransactionsRequest, TransactionService_StreamTransactionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTransactions not implemented")
+}
 func (UnimplementedTransactionServiceServer) GetTransactionsByConsumerId(context.Context, *TransactionConsumerIdRequest) (*TransactionListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTransactionsByConsumerId not implemented")
 }
@@ -107,6 +276,39 @@ func (UnimplementedTransactionServiceServer) GetTransactionByContractNumber(cont
 func (UnimplementedTransactionServiceServer) CreateTransaction(context.Context, *Transaction) (*TransactionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateTransaction not implemented")
 }
+func (UnimplementedTransactionServiceServer) GetInstallmentSchedule(context.Context, *TransactionContractNumberRequest) (*InstallmentScheduleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInstallmentSchedule not implemented")
+}
+func (UnimplementedTransactionServiceServer) RecordPayment(context.Context, *RecordPaymentRequest) (*PaymentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecordPayment not implemented")
+}
+func (UnimplementedTransactionServiceServer) UpdateTransactionStatus(context.Context, *UpdateTransactionStatusRequest) (*TransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateTransactionStatus not implemented")
+}
+func (UnimplementedTransactionServiceServer) GetTransactionHistory(context.Context, *TransactionContractNumberRequest) (*TransactionHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransactionHistory not implemented")
+}
+func (UnimplementedTransactionServiceServer) ListMyTransactions(context.Context, *ListMyTransactionsRequest) (*TransactionListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMyTransactions not implemented")
+}
+func (UnimplementedTransactionServiceServer) GetMyTransaction(context.Context, *TransactionContractNumberRequest) (*TransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMyTransaction not implemented")
+}
+func (UnimplementedTransactionServiceServer) CreateMyTransaction(context.Context, *CreateMyTransactionRequest) (*TransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateMyTransaction not implemented")
+}
+func (UnimplementedTransactionServiceServer) CreateProduct(context.Context, *Product) (*ProductResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateProduct not implemented")
+}
+func (UnimplementedTransactionServiceServer) GetProduct(context.Context, *GetProductRequest) (*ProductResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProduct not implemented")
+}
+func (UnimplementedTransactionServiceServer) ListProducts(context.Context, *ListProductsRequest) (*ProductListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProducts not implemented")
+}
+func (UnimplementedTransactionServiceServer) RetireProduct(context.Context, *RetireProductRequest) (*ProductResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RetireProduct not implemented")
+}
 func (UnimplementedTransactionServiceServer) mustEmbedUnimplementedTransactionServiceServer() {}
 
 // UnsafeTransactionServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -121,7 +323,7 @@ func RegisterTransactionServiceServer(s grpc.ServiceRegistrar, srv TransactionSe
 }
 
 func _TransactionService_GetAllTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(emptypb.Empty)
+	in := new(GetAllTransactionsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -133,11 +335,32 @@ func _TransactionService_GetAllTransactions_Handler(srv interface{}, ctx context
 		FullMethod: TransactionService_GetAllTransactions_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TransactionServiceServer).GetAllTransactions(ctx, req.(*emptypb.Empty))
+		return srv.(TransactionServiceServer).GetAllTransactions(ctx, req.(*GetAllTransactionsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TransactionService_StreamTransactions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamTransactionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TransactionServiceServer).StreamTransactions(m, &transactionServiceStreamTransactionsServer{stream})
+}
+
+type TransactionService_StreamTransactionsServer interface {
+	Send(*Transaction) error
+	grpc.ServerStream
+}
+
+type transactionServiceStreamTransactionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *transactionServiceStreamTransactionsServer) Send(m *Transaction) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _TransactionService_GetTransactionsByConsumerId_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(TransactionConsumerIdRequest)
 	if err := dec(in); err != nil {
@@ -192,6 +415,204 @@ func _TransactionService_CreateTransaction_Handler(srv interface{}, ctx context.
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TransactionService_GetInstallmentSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransactionContractNumberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).GetInstallmentSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_GetInstallmentSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).GetInstallmentSchedule(ctx, req.(*TransactionContractNumberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_RecordPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecordPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).RecordPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_RecordPayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).RecordPayment(ctx, req.(*RecordPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_UpdateTransactionStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateTransactionStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).UpdateTransactionStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_UpdateTransactionStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).UpdateTransactionStatus(ctx, req.(*UpdateTransactionStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_GetTransactionHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransactionContractNumberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).GetTransactionHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_GetTransactionHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).GetTransactionHistory(ctx, req.(*TransactionContractNumberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_ListMyTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMyTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).ListMyTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_ListMyTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).ListMyTransactions(ctx, req.(*ListMyTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_GetMyTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransactionContractNumberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).GetMyTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_GetMyTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).GetMyTransaction(ctx, req.(*TransactionContractNumberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_CreateMyTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateMyTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).CreateMyTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_CreateMyTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).CreateMyTransaction(ctx, req.(*CreateMyTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_CreateProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Product)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).CreateProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_CreateProduct_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).CreateProduct(ctx, req.(*Product))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_GetProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).GetProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_GetProduct_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).GetProduct(ctx, req.(*GetProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_ListProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).ListProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_ListProducts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).ListProducts(ctx, req.(*ListProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_RetireProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RetireProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).RetireProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_RetireProduct_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).RetireProduct(ctx, req.(*RetireProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // TransactionService_ServiceDesc is the grpc.ServiceDesc for TransactionService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -215,7 +636,57 @@ var TransactionService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateTransaction",
 			Handler:    _TransactionService_CreateTransaction_Handler,
 		},
+		{
+			MethodName: "GetInstallmentSchedule",
+			Handler:    _TransactionService_GetInstallmentSchedule_Handler,
+		},
+		{
+			MethodName: "RecordPayment",
+			Handler:    _TransactionService_RecordPayment_Handler,
+		},
+		{
+			MethodName: "UpdateTransactionStatus",
+			Handler:    _TransactionService_UpdateTransactionStatus_Handler,
+		},
+		{
+			MethodName: "GetTransactionHistory",
+			Handler:    _TransactionService_GetTransactionHistory_Handler,
+		},
+		{
+			MethodName: "ListMyTransactions",
+			Handler:    _TransactionService_ListMyTransactions_Handler,
+		},
+		{
+			MethodName: "GetMyTransaction",
+			Handler:    _TransactionService_GetMyTransaction_Handler,
+		},
+		{
+			MethodName: "CreateMyTransaction",
+			Handler:    _TransactionService_CreateMyTransaction_Handler,
+		},
+		{
+			MethodName: "CreateProduct",
+			Handler:    _TransactionService_CreateProduct_Handler,
+		},
+		{
+			MethodName: "GetProduct",
+			Handler:    _TransactionService_GetProduct_Handler,
+		},
+		{
+			MethodName: "ListProducts",
+			Handler:    _TransactionService_ListProducts_Handler,
+		},
+		{
+			MethodName: "RetireProduct",
+			Handler:    _TransactionService_RetireProduct_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamTransactions",
+			Handler:       _TransactionService_StreamTransactions_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "transaction.proto",
 }
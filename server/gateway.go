@@ -0,0 +1,173 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/textproto"
+	"time"
+
+	commonErr "xyz-transaction-service/common/error"
+	"xyz-transaction-service/common/utils"
+	"xyz-transaction-service/common/validation"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	gatewayReadHeaderTimeout = 5 * time.Second
+	openAPIPath              = "/v1/openapi.json"
+)
+
+// GatewayRegisterFunc registers the HTTP bindings of a service, e.g.
+// pb.RegisterTransactionServiceHandler.
+type GatewayRegisterFunc func(ctx context.Context, mux *runtime.ServeMux, conn *grpc.ClientConn) error
+
+// Gateway serves the REST/JSON API by forwarding every request to the gRPC
+// server through conn, so the gRPC interceptors, auth included, still apply.
+type Gateway struct {
+	mux      *runtime.ServeMux
+	server   *http.Server
+	listener net.Listener
+	conn     *grpc.ClientConn
+	Port     string
+}
+
+func NewGateway(port string, conn *grpc.ClientConn, openAPI []byte) *Gateway {
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(gatewayHeaderMatcher),
+		runtime.WithForwardResponseOption(gatewayResponseStatus),
+		runtime.WithErrorHandler(gatewayErrorHandler),
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions:   protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true},
+			UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+		}),
+	)
+
+	handler := http.NewServeMux()
+	handler.Handle("/", mux)
+	handler.HandleFunc(openAPIPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(openAPI)
+	})
+
+	return &Gateway{
+		mux: mux,
+		server: &http.Server{
+			Handler:           handler,
+			ReadHeaderTimeout: gatewayReadHeaderTimeout,
+		},
+		conn: conn,
+		Port: port,
+	}
+}
+
+func (g *Gateway) Register(ctx context.Context, register ...GatewayRegisterFunc) error {
+	for _, fn := range register {
+		if err := fn(ctx, g.mux, g.conn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Handler is the root HTTP handler, exposed for tests.
+func (g *Gateway) Handler() http.Handler {
+	return g.server.Handler
+}
+
+func (g *Gateway) Run() error {
+	var err error
+	g.listener, err = net.Listen(connProtocol, fmt.Sprintf(":%s", g.Port))
+	if err != nil {
+		return fmt.Errorf("ERROR: Failed to listen on port %s: %v", g.Port, err)
+	}
+
+	go g.serve()
+	log.Printf("http gateway is running on port %s\n", g.Port)
+	return nil
+}
+
+func (g *Gateway) serve() {
+	if err := g.server.Serve(g.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Println("ERROR: [Gateway - serve] http gateway stopped serving:", err)
+	}
+}
+
+func (g *Gateway) Shutdown(ctx context.Context) error {
+	return g.server.Shutdown(ctx)
+}
+
+// gatewayHeaderMatcher forwards the headers the gRPC interceptors and
+// handlers read under their plain metadata keys.
+func gatewayHeaderMatcher(key string) (string, bool) {
+	switch textproto.CanonicalMIMEHeaderKey(key) {
+	case "Authorization":
+		return "authorization", true
+	case "Idempotency-Key":
+		return "idempotency-key", true
+	case textproto.CanonicalMIMEHeaderKey(utils.RequestIdHeader):
+		return utils.RequestIdHeader, true
+	}
+
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+type codedResponse interface {
+	GetCode() uint32
+}
+
+// gatewayResponseStatus answers with the HTTP status the handler put in the
+// Code field of the response.
+func gatewayResponseStatus(ctx context.Context, w http.ResponseWriter, res proto.Message) error {
+	if coded, ok := res.(codedResponse); ok && coded.GetCode() != 0 && coded.GetCode() != http.StatusOK {
+		w.WriteHeader(int(coded.GetCode()))
+	}
+
+	return nil
+}
+
+// gatewayErrorHandler renders gRPC errors in the same code and message shape
+// as the responses, since a failed call carries no response body. The field
+// violations of an InvalidArgument error are listed next to the message.
+func gatewayErrorHandler(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	st := status.Convert(err)
+	httpStatus := commonErr.HttpStatusFromCode(st.Code())
+
+	var httpErr *runtime.HTTPStatusError
+	if errors.As(err, &httpErr) {
+		// routing errors such as an unknown path or method
+		httpStatus = httpErr.HTTPStatus
+		st = status.Convert(httpErr.Err)
+	}
+
+	type fieldViolation struct {
+		Field       string `json:"field"`
+		Description string `json:"description"`
+	}
+	var violations []fieldViolation
+	for _, violation := range validation.FieldViolations(st.Err()) {
+		violations = append(violations, fieldViolation{Field: violation.GetField(), Description: violation.GetDescription()})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	_ = json.NewEncoder(w).Encode(struct {
+		Code            uint32           `json:"code"`
+		Message         string           `json:"message"`
+		FieldViolations []fieldViolation `json:"field_violations,omitempty"`
+	}{
+		Code:            uint32(httpStatus),
+		Message:         st.Message(),
+		FieldViolations: violations,
+	})
+}
@@ -0,0 +1,127 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"xyz-transaction-service/common/validation"
+	"xyz-transaction-service/pb"
+	"xyz-transaction-service/server"
+)
+
+type gatewayTestService struct {
+	pb.UnimplementedTransactionServiceServer
+}
+
+func (s *gatewayTestService) GetTransactionByContractNumber(ctx context.Context, req *pb.TransactionContractNumberRequest) (*pb.TransactionResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if len(md.Get("authorization")) == 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "authorization token is not provided")
+	}
+	if req.ContractNumber != "KTR-1" {
+		return nil, status.Errorf(codes.NotFound, "Transaction not found")
+	}
+
+	return &pb.TransactionResponse{
+		Code:    http.StatusOK,
+		Message: "Success get transaction",
+		Data:    &pb.Transaction{ContractNumber: req.ContractNumber},
+	}, nil
+}
+
+func (s *gatewayTestService) CreateTransaction(ctx context.Context, req *pb.Transaction) (*pb.TransactionResponse, error) {
+	if req.Otr == 0 {
+		v := validation.NewViolations()
+		v.Add("otr", "must be greater than zero")
+		return nil, v.Err()
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	return &pb.TransactionResponse{
+		Code:    http.StatusCreated,
+		Message: strings.Join(md.Get("idempotency-key"), ","),
+		Data:    req,
+	}, nil
+}
+
+func newTestGateway(t *testing.T) http.Handler {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	pb.RegisterTransactionServiceServer(grpcServer, &gatewayTestService{})
+	go func() { _ = grpcServer.Serve(listener) }()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	gateway := server.NewGateway("0", conn, pb.TransactionOpenAPI)
+	assert.NoError(t, gateway.Register(context.Background(), pb.RegisterTransactionServiceHandler))
+	return gateway.Handler()
+}
+
+func TestGateway(t *testing.T) {
+	handler := newTestGateway(t)
+
+	do := func(method, path, body string, header http.Header) (int, map[string]any) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		var res map[string]any
+		_ = json.Unmarshal(rec.Body.Bytes(), &res)
+		return rec.Code, res
+	}
+
+	t.Run("forwards the authorization header", func(t *testing.T) {
+		code, res := do(http.MethodGet, "/v1/transactions/KTR-1", "", http.Header{"Authorization": {"Bearer token"}})
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "KTR-1", res["data"].(map[string]any)["contract_number"])
+
+		code, res = do(http.MethodGet, "/v1/transactions/KTR-1", "", nil)
+		assert.Equal(t, http.StatusUnauthorized, code)
+		assert.Equal(t, float64(http.StatusUnauthorized), res["code"])
+	})
+
+	t.Run("maps errors to the response code", func(t *testing.T) {
+		code, res := do(http.MethodGet, "/v1/transactions/KTR-2", "", http.Header{"Authorization": {"Bearer token"}})
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.Equal(t, "Transaction not found", res["message"])
+	})
+
+	t.Run("uses the status of the code field", func(t *testing.T) {
+		code, res := do(http.MethodPost, "/v1/transactions", `{"consumer_id": "7", "tenor": 6, "otr": "1000000"}`, http.Header{"Idempotency-Key": {"abc"}})
+		assert.Equal(t, http.StatusCreated, code)
+		assert.Equal(t, "abc", res["message"])
+	})
+
+	t.Run("lists field violations", func(t *testing.T) {
+		code, res := do(http.MethodPost, "/v1/transactions", `{"consumer_id": "7", "tenor": 6}`, nil)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, []any{map[string]any{"field": "otr", "description": "must be greater than zero"}}, res["field_violations"])
+	})
+
+	t.Run("serves the OpenAPI document", func(t *testing.T) {
+		code, res := do(http.MethodGet, "/v1/openapi.json", "", nil)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Contains(t, res["paths"], "/v1/transactions")
+	})
+}
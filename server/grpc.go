@@ -1,20 +1,27 @@
 package server
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"net"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
-	roles "xyz-transaction-service/common/authorization"
+	"xyz-transaction-service/common/authorization"
 	commonJwt "xyz-transaction-service/common/jwt"
 	"xyz-transaction-service/server/interceptor"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionalphapb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
 	"google.golang.org/grpc/status"
 )
 
@@ -27,8 +34,18 @@ const (
 
 type Grpc struct {
 	Server   *grpc.Server
+	Health   *HealthChecker
 	listener net.Listener
 	Port     string
+	// DrainDelay is how long AwaitTermination reports NOT_SERVING before it
+	// stops accepting calls, giving load balancers time to notice.
+	DrainDelay time.Duration
+}
+
+// ReflectionMethods are served once EnableReflection is called.
+var ReflectionMethods = []string{
+	reflectionpb.ServerReflection_ServerReflectionInfo_FullMethodName,
+	reflectionalphapb.ServerReflection_ServerReflectionInfo_FullMethodName,
 }
 
 func NewGrpc(port string, options ...grpc.ServerOption) *Grpc {
@@ -37,19 +54,48 @@ func NewGrpc(port string, options ...grpc.ServerOption) *Grpc {
 
 	server := grpc.NewServer(options...)
 
+	healthChecker := NewHealthChecker()
+	healthpb.RegisterHealthServer(server, healthChecker.server)
+
 	return &Grpc{
 		Server: server,
+		Health: healthChecker,
 		Port:   port,
 	}
 }
 
-func NewGrpcServer(port string, jwtManager *commonJwt.JWT) *Grpc {
-	authInterceptor := interceptor.NewAuthInterceptor(jwtManager, roles.GetAccessibleRoles())
+// EnableReflection registers the server reflection service, letting tools
+// such as grpcurl list and describe the served methods.
+func (g *Grpc) EnableReflection() {
+	reflection.Register(g.Server)
+}
+
+// NewGrpcServer chains the interceptors outermost first: request id, metrics,
+// access log, panic recovery and auth, so even failed and panicking calls are
+// counted and logged with their request id.
+func NewGrpcServer(port string, jwtManager *commonJwt.JWT, policy *authorization.Policy, extra ...grpc.ServerOption) *Grpc {
+	authInterceptor := interceptor.NewAuthInterceptor(jwtManager, policy)
+	loggingInterceptor := interceptor.NewLoggingInterceptor(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
 	options := []grpc.ServerOption{
-		grpc.UnaryInterceptor(authInterceptor.Unary()),
+		// starts the server span, continuing the trace of the caller
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			interceptor.RequestIdUnary(),
+			interceptor.MetricsUnary(),
+			loggingInterceptor.Unary(),
+			interceptor.RecoveryUnary(),
+			authInterceptor.Unary(),
+		),
+		grpc.ChainStreamInterceptor(
+			interceptor.RequestIdStream(),
+			interceptor.MetricsStream(),
+			loggingInterceptor.Stream(),
+			interceptor.RecoveryStream(),
+			authInterceptor.Stream(),
+		),
 	}
 
-	server := NewGrpc(port, options...)
+	server := NewGrpc(port, append(options, extra...)...)
 	return server
 }
 
@@ -66,8 +112,8 @@ func (g *Grpc) Run() error {
 }
 
 func (g *Grpc) serve() {
-	if err := g.Server.Serve(g.listener); err != nil {
-		panic(err)
+	if err := g.Server.Serve(g.listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		log.Println("ERROR: [Grpc - serve] grpc server stopped serving:", err)
 	}
 }
 
@@ -76,6 +122,12 @@ func (g *Grpc) AwaitTermination() error {
 	signal.Notify(sign, syscall.SIGINT, syscall.SIGTERM)
 	<-sign
 
+	g.Health.Shutdown()
+	if g.DrainDelay > 0 {
+		log.Printf("grpc server is draining for %s\n", g.DrainDelay)
+		time.Sleep(g.DrainDelay)
+	}
+
 	g.Server.GracefulStop()
 	return g.listener.Close()
 }
@@ -3,15 +3,23 @@ package server
 import (
 	"fmt"
 
+	"xyz-transaction-service/server/interceptor"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
 type DialOption func(name string) (grpc.DialOption, error)
 
+// Dial connects without TLS unless an option such as WithTLS replaces the
+// transport credentials; options are applied in order, the last one wins.
 func Dial(name string, opts ...DialOption) (*grpc.ClientConn, error) {
 	dialOpts := []grpc.DialOption{
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(interceptor.RequestIdUnaryClient()),
+		// propagates the trace context to the called service
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
 	}
 
 	for _, fn := range opts {
@@ -30,11 +38,15 @@ func Dial(name string, opts ...DialOption) (*grpc.ClientConn, error) {
 	return conn, nil
 }
 
-func InitGRPCConn(addr string, ssl bool, cert string) *grpc.ClientConn {
-	// if ssl true, dial with ssl
+// InitGRPCConn dials addr, over TLS when ssl is set, trusting the CA bundle
+// in cert or the system pool when cert is empty. opts are applied after it,
+// e.g. WithTLS with a client certificate for mTLS.
+func InitGRPCConn(addr string, ssl bool, cert string, opts ...DialOption) *grpc.ClientConn {
+	if ssl {
+		opts = append([]DialOption{WithTLS(cert, "", "")}, opts...)
+	}
 
-	// else
-	conn, err := Dial(addr)
+	conn, err := Dial(addr, opts...)
 	if err != nil {
 		panic(fmt.Sprintf("ERROR: dial error: %v", err))
 	}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultHealthCheckTimeout  = 2 * time.Second
+)
+
+// HealthCheck reports whether a dependency is usable.
+type HealthCheck func(ctx context.Context) error
+
+// HealthChecker backs the grpc.health.v1 service. Every check is published
+// under its own service name, e.g. "mysql", and the overall "" service is
+// SERVING only while every check passes.
+type HealthChecker struct {
+	server *health.Server
+
+	mu     sync.Mutex
+	checks map[string]HealthCheck
+}
+
+func NewHealthChecker() *HealthChecker {
+	server := health.NewServer()
+	// not ready until the first round of checks passed
+	server.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	return &HealthChecker{
+		server: server,
+		checks: make(map[string]HealthCheck),
+	}
+}
+
+func (h *HealthChecker) AddCheck(service string, check HealthCheck) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.checks[service] = check
+	h.server.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+// Run checks the dependencies every interval until ctx is done.
+func (h *HealthChecker) Run(ctx context.Context, interval, timeout time.Duration) {
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		h.CheckOnce(ctx, timeout)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckOnce runs every check and publishes the result.
+func (h *HealthChecker) CheckOnce(ctx context.Context, timeout time.Duration) {
+	h.mu.Lock()
+	services := make([]string, 0, len(h.checks))
+	for service := range h.checks {
+		services = append(services, service)
+	}
+	h.mu.Unlock()
+	sort.Strings(services)
+
+	overall := healthpb.HealthCheckResponse_SERVING
+	for _, service := range services {
+		h.mu.Lock()
+		check := h.checks[service]
+		h.mu.Unlock()
+
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := check(checkCtx)
+		cancel()
+
+		status := healthpb.HealthCheckResponse_SERVING
+		if err != nil {
+			log.Printf("WARNING: [HealthChecker - CheckOnce] Dependency %s is not serving: %v\n", service, err)
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+			overall = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		h.server.SetServingStatus(service, status)
+	}
+
+	h.server.SetServingStatus("", overall)
+}
+
+// Shutdown flips every service to NOT_SERVING for good, so load balancers
+// stop routing new calls while in-flight ones drain.
+func (h *HealthChecker) Shutdown() {
+	h.server.Shutdown()
+}
@@ -0,0 +1,60 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+
+	"xyz-transaction-service/server"
+)
+
+func TestHealthChecker(t *testing.T) {
+	srv := server.NewGrpc("0")
+	listener := bufconn.Listen(1024 * 1024)
+	go func() { _ = srv.Server.Serve(listener) }()
+	defer srv.Server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	check := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		res, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		assert.NoError(t, err)
+		return res.GetStatus()
+	}
+
+	// not ready before the first round of checks
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(""))
+
+	mysqlErr := errors.New("connection refused")
+	srv.Health.AddCheck("mysql", func(ctx context.Context) error { return mysqlErr })
+	srv.Health.AddCheck("consumer_limit", func(ctx context.Context) error { return nil })
+
+	srv.Health.CheckOnce(context.Background(), time.Second)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check("mysql"))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check("consumer_limit"))
+
+	mysqlErr = nil
+	srv.Health.CheckOnce(context.Background(), time.Second)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(""))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check("mysql"))
+
+	// draining wins over later checks
+	srv.Health.Shutdown()
+	srv.Health.CheckOnce(context.Background(), time.Second)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check("mysql"))
+}
@@ -5,54 +5,95 @@ import (
 	"log"
 	"strings"
 
+	"xyz-transaction-service/common/authorization"
 	commonJwt "xyz-transaction-service/common/jwt"
 	"xyz-transaction-service/common/utils"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
 type AuthInterceptor struct {
-	jwtManager      *commonJwt.JWT
-	accessibleRoles map[string][]uint32
+	jwtManager *commonJwt.JWT
+	policy     *authorization.Policy
 }
 
-func NewAuthInterceptor(jwtManager *commonJwt.JWT, accessibleRoles map[string][]uint32) *AuthInterceptor {
+func NewAuthInterceptor(jwtManager *commonJwt.JWT, policy *authorization.Policy) *AuthInterceptor {
 	return &AuthInterceptor{
-		jwtManager:      jwtManager,
-		accessibleRoles: accessibleRoles,
+		jwtManager: jwtManager,
+		policy:     policy,
 	}
 }
 
 func (a *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
-		log.Println("INFO [Auth Interceptor - Unary Server Interceptor] Method:", info.FullMethod)
-
-		if err := a.authorize(ctx, info.FullMethod); err != nil {
+		claims, err := a.authorize(ctx, info.FullMethod)
+		if err != nil {
 			return nil, err
 		}
+		if claims != nil {
+			ctx = commonJwt.NewContext(ctx, claims)
+			setAccessLogConsumerId(ctx, claims.ConsumerId)
+		}
 
 		return handler(ctx, req)
 	}
 }
 
-func (a *AuthInterceptor) authorize(ctx context.Context, method string) error {
-	accessibleRoles, ok := a.accessibleRoles[method]
-	if !ok {
-		return nil
+func (a *AuthInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		claims, err := a.authorize(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		if claims != nil {
+			stream = &contextServerStream{ServerStream: stream, ctx: commonJwt.NewContext(stream.Context(), claims)}
+			setAccessLogConsumerId(stream.Context(), claims.ConsumerId)
+		}
+
+		return handler(srv, stream)
+	}
+}
+
+// authorize checks the caller against the policy of the method and returns
+// its claims. Anonymous methods need no token, but a valid one sent to them is
+// still returned so the caller can be audited. Every other method requires a
+// token whose role the policy allows; methods missing from the policy are denied.
+func (a *AuthInterceptor) authorize(ctx context.Context, method string) (*commonJwt.CustomClaims, error) {
+	if a.policy.IsAnonymous(method) {
+		if md, _ := metadata.FromIncomingContext(ctx); len(md.Get("authorization")) == 0 {
+			return nil, nil
+		}
+		claims, _ := a.verify(ctx)
+		return claims, nil
 	}
 
+	claims, err := a.verify(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !a.policy.Allows(method, claims.Role) {
+		log.Printf("ERROR: [Auth Interceptor - Authorize] Role %d has no permission to access %s\n", claims.Role, method)
+		return nil, status.Errorf(codes.PermissionDenied, "no permission to access this RPC")
+	}
+
+	return claims, nil
+}
+
+func (a *AuthInterceptor) verify(ctx context.Context) (*commonJwt.CustomClaims, error) {
 	authHeader, err := utils.GetMetadataAuthorization(ctx)
 	if err != nil {
 		log.Println("ERROR: [Auth Interceptor - Authorize] Error while getting metadata authorization:", err)
-		return status.Errorf(codes.Unauthenticated, "error while get metadata authorization: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "error while get metadata authorization: %v", err)
 	}
 
 	parts := strings.Fields(authHeader)
 	if len(parts) != 2 || parts[0] != "Bearer" {
 		log.Println("ERROR: [Auth Interceptor - Authorize] Authorization token in wrong format")
-		return status.Errorf(codes.Unauthenticated, "authorization token is invalid")
+		return nil, status.Errorf(codes.Unauthenticated, "authorization token is invalid")
 	}
 
 	accessToken := parts[1]
@@ -60,15 +101,8 @@ func (a *AuthInterceptor) authorize(ctx context.Context, method string) error {
 	claims, err := a.jwtManager.Verify(accessToken)
 	if err != nil {
 		log.Println("ERROR: [Auth Interceptor - Authorize] Access token is invalid:", err)
-		return status.Errorf(codes.Unauthenticated, "access token is invalid: %v", err)
-	}
-
-	for _, role := range accessibleRoles {
-		if role == claims.Role {
-			return nil
-		}
+		return nil, status.Errorf(codes.Unauthenticated, "access token is invalid: %v", err)
 	}
 
-	log.Println("ERROR: [Auth Interceptor - Authorize] No permission to access this RPC")
-	return status.Errorf(codes.PermissionDenied, "no permission to access this RPC")
+	return claims, nil
 }
@@ -0,0 +1,82 @@
+package interceptor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"xyz-transaction-service/common/authorization"
+	commonJwt "xyz-transaction-service/common/jwt"
+	"xyz-transaction-service/server/interceptor"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	myTransactionsMethod  = "/xyz_grpc.TransactionService/ListMyTransactions"
+	allTransactionsMethod = "/xyz_grpc.TransactionService/GetAllTransactions"
+)
+
+func newAuthInterceptor(t *testing.T) (*interceptor.AuthInterceptor, *commonJwt.JWT) {
+	policy, err := authorization.ParsePolicy([]byte(`
+roles:
+  staff:
+    id: 2
+  consumer:
+    id: 3
+methods:
+  /xyz_grpc.TransactionService/ListMyTransactions:
+    roles: [consumer]
+  /xyz_grpc.TransactionService/GetAllTransactions:
+    roles: [staff]
+`), ".yaml")
+	assert.NoError(t, err)
+
+	jwtManager := commonJwt.NewJWT("secret", time.Minute)
+	return interceptor.NewAuthInterceptor(jwtManager, policy), jwtManager
+}
+
+func withToken(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestUnaryPutsClaimsIntoContext(t *testing.T) {
+	auth, jwtManager := newAuthInterceptor(t)
+	token, err := jwtManager.GenerateToken("budi", 3, 7)
+	assert.NoError(t, err)
+
+	var claims *commonJwt.CustomClaims
+	handler := func(ctx context.Context, req any) (any, error) {
+		claims, _ = commonJwt.FromContext(ctx)
+		return nil, nil
+	}
+
+	_, err = auth.Unary()(withToken(token), nil, &grpc.UnaryServerInfo{FullMethod: myTransactionsMethod}, handler)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "budi", claims.Cred)
+	assert.Equal(t, uint64(7), claims.ConsumerId)
+}
+
+func TestUnaryDeniesRoleOutsidePolicy(t *testing.T) {
+	auth, jwtManager := newAuthInterceptor(t)
+	token, err := jwtManager.GenerateToken("budi", 3, 7)
+	assert.NoError(t, err)
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		t.Fatal("handler must not run")
+		return nil, nil
+	}
+
+	_, err = auth.Unary()(withToken(token), nil, &grpc.UnaryServerInfo{FullMethod: allTransactionsMethod}, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = auth.Unary()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: allTransactionsMethod}, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = auth.Unary()(withToken(token), nil, &grpc.UnaryServerInfo{FullMethod: "/xyz_grpc.TransactionService/Unknown"}, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
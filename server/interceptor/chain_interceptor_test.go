@@ -0,0 +1,68 @@
+package interceptor_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"xyz-transaction-service/common/utils"
+	"xyz-transaction-service/server/interceptor"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var testInfo = &grpc.UnaryServerInfo{FullMethod: "/xyz_grpc.TransactionService/GetMyTransaction"}
+
+func TestRecoveryUnaryConvertsPanic(t *testing.T) {
+	handler := func(ctx context.Context, req any) (any, error) {
+		panic("boom")
+	}
+
+	_, err := interceptor.RecoveryUnary()(context.Background(), nil, testInfo, handler)
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestRequestIdUnary(t *testing.T) {
+	var requestId string
+	handler := func(ctx context.Context, req any) (any, error) {
+		requestId = utils.GetMetadataRequestId(ctx)
+		return nil, nil
+	}
+
+	// a missing id is generated
+	_, err := interceptor.RequestIdUnary()(context.Background(), nil, testInfo, handler)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, requestId)
+
+	// an id sent by the caller is kept
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(utils.RequestIdHeader, "req-1"))
+	_, err = interceptor.RequestIdUnary()(ctx, nil, testInfo, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "req-1", requestId)
+}
+
+func TestLoggingUnaryWritesAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	logging := interceptor.NewLoggingInterceptor(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return nil, status.Error(codes.NotFound, "Transaction not found")
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(utils.RequestIdHeader, "req-1"))
+	_, err := logging.Unary()(ctx, nil, testInfo, handler)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	var line map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, testInfo.FullMethod, line["method"])
+	assert.Equal(t, "NotFound", line["code"])
+	assert.Equal(t, "req-1", line["request_id"])
+	assert.Contains(t, line, "latency_ms")
+}
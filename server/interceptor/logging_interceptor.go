@@ -0,0 +1,94 @@
+package interceptor
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"xyz-transaction-service/common/tracing"
+	"xyz-transaction-service/common/utils"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type accessLogKey struct{}
+
+// accessLog collects fields known only by inner interceptors, such as the
+// consumer of the caller token, for the access log line.
+type accessLog struct {
+	consumerId uint64
+}
+
+type LoggingInterceptor struct {
+	logger *slog.Logger
+}
+
+func NewLoggingInterceptor(logger *slog.Logger) *LoggingInterceptor {
+	return &LoggingInterceptor{
+		logger: logger,
+	}
+}
+
+// Unary writes one structured access log line per call.
+func (l *LoggingInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		entry := &accessLog{}
+
+		res, err := handler(context.WithValue(ctx, accessLogKey{}, entry), req)
+
+		l.log(ctx, "unary", info.FullMethod, start, entry, err)
+		return res, err
+	}
+}
+
+func (l *LoggingInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		entry := &accessLog{}
+
+		ctx := stream.Context()
+		err := handler(srv, &contextServerStream{ServerStream: stream, ctx: context.WithValue(ctx, accessLogKey{}, entry)})
+
+		l.log(ctx, "stream", info.FullMethod, start, entry, err)
+		return err
+	}
+}
+
+func (l *LoggingInterceptor) log(ctx context.Context, kind, method string, start time.Time, entry *accessLog, err error) {
+	code := status.Code(err)
+
+	attrs := []slog.Attr{
+		slog.String("kind", kind),
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+		slog.String("request_id", utils.GetMetadataRequestId(ctx)),
+	}
+	if traceId := tracing.TraceId(ctx); traceId != "" {
+		attrs = append(attrs, slog.String("trace_id", traceId))
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		attrs = append(attrs, slog.String("peer", p.Addr.String()))
+	}
+	if entry.consumerId != 0 {
+		attrs = append(attrs, slog.Uint64("consumer_id", entry.consumerId))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+	}
+
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelWarn
+	}
+	l.logger.LogAttrs(ctx, level, "grpc access", attrs...)
+}
+
+// setAccessLogConsumerId records the consumer of the caller for the access log.
+func setAccessLogConsumerId(ctx context.Context, consumerId uint64) {
+	if entry, ok := ctx.Value(accessLogKey{}).(*accessLog); ok {
+		entry.consumerId = consumerId
+	}
+}
@@ -0,0 +1,30 @@
+package interceptor
+
+import (
+	"context"
+	"time"
+	"xyz-transaction-service/common/metrics"
+
+	"google.golang.org/grpc"
+)
+
+// MetricsUnary counts calls and records their latency per method and code.
+func MetricsUnary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		res, err := handler(ctx, req)
+
+		metrics.ObserveRPC("unary", info.FullMethod, start, err)
+		return res, err
+	}
+}
+
+func MetricsStream() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, stream)
+
+		metrics.ObserveRPC("stream", info.FullMethod, start, err)
+		return err
+	}
+}
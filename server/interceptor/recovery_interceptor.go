@@ -0,0 +1,42 @@
+package interceptor
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RecoveryUnary turns a panicking handler into an Internal error, so one bad
+// request cannot take the whole server down.
+func RecoveryUnary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(info.FullMethod, r)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+func RecoveryStream() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(info.FullMethod, r)
+			}
+		}()
+
+		return handler(srv, stream)
+	}
+}
+
+func recovered(method string, r any) error {
+	log.Printf("ERROR: [Recovery Interceptor - Recover] Panic in %s: %v\n%s", method, r, debug.Stack())
+	return status.Errorf(codes.Internal, "internal server error")
+}
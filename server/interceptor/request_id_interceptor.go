@@ -0,0 +1,56 @@
+package interceptor
+
+import (
+	"context"
+	"xyz-transaction-service/common/utils"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIdUnary makes sure every request has an x-request-id: the one sent by
+// the caller is kept, otherwise a new one is generated. The id is written back
+// into the incoming metadata for the handlers and returned as a response header.
+func RequestIdUnary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, requestId := withRequestId(ctx)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(utils.RequestIdHeader, requestId))
+
+		return handler(ctx, req)
+	}
+}
+
+func RequestIdStream() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, requestId := withRequestId(stream.Context())
+		_ = stream.SetHeader(metadata.Pairs(utils.RequestIdHeader, requestId))
+
+		return handler(srv, &contextServerStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// RequestIdUnaryClient forwards the request id of the incoming call to
+// outbound calls, so logs of downstream services can be correlated.
+func RequestIdUnaryClient() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if requestId := utils.GetMetadataRequestId(ctx); requestId != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, utils.RequestIdHeader, requestId)
+		}
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func withRequestId(ctx context.Context) (context.Context, string) {
+	if requestId := utils.GetMetadataRequestId(ctx); requestId != "" {
+		return ctx, requestId
+	}
+
+	requestId := uuid.NewString()
+	md, _ := metadata.FromIncomingContext(ctx)
+	md = md.Copy()
+	md.Set(utils.RequestIdHeader, requestId)
+
+	return metadata.NewIncomingContext(ctx, md), requestId
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+	"xyz-transaction-service/common/metrics"
+)
+
+const metricsReadHeaderTimeout = 5 * time.Second
+
+// Metrics serves the Prometheus /metrics endpoint on its own HTTP port next
+// to the gRPC one.
+type Metrics struct {
+	server   *http.Server
+	listener net.Listener
+	Port     string
+}
+
+func NewMetrics(port string) *Metrics {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+
+	return &Metrics{
+		server: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: metricsReadHeaderTimeout,
+		},
+		Port: port,
+	}
+}
+
+func (m *Metrics) Run() error {
+	var err error
+	m.listener, err = net.Listen(connProtocol, fmt.Sprintf(":%s", m.Port))
+	if err != nil {
+		return fmt.Errorf("ERROR: Failed to listen on port %s: %v", m.Port, err)
+	}
+
+	go m.serve()
+	log.Printf("metrics server is running on port %s\n", m.Port)
+	return nil
+}
+
+func (m *Metrics) serve() {
+	if err := m.server.Serve(m.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Println("ERROR: [Metrics - serve] metrics server stopped serving:", err)
+	}
+}
+
+func (m *Metrics) Shutdown(ctx context.Context) error {
+	return m.server.Shutdown(ctx)
+}
@@ -0,0 +1,296 @@
+package server
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// reloadCheckInterval bounds how often the files are stat'ed, since the
+// certificates are looked up on every handshake.
+const reloadCheckInterval = time.Second
+
+// watchedFiles tells whether a set of files changed since they were loaded.
+type watchedFiles struct {
+	paths     []string
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+func (w *watchedFiles) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, path := range w.paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// changed reports whether a reload is due and the modification time to
+// remember once it succeeded.
+func (w *watchedFiles) changed(now time.Time) (bool, time.Time) {
+	if now.Sub(w.checkedAt) < reloadCheckInterval {
+		return false, w.modTime
+	}
+	w.checkedAt = now
+
+	modTime, err := w.latestModTime()
+	if err != nil {
+		log.Println("ERROR: [TLS - reload] Error while stat certificate files:", err)
+		return false, w.modTime
+	}
+	return !modTime.Equal(w.modTime), modTime
+}
+
+// CertReloader serves a certificate and key pair from disk and reloads it
+// when the files change, so a rotated certificate is used by the next
+// handshake without a restart. A pair that fails to load keeps the previous
+// one in use.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+
+	mu    sync.Mutex
+	files watchedFiles
+	cert  *tls.Certificate
+}
+
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		files:    watchedFiles{paths: []string{certFile, keyFile}},
+	}
+
+	modTime, err := r.files.latestModTime()
+	if err != nil {
+		return nil, fmt.Errorf("load certificate: %w", err)
+	}
+	if err := r.load(modTime); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *CertReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load certificate %s: %w", r.certFile, err)
+	}
+	if cert.Leaf == nil {
+		cert.Leaf, _ = x509.ParseCertificate(cert.Certificate[0])
+	}
+
+	r.cert = &cert
+	r.files.modTime = modTime
+	return nil
+}
+
+// Certificate returns the current pair, reloading it first if it changed.
+func (r *CertReloader) Certificate() *tls.Certificate {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if changed, modTime := r.files.changed(time.Now()); changed {
+		if err := r.load(modTime); err != nil {
+			log.Println("ERROR: [CertReloader - Certificate] Keeping the previous certificate:", err)
+		} else {
+			log.Println("INFO: [CertReloader - Certificate] Reloaded certificate:", r.certFile)
+		}
+	}
+
+	return r.cert
+}
+
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.Certificate(), nil
+}
+
+func (r *CertReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.Certificate(), nil
+}
+
+// CertPoolReloader serves a CA bundle from disk and reloads it when the file
+// changes.
+type CertPoolReloader struct {
+	caFile string
+
+	mu    sync.Mutex
+	files watchedFiles
+	pool  *x509.CertPool
+}
+
+func NewCertPoolReloader(caFile string) (*CertPoolReloader, error) {
+	r := &CertPoolReloader{
+		caFile: caFile,
+		files:  watchedFiles{paths: []string{caFile}},
+	}
+
+	modTime, err := r.files.latestModTime()
+	if err != nil {
+		return nil, fmt.Errorf("load CA bundle: %w", err)
+	}
+	if err := r.load(modTime); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *CertPoolReloader) load(modTime time.Time) error {
+	pool, err := loadCertPool(r.caFile)
+	if err != nil {
+		return err
+	}
+
+	r.pool = pool
+	r.files.modTime = modTime
+	return nil
+}
+
+func (r *CertPoolReloader) CertPool() *x509.CertPool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if changed, modTime := r.files.changed(time.Now()); changed {
+		if err := r.load(modTime); err != nil {
+			log.Println("ERROR: [CertPoolReloader - CertPool] Keeping the previous CA bundle:", err)
+		} else {
+			log.Println("INFO: [CertPoolReloader - CertPool] Reloaded CA bundle:", r.caFile)
+		}
+	}
+
+	return r.pool
+}
+
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("load CA bundle: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("load CA bundle %s: no certificate found", caFile)
+	}
+	return pool, nil
+}
+
+// NewServerTLSConfig serves the certificates of certs. With a client CA
+// bundle every client must present a certificate signed by it (mTLS).
+func NewServerTLSConfig(certs *CertReloader, clientCAFile string) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: certs.GetCertificate,
+		// set here as well as by grpc, the per-handshake clone below is
+		// taken from this config and gRPC requires ALPN h2
+		NextProtos: []string{"h2"},
+	}
+	if clientCAFile == "" {
+		return config, nil
+	}
+
+	clientCAs, err := NewCertPoolReloader(clientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	config.ClientAuth = tls.RequireAndVerifyClientCert
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		handshake := config.Clone()
+		handshake.GetConfigForClient = nil
+		handshake.ClientCAs = clientCAs.CertPool()
+		return handshake, nil
+	}
+
+	return config, nil
+}
+
+// WithTLS dials over TLS trusting the CA bundle in caFile, or the system
+// pool when empty. A certificate and key pair is presented to servers
+// requiring mTLS. Both the bundle and the pair are reloaded on rotation.
+func WithTLS(caFile, certFile, keyFile string) DialOption {
+	return func(name string) (grpc.DialOption, error) {
+		config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+		if caFile != "" {
+			rootCAs, err := NewCertPoolReloader(caFile)
+			if err != nil {
+				return nil, err
+			}
+			// RootCAs is fixed once the config is in use, so the server is
+			// verified by VerifyConnection against the current bundle instead
+			config.InsecureSkipVerify = true
+			config.VerifyConnection = func(cs tls.ConnectionState) error {
+				return verifyServerCertificate(cs, rootCAs.CertPool())
+			}
+		}
+
+		if certFile != "" || keyFile != "" {
+			certs, err := NewCertReloader(certFile, keyFile)
+			if err != nil {
+				return nil, err
+			}
+			config.GetClientCertificate = certs.GetClientCertificate
+		}
+
+		return grpc.WithTransportCredentials(credentials.NewTLS(config)), nil
+	}
+}
+
+// verifyServerCertificate does the verification InsecureSkipVerify turns off:
+// the chain against roots and the certificate against the dialed name.
+func verifyServerCertificate(cs tls.ConnectionState, roots *x509.CertPool) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("server presented no certificate")
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		DNSName:       cs.ServerName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+// WithLoopbackTLS dials this process's own server. The server certificate is
+// pinned instead of verified against a CA, and is also presented as the
+// client certificate, so with mTLS it must allow client authentication and be
+// trusted by the client CA bundle.
+func WithLoopbackTLS(certs *CertReloader) DialOption {
+	return func(name string) (grpc.DialOption, error) {
+		config := &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			// verification is done by VerifyPeerCertificate against the pinned certificate
+			InsecureSkipVerify:   true,
+			GetClientCertificate: certs.GetClientCertificate,
+			VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+				if len(rawCerts) == 0 || !bytes.Equal(rawCerts[0], certs.Certificate().Certificate[0]) {
+					return errors.New("loopback server certificate does not match the local certificate")
+				}
+				return nil
+			},
+		}
+
+		return grpc.WithTransportCredentials(credentials.NewTLS(config)), nil
+	}
+}
@@ -0,0 +1,182 @@
+package server_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"xyz-transaction-service/server"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	dir  string
+}
+
+func newTestCA(t *testing.T, dir string) *testCA {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, _ := x509.ParseCertificate(der)
+
+	writePEM(t, filepath.Join(dir, "ca.pem"), "CERTIFICATE", der)
+	return &testCA{cert: cert, key: key, dir: dir}
+}
+
+// issue writes name.pem and name-key.pem signed by the CA and returns their paths.
+func (ca *testCA) issue(t *testing.T, name string, serial int64) (string, string) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	assert.NoError(t, err)
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+
+	certFile, keyFile := filepath.Join(ca.dir, name+".pem"), filepath.Join(ca.dir, name+"-key.pem")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDer)
+	return certFile, keyFile
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	assert.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir)
+	serverCert, serverKey := ca.issue(t, "server", 2)
+	clientCert, clientKey := ca.issue(t, "client", 3)
+
+	certs, err := server.NewCertReloader(serverCert, serverKey)
+	assert.NoError(t, err)
+	tlsConfig, err := server.NewServerTLSConfig(certs, filepath.Join(dir, "ca.pem"))
+	assert.NoError(t, err)
+
+	srv := server.NewGrpc("0", grpc.Creds(credentials.NewTLS(tlsConfig)))
+	srv.Health.CheckOnce(context.Background(), time.Second)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() { _ = srv.Server.Serve(listener) }()
+	defer srv.Server.Stop()
+
+	check := func(opts ...server.DialOption) error {
+		conn, err := server.Dial(listener.Addr().String(), opts...)
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		return err
+	}
+
+	assert.NoError(t, check(server.WithTLS(filepath.Join(dir, "ca.pem"), clientCert, clientKey)))
+	assert.NoError(t, check(server.WithLoopbackTLS(certs)))
+	// no client certificate
+	assert.Error(t, check(server.WithTLS(filepath.Join(dir, "ca.pem"), "", "")))
+	// plaintext
+	assert.Error(t, check())
+}
+
+func TestCertReloaderPicksUpRotation(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir)
+	certFile, keyFile := ca.issue(t, "server", 2)
+
+	certs, err := server.NewCertReloader(certFile, keyFile)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), certs.Certificate().Leaf.SerialNumber.Int64())
+
+	ca.issue(t, "server", 4)
+	future := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(certFile, future, future))
+	time.Sleep(1100 * time.Millisecond)
+	assert.Equal(t, int64(4), certs.Certificate().Leaf.SerialNumber.Int64())
+
+	// a broken pair keeps the previous certificate in use
+	assert.NoError(t, os.WriteFile(keyFile, []byte("garbage"), 0o600))
+	later := future.Add(time.Minute)
+	assert.NoError(t, os.Chtimes(keyFile, later, later))
+	time.Sleep(1100 * time.Millisecond)
+	assert.Equal(t, int64(4), certs.Certificate().Leaf.SerialNumber.Int64())
+}
+
+func TestClientCAReloadsOnRotation(t *testing.T) {
+	dir, rotatedDir := t.TempDir(), t.TempDir()
+	newTestCA(t, dir)
+	rotated := newTestCA(t, rotatedDir)
+	serverCert, serverKey := rotated.issue(t, "server", 2)
+
+	certs, err := server.NewCertReloader(serverCert, serverKey)
+	assert.NoError(t, err)
+	tlsConfig, err := server.NewServerTLSConfig(certs, "")
+	assert.NoError(t, err)
+
+	srv := server.NewGrpc("0", grpc.Creds(credentials.NewTLS(tlsConfig)))
+	srv.Health.CheckOnce(context.Background(), time.Second)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() { _ = srv.Server.Serve(listener) }()
+	defer srv.Server.Stop()
+
+	// one set of credentials, as held by a long lived client
+	caFile := filepath.Join(dir, "ca.pem")
+	dialOpt, err := server.WithTLS(caFile, "", "")(listener.Addr().String())
+	assert.NoError(t, err)
+	check := func() error {
+		conn, err := server.Dial(listener.Addr().String(), func(string) (grpc.DialOption, error) { return dialOpt, nil })
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		return err
+	}
+
+	// the server certificate is signed by a CA the client does not trust yet
+	assert.Error(t, check())
+
+	pem, err := os.ReadFile(filepath.Join(rotatedDir, "ca.pem"))
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(caFile, pem, 0o600))
+	future := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(caFile, future, future))
+	time.Sleep(1100 * time.Millisecond)
+
+	assert.NoError(t, check())
+}